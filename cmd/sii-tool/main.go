@@ -3,22 +3,35 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
-	"github.com/robebs/ts-se-tool-go/internal/siidecrypt"
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
 	"github.com/robebs/ts-se-tool-go/internal/sii"
+	"github.com/robebs/ts-se-tool-go/internal/siidecrypt"
 )
 
 func main() {
 	inPath := flag.String("in", "", "input SII file path")
 	outPath := flag.String("out", "", "output SII file path (optional)")
+	checkRefs := flag.Bool("check-refs", false, "list pointers to units missing from the file")
+	countType := flag.String("count", "", "stream the file and count the blocks of this type (e.g. job_offer_data)")
 	flag.Parse()
 
 	if *inPath == "" {
 		log.Fatal("missing -in path to SII file")
 	}
 
+	if *countType != "" {
+		n, err := countBlocks(*inPath, *countType)
+		if err != nil {
+			log.Fatalf("count %s: %v", *countType, err)
+		}
+		fmt.Printf("%d %s blocks\n", n, *countType)
+		return
+	}
+
 	// Déchiffre/décode le fichier si nécessaire (supporte les formats encryptés ETS2),
 	// puis parse le texte SII résultant.
 	plain, err := siidecrypt.DecryptFile(*inPath, true)
@@ -31,10 +44,19 @@ func main() {
 		log.Fatalf("parse SII: %v", err)
 	}
 
+	if *checkRefs {
+		dangling := doc.DanglingReferences(items.Pointers)
+		for _, ref := range dangling {
+			fmt.Println(ref)
+		}
+		fmt.Printf("%d dangling references\n", len(dangling))
+		return
+	}
+
 	// Example integration with typed save-game classes: once parsing is wired,
 	// this will surface structured bank loans, garages, etc.
-	bankLoans := doc.ToBankLoans()
-	fmt.Printf("parsed %d bank_loan blocks (typed view placeholder)\n\n", len(bankLoans))
+	bankLoans := items.BankLoansFromDocument(doc)
+	fmt.Printf("parsed %d bank_loan blocks\n\n", len(bankLoans))
 
 	if *outPath == "" {
 		fmt.Println(doc.DebugString())
@@ -50,3 +72,28 @@ func main() {
 		log.Fatalf("write output: %v", err)
 	}
 }
+
+// countBlocks counts the blocks of type typ without loading the whole file,
+// so it runs in constant memory even on large saves.
+func countBlocks(path, typ string) (int, error) {
+	f, err := siidecrypt.OpenFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := sii.NewReader(f)
+	n := 0
+	for {
+		b, err := r.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if b.Type == typ {
+			n++
+		}
+	}
+}
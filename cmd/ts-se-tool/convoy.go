@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/robebs/ts-se-tool-go/internal/save"
+	"github.com/robebs/ts-se-tool-go/internal/save/convoy"
+	"github.com/urfave/cli/v2"
+)
+
+// convoyCommand copies the truck position and the GPS route between saves,
+// for players starting a convoy together.
+func convoyCommand() *cli.Command {
+	saveFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "profile",
+			Usage:    "profile folder, the one holding save/",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "slot",
+			Usage: "save slot, e.g. 1 or autosave",
+			Value: "autosave",
+		},
+	}
+	return &cli.Command{
+		Name:  "convoy",
+		Usage: "copy the truck position and GPS route from one save to another",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "write the position and route of a save",
+				Flags: append(saveFlags,
+					&cli.StringFlag{Name: "out", Usage: "output file (default stdout)"},
+					&cli.BoolFlag{Name: "json", Usage: "write JSON instead of a one-line text blob"},
+				),
+				Action: convoyExport,
+			},
+			{
+				Name:  "import",
+				Usage: "move the player of a save to an exported position and route",
+				Flags: append(saveFlags,
+					&cli.StringFlag{Name: "in", Usage: "input file, text or JSON (default stdin)"},
+				),
+				Action: convoyImport,
+			},
+			{
+				Name:  "clone",
+				Usage: "copy a save into new numbered slots, one for each position",
+				Flags: append(saveFlags,
+					&cli.StringSliceFlag{Name: "from-slot", Usage: "take a position from this save slot of the profile"},
+					&cli.StringSliceFlag{Name: "from-bundle", Usage: "take a position from this exported file"},
+					&cli.StringFlag{Name: "name", Usage: "name of the new saves, numbered (default the name of the save)"},
+				),
+				Action: convoyClone,
+			},
+		},
+	}
+}
+
+func convoyExport(c *cli.Context) error {
+	docs, err := save.LoadSaveFile(c.String("profile"), c.String("slot"))
+	if err != nil {
+		return fmt.Errorf("load save file: %w", err)
+	}
+	bundle, err := convoy.Export(docs.Game)
+	if err != nil {
+		return fmt.Errorf("export convoy: %w", err)
+	}
+	format := convoy.FormatText
+	if c.Bool("json") {
+		format = convoy.FormatJSON
+	}
+	data, err := convoy.Encode(bundle, format)
+	if err != nil {
+		return err
+	}
+
+	if out := c.String("out"); out != "" {
+		return os.WriteFile(out, data, 0o644)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func convoyImport(c *cli.Context) error {
+	var data []byte
+	var err error
+	if in := c.String("in"); in != "" {
+		data, err = os.ReadFile(in)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return fmt.Errorf("read convoy: %w", err)
+	}
+	bundle, err := convoy.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	profile, slot := c.String("profile"), c.String("slot")
+	docs, err := save.LoadSaveFile(profile, slot)
+	if err != nil {
+		return fmt.Errorf("load save file: %w", err)
+	}
+	if err := convoy.Apply(docs.Game, bundle); err != nil {
+		return fmt.Errorf("import convoy: %w", err)
+	}
+	if err := save.WriteSaveFile(profile, slot, docs); err != nil {
+		return fmt.Errorf("write save file: %w", err)
+	}
+	fmt.Printf("Convoy position imported into %s\n", slot)
+	return nil
+}
+
+func convoyClone(c *cli.Context) error {
+	profile := c.String("profile")
+
+	var positions []convoy.Position
+	for _, slot := range c.StringSlice("from-slot") {
+		docs, err := save.LoadSaveFile(profile, slot)
+		if err != nil {
+			return fmt.Errorf("load save file %s: %w", slot, err)
+		}
+		pos, err := convoy.PositionOf(docs.Game)
+		if err != nil {
+			return fmt.Errorf("save %s: %w", slot, err)
+		}
+		positions = append(positions, pos)
+	}
+	for _, path := range c.StringSlice("from-bundle") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read convoy: %w", err)
+		}
+		bundle, err := convoy.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		positions = append(positions, bundle.Position)
+	}
+
+	docs, err := save.LoadSaveFile(profile, c.String("slot"))
+	if err != nil {
+		return fmt.Errorf("load save file: %w", err)
+	}
+	slots, err := convoy.CloneSave(profile, docs, positions, c.String("name"))
+	for _, slot := range slots {
+		fmt.Printf("Save %s created\n", slot)
+	}
+	return err
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"bufio"
-	"encoding/binary"
 	"fmt"
 	"log"
 	"os"
@@ -10,14 +9,28 @@ import (
 
 	"github.com/robebs/ts-se-tool-go/internal/app"
 	"github.com/robebs/ts-se-tool-go/internal/save"
-	"github.com/robebs/ts-se-tool-go/internal/save/loader"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	app := &cli.App{
-		Name:   "ts-se-tool",
-		Usage:  "Euro Truck Simulator 2 / American Truck Simulator Save Editor",
+		Name:  "ts-se-tool",
+		Usage: "Euro Truck Simulator 2 / American Truck Simulator Save Editor",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "gameref",
+				Usage: "folder of extracted game definitions (<game>/<dlc>/def), to randomize the cargo market",
+			},
+			&cli.StringFlag{
+				Name:  "lang",
+				Usage: "folder of the lang tables (driver names, countries); defaults to lang next to the executable, then in the working directory",
+			},
+		},
+		Commands: []*cli.Command{
+			convoyCommand(),
+		},
 		Action: runInteractive,
 	}
 
@@ -53,6 +66,7 @@ func runInteractive(c *cli.Context) error {
 		GameType:    selected.GameType,
 		ProfilePath: selected.ProfileDir,
 		SaveSlot:    selected.SaveSlot,
+		GameRefRoot: c.String("gameref"),
 	}
 	w, err := app.LoadWorld(opts)
 	if err != nil {
@@ -65,6 +79,7 @@ func runInteractive(c *cli.Context) error {
 
 	// Step 5: Main menu loop
 	modified := false
+	var injectedJobs []string // job offers added in this session
 	for {
 		displayMainMenu()
 		choice := getUserChoice()
@@ -108,25 +123,134 @@ func runInteractive(c *cli.Context) error {
 				modified = true
 			}
 		case 6:
-			err = save.PopulateGaragesWithTrucks(docs.Game, w)
+			err = save.PopulateGaragesWithTrucks(docs.Game, w, save.StockTruck(selected.GameType))
 			if err == nil {
 				fmt.Println("Garages populated with trucks")
 				modified = true
 			}
 		case 7:
-			err = save.RecruitEmployeesAndPopulateTrucks(docs.Game, w)
+			var skills save.DriverSkills
+			skills, err = promptDriverSkills()
 			if err == nil {
-				fmt.Println("Employees recruited and assigned to trucks")
-				modified = true
+				recruit := save.RecruitOptions{
+					Names:  loadDriverNames(langDir(c), selected.GameType),
+					Skills: skills,
+				}
+				err = save.RecruitEmployeesAndPopulateTrucks(docs.Game, w, recruit)
+				if err == nil {
+					fmt.Println("Employees recruited and assigned to trucks")
+					modified = true
+				}
 			}
 		case 8:
+			var city string
+			var action int
+			var garageOpts save.GarageOptions
+			city, action, err = promptGarageAction()
+			if err == nil {
+				garageOpts, err = promptGaragePrices()
+			}
+			if err == nil {
+				err = manageGarage(docs.Game, city, action, garageOpts)
+				if err == nil {
+					fmt.Printf("Garage %s updated\n", city)
+					modified = true
+				}
+			}
+		case 9:
+			var city string
+			var transfer bool
+			city, transfer, err = promptHQCity()
+			if err == nil {
+				err = save.SetHQCity(docs.Game, city, transfer)
+				if err == nil {
+					fmt.Printf("Headquarters moved to %s\n", city)
+					modified = true
+				}
+			}
+		case 10:
+			var action int
+			var cities []string
+			action, cities, err = promptCities(loadCountries(langDir(c)))
+			if err == nil {
+				err = editVisitedCities(docs, action, cities)
+				if err == nil {
+					modified = true
+				}
+			}
+		case 11:
+			var changed bool
+			changed, err = manageLoans(docs.Game)
+			if changed {
+				fmt.Println("Loans updated")
+				modified = true
+			}
+		case 12:
+			fmt.Printf("%d jobs added in this session\n", len(injectedJobs))
+			fmt.Print("Add a job (a) or clear the added jobs (c)? ")
+			reader := bufio.NewReader(os.Stdin)
+			response, _ := reader.ReadString('\n')
+			switch strings.TrimSpace(strings.ToLower(response)) {
+			case "a":
+				var job save.JobOffer
+				job, err = promptJobOffer()
+				if err == nil {
+					var names []string
+					names, err = save.AddJobOffers(docs.Game, w, job)
+					// Jobs added before an error are in the save as well
+					injectedJobs = append(injectedJobs, names...)
+					modified = modified || len(names) > 0
+					if err == nil {
+						fmt.Println("Job added")
+					}
+				}
+			case "c":
+				n := save.RemoveJobOffers(docs.Game, injectedJobs...)
+				injectedJobs = nil
+				fmt.Printf("%d jobs removed\n", n)
+				modified = modified || n > 0
+			default:
+				err = fmt.Errorf("invalid action")
+			}
+		case 13:
+			var action int
+			var company, city string
+			action, company, city, err = promptCargoMarket()
+			if err == nil {
+				err = editCargoMarket(docs.Game, w, action, company, city)
+				if err == nil {
+					modified = true
+				}
+			}
+		case 14:
+			var changed bool
+			changed, err = manageTrucks(docs.Game)
+			if changed {
+				fmt.Println("Trucks updated")
+				modified = true
+			}
+		case 15:
+			var changed bool
+			changed, err = manageTrailers(docs.Game)
+			if changed {
+				fmt.Println("Trailers updated")
+				modified = true
+			}
+		case 16:
+			var changed bool
+			changed, err = shareTruckSettings(docs.Game)
+			if changed {
+				fmt.Println("Truck settings pasted")
+				modified = true
+			}
+		case 17:
 			// Save and exit
 			if modified {
 				return saveChanges(selected, docs)
 			}
 			fmt.Println("No changes to save")
 			return nil
-		case 9:
+		case 18:
 			// Exit without saving
 			if modified {
 				fmt.Println("Warning: You have unsaved changes!")
@@ -140,7 +264,7 @@ func runInteractive(c *cli.Context) error {
 			}
 			return nil
 		default:
-			fmt.Println("Invalid choice. Please select 1-9.")
+			fmt.Println("Invalid choice. Please select 1-18.")
 			continue
 		}
 
@@ -158,27 +282,84 @@ func runInteractive(c *cli.Context) error {
 	}
 }
 
-func saveChanges(selected *SelectedSave, docs *save.Documents) error {
-	fmt.Println("\nSaving changes...")
+// manageGarage applies a garage action of promptGarageAction.
+func manageGarage(doc *sii.Document, city string, action int, opts save.GarageOptions) error {
+	switch action {
+	case garageBuy:
+		return save.BuyGarage(doc, city, opts)
+	case garageBuyAndUpgrade:
+		return save.BuyAndUpgradeGarage(doc, city, opts)
+	case garageUpgrade:
+		return save.UpgradeGarage(doc, city, opts)
+	case garageDowngrade:
+		return save.DowngradeGarage(doc, city, opts)
+	case garageSell:
+		return save.SellGarage(doc, city, opts)
+	}
+	return fmt.Errorf("invalid garage action %d", action)
+}
 
-	// Detect encryption format by checking original file
-	encrypt := false
-	gamePath := loader.ResolveGameSIIPath(selected.ProfileDir, selected.SaveSlot)
-	if info, err := os.Stat(gamePath); err == nil && !info.IsDir() {
-		firstBytes := make([]byte, 4)
-		if f, err := os.Open(gamePath); err == nil {
-			if n, _ := f.Read(firstBytes); n == 4 {
-				sig := binary.LittleEndian.Uint32(firstBytes)
-				if sig == 1131635539 { // SignatureEncrypted
-					encrypt = true
-				}
+// editVisitedCities applies a city action of promptCities, and updates the
+// visited city count shown in the save list.
+func editVisitedCities(docs *save.Documents, action int, cities []string) error {
+	var err error
+	switch action {
+	case citiesVisit:
+		var n int
+		var skipped []string
+		if n, skipped, err = save.VisitCities(docs.Game, cities...); err == nil {
+			fmt.Printf("%d cities visited\n", n)
+			if len(skipped) > 0 {
+				fmt.Printf("Not cities of this save, skipped: %s\n", strings.Join(skipped, ", "))
 			}
-			f.Close()
 		}
+	case citiesUnvisit:
+		var n int
+		if n, err = save.UnvisitCities(docs.Game, cities...); err == nil {
+			fmt.Printf("%d cities unvisited\n", n)
+		}
+	case citiesResetExploration:
+		if err = save.ResetExploration(docs.Game); err == nil {
+			fmt.Println("World map exploration reset")
+		}
+	}
+	if err != nil || docs.Info == nil {
+		return err
+	}
+	return save.SyncInfoVisitedCities(docs.Info, docs.Game)
+}
+
+// editCargoMarket applies a cargo market action of promptCargoMarket.
+func editCargoMarket(doc *sii.Document, w *world.World, action int, company, city string) error {
+	var n int
+	var err error
+	switch action {
+	case marketRandomize:
+		if n, err = save.RandomizeCargoMarket(doc, w, company, city); err == nil {
+			fmt.Printf("%d jobs offered\n", n)
+		}
+	case marketExpire:
+		if n, err = save.ResetCargoMarket(doc, company, city, false); err == nil {
+			fmt.Printf("%d jobs expired\n", n)
+		}
+	case marketRemove:
+		if n, err = save.ResetCargoMarket(doc, company, city, true); err == nil {
+			fmt.Printf("%d jobs removed\n", n)
+		}
+	}
+	return err
+}
+
+func saveChanges(selected *SelectedSave, docs *save.Documents) error {
+	fmt.Println("\nSaving changes...")
+
+	// Refresh what the save list shows from the edited game
+	if err := save.SyncInfo(docs, true); err != nil {
+		return fmt.Errorf("sync info.sii: %w", err)
 	}
 
-	// Write all files
-	if err := save.WriteSaveFile(selected.ProfileDir, selected.SaveSlot, docs, encrypt); err != nil {
+	// Write all files, each in the format it was loaded from
+	if err := save.WriteSaveFile(selected.ProfileDir, selected.SaveSlot, docs); err != nil {
 		return fmt.Errorf("write save file: %w", err)
 	}
 
@@ -4,8 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/externaldata"
+	"github.com/robebs/ts-se-tool-go/internal/save"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+	"github.com/urfave/cli/v2"
 )
 
 func displayMainMenu() {
@@ -19,9 +25,18 @@ func displayMainMenu() {
 	fmt.Println("5. Upgrade all garages")
 	fmt.Println("6. Populate all garages with random trucks")
 	fmt.Println("7. Recruit employees and populate all trucks")
-	fmt.Println("8. Save and exit")
-	fmt.Println("9. Exit without saving")
-	fmt.Print("\nSelect option (1-9): ")
+	fmt.Println("8. Buy, sell, upgrade or downgrade a garage")
+	fmt.Println("9. Move headquarters")
+	fmt.Println("10. Visit or unvisit cities")
+	fmt.Println("11. Manage bank loans")
+	fmt.Println("12. Add or clear freight market jobs")
+	fmt.Println("13. Randomize or reset the cargo market")
+	fmt.Println("14. Edit truck details")
+	fmt.Println("15. Edit trailer details")
+	fmt.Println("16. Copy or paste truck settings")
+	fmt.Println("17. Save and exit")
+	fmt.Println("18. Exit without saving")
+	fmt.Print("\nSelect option (1-14): ")
 }
 
 func getUserChoice() int {
@@ -76,3 +91,604 @@ func confirmContinue() bool {
 	response = strings.TrimSpace(strings.ToLower(response))
 	return response == "y" || response == "yes"
 }
+
+// promptDriverSkills asks how the skills of recruited drivers are chosen.
+func promptDriverSkills() (save.DriverSkills, error) {
+	fmt.Println("Driver skills:")
+	fmt.Println("  1. Fixed level")
+	fmt.Println("  2. Random levels")
+	fmt.Println("  3. Same as the player")
+	fmt.Print("Select option (1-3, default 2): ")
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "1":
+		fmt.Printf("Enter skill level (0-%d): ", save.MaxSkillLevel)
+		input, _ = reader.ReadString('\n')
+		level, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || level < 0 || level > save.MaxSkillLevel {
+			return save.DriverSkills{}, fmt.Errorf("invalid skill level: %q", strings.TrimSpace(input))
+		}
+		return save.DriverSkills{Mode: save.SkillsFixed, Level: level, XP: 0}, nil
+	case "3":
+		return save.DriverSkills{Mode: save.SkillsMirrorPlayer}, nil
+	case "", "2":
+		return save.DriverSkills{Mode: save.SkillsRandom, MaxLevel: 2, MaxXP: 5000}, nil
+	}
+	return save.DriverSkills{}, fmt.Errorf("invalid option: %q", input)
+}
+
+// langDir returns the folder of the lang tables: the --lang flag, or the lang
+// folder next to the executable when there is one, or lang in the working
+// directory.
+func langDir(c *cli.Context) string {
+	if dir := c.String("lang"); dir != "" {
+		return dir
+	}
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Join(filepath.Dir(exe), "lang")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return "lang"
+}
+
+// loadDriverNames reads the driver name table of the game from the lang
+// folder. Drivers are numbered without it, so a missing table is only
+// reported.
+func loadDriverNames(lang, gameType string) map[string]string {
+	path := filepath.Join(lang, "Default", gameType, "driver_names.csv")
+	names, err := externaldata.LoadDriverNames(path)
+	if err != nil {
+		fmt.Printf("Warning: Could not load driver names: %v\n", err)
+		return nil
+	}
+	return names
+}
+
+// Garage actions of promptGarageAction.
+const (
+	garageBuy = iota + 1
+	garageBuyAndUpgrade
+	garageUpgrade
+	garageDowngrade
+	garageSell
+)
+
+// promptGarageAction asks for a garage city and what to do with it.
+func promptGarageAction() (string, int, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter garage city (e.g. berlin): ")
+	city, _ := reader.ReadString('\n')
+	city = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(city)), "garage.")
+	if city == "" {
+		return "", 0, fmt.Errorf("no city entered")
+	}
+
+	fmt.Println("  1. Buy")
+	fmt.Println("  2. Buy and upgrade")
+	fmt.Println("  3. Upgrade")
+	fmt.Println("  4. Downgrade")
+	fmt.Println("  5. Sell")
+	fmt.Print("Select action (1-5): ")
+	input, _ := reader.ReadString('\n')
+	action, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || action < garageBuy || action > garageSell {
+		return "", 0, fmt.Errorf("invalid action: %q", strings.TrimSpace(input))
+	}
+	return city, action, nil
+}
+
+// promptGaragePrices asks for the value of a tiny, small and large garage.
+// The bank is left untouched when nothing is entered.
+func promptGaragePrices() (save.GarageOptions, error) {
+	fmt.Print("Enter the value of a tiny, small and large garage to charge the bank (empty for free): ")
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return save.GarageOptions{}, nil
+	}
+	if len(fields) != 3 {
+		return save.GarageOptions{}, fmt.Errorf("expected 3 prices, got %d", len(fields))
+	}
+	var prices save.GaragePrices
+	for i, dst := range []*int64{&prices.Tiny, &prices.Small, &prices.Large} {
+		v, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return save.GarageOptions{}, fmt.Errorf("invalid price: %v", err)
+		}
+		*dst = v
+	}
+	return save.GarageOptions{Prices: &prices}, nil
+}
+
+// promptHQCity asks for the new headquarters city and whether to travel
+// there.
+func promptHQCity() (string, bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter new headquarters city (must have an owned garage): ")
+	city, _ := reader.ReadString('\n')
+	city = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(city)), "garage.")
+	if city == "" {
+		return "", false, fmt.Errorf("no city entered")
+	}
+	fmt.Print("Move your truck there when the game loads? (y/n): ")
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return city, response == "y" || response == "yes", nil
+}
+
+// City actions of promptCities.
+const (
+	citiesVisit = iota + 1
+	citiesUnvisit
+	citiesResetExploration
+)
+
+// promptCities asks what to do with visited cities and, to visit or unvisit,
+// the cities. Country names are expanded to their cities with countries.
+func promptCities(countries *externaldata.CountryDictionary) (int, []string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println("  1. Visit cities")
+	fmt.Println("  2. Unvisit cities")
+	fmt.Println("  3. Reset world map exploration")
+	fmt.Print("Select action (1-3): ")
+	input, _ := reader.ReadString('\n')
+	action, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || action < citiesVisit || action > citiesResetExploration {
+		return 0, nil, fmt.Errorf("invalid action: %q", strings.TrimSpace(input))
+	}
+	if action == citiesResetExploration {
+		return action, nil, nil
+	}
+
+	fmt.Print("Enter cities or countries, separated by commas: ")
+	input, _ = reader.ReadString('\n')
+	var cities []string
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		if inCountry := countries.Cities(name); len(inCountry) > 0 {
+			cities = append(cities, inCountry...)
+		} else {
+			cities = append(cities, name)
+		}
+	}
+	if len(cities) == 0 {
+		return 0, nil, fmt.Errorf("no city entered")
+	}
+	return action, cities, nil
+}
+
+// loadCountries reads the city to country table from the lang folder.
+// Countries cannot be entered without it, so a missing table is only
+// reported.
+func loadCountries(lang string) *externaldata.CountryDictionary {
+	countries, err := externaldata.LoadCountryDictionary(filepath.Join(lang, "CityToCountry.csv"))
+	if err != nil {
+		fmt.Printf("Warning: Could not load countries: %v\n", err)
+		return nil
+	}
+	return countries
+}
+
+// manageLoans lists the loans of the player and applies the chosen change.
+// It reports whether the save was changed.
+func manageLoans(doc *sii.Document) (bool, error) {
+	loans, err := save.ListLoans(doc)
+	if err != nil {
+		return false, err
+	}
+	if len(loans) == 0 {
+		fmt.Println("No loans")
+	}
+	for i, l := range loans {
+		fmt.Printf("  %d. %d left of %d, interest %.2f%%, %d days\n",
+			i+1, l.Amount, l.OriginalAmount, float32(l.InterestRate)*100, l.Duration)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(text string) string {
+		fmt.Print(text)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	pickLoan := func() (string, error) {
+		n, err := strconv.Atoi(prompt("Enter loan number: "))
+		if err != nil || n < 1 || n > len(loans) {
+			return "", fmt.Errorf("invalid loan number")
+		}
+		return loans[n-1].Name, nil
+	}
+	promptTerms := func() (float32, int, error) {
+		rate, err := strconv.ParseFloat(prompt("Enter interest rate in percent: "), 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid interest rate: %v", err)
+		}
+		days, err := strconv.Atoi(prompt("Enter duration in days: "))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid duration: %v", err)
+		}
+		return float32(rate / 100), days, nil
+	}
+
+	fmt.Println("  a. Pay off a loan")
+	fmt.Println("  b. Pay off all loans")
+	fmt.Println("  c. Take a loan")
+	fmt.Println("  d. Change the terms of a loan")
+	switch strings.ToLower(prompt("Select action (a-d): ")) {
+	case "a":
+		name, err := pickLoan()
+		if err != nil {
+			return false, err
+		}
+		err = save.PayOffLoan(doc, name)
+		return err == nil, err
+	case "b":
+		err := save.PayOffAllLoans(doc)
+		return err == nil, err
+	case "c":
+		amount, err := strconv.Atoi(prompt("Enter amount: "))
+		if err != nil {
+			return false, fmt.Errorf("invalid amount: %v", err)
+		}
+		rate, days, err := promptTerms()
+		if err != nil {
+			return false, err
+		}
+		_, err = save.TakeLoan(doc, amount, rate, days)
+		return err == nil, err
+	case "d":
+		name, err := pickLoan()
+		if err != nil {
+			return false, err
+		}
+		rate, days, err := promptTerms()
+		if err != nil {
+			return false, err
+		}
+		err = save.SetLoanTerms(doc, name, rate, days)
+		return err == nil, err
+	}
+	return false, fmt.Errorf("invalid action")
+}
+
+// promptJobOffer asks for a freight market job. Companies are entered as
+// "company.city", as in job_offer_data.target.
+func promptJobOffer() (save.JobOffer, error) {
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(text string) string {
+		fmt.Print(text)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	var job save.JobOffer
+	var ok bool
+	if job.SourceCompany, job.SourceCity, ok = strings.Cut(prompt("Enter source company (company.city): "), "."); !ok {
+		return job, fmt.Errorf("source must be company.city")
+	}
+	if job.TargetCompany, job.TargetCity, ok = strings.Cut(prompt("Enter target company (company.city): "), "."); !ok {
+		return job, fmt.Errorf("target must be company.city")
+	}
+	if job.Cargo = prompt("Enter cargo (e.g. apples): "); job.Cargo == "" {
+		return job, fmt.Errorf("no cargo entered")
+	}
+
+	var err error
+	if input := prompt("Enter urgency (0 standard, 1 important, 2 urgent, default 0): "); input != "" {
+		if job.Urgency, err = strconv.Atoi(input); err != nil {
+			return job, fmt.Errorf("invalid urgency: %v", err)
+		}
+	}
+	if input := prompt("Enter distance in km (default 0): "); input != "" {
+		if job.DistanceKm, err = strconv.Atoi(input); err != nil {
+			return job, fmt.Errorf("invalid distance: %v", err)
+		}
+	}
+	if input := prompt("Enter expiration in hours (default 24): "); input != "" {
+		hours, err := strconv.Atoi(input)
+		if err != nil {
+			return job, fmt.Errorf("invalid expiration: %v", err)
+		}
+		job.ExpiresIn = hours * 60
+	}
+	if input := prompt("Enter units count (empty for default): "); input != "" {
+		if job.UnitsCount, err = strconv.Atoi(input); err != nil {
+			return job, fmt.Errorf("invalid units count: %v", err)
+		}
+	}
+	job.TrailerDefinition = prompt("Enter trailer definition (empty for default): ")
+	job.TrailerVariant = prompt("Enter trailer variant (empty for default): ")
+	job.CompanyTruck = prompt("Enter company truck (empty for default): ")
+	return job, nil
+}
+
+// Cargo market actions of promptCargoMarket.
+const (
+	marketRandomize = iota + 1
+	marketExpire
+	marketRemove
+)
+
+// promptCargoMarket asks what to do with the cargo market and of which
+// company and city. Empty company or city select all of them.
+func promptCargoMarket() (int, string, string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println("  1. Randomize cargo list")
+	fmt.Println("  2. Reset cargo list (expire jobs)")
+	fmt.Println("  3. Reset cargo list (remove jobs)")
+	fmt.Print("Select action (1-3): ")
+	input, _ := reader.ReadString('\n')
+	action, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || action < marketRandomize || action > marketRemove {
+		return 0, "", "", fmt.Errorf("invalid action: %q", strings.TrimSpace(input))
+	}
+
+	fmt.Print("Enter company (e.g. ikea, empty for all): ")
+	company, _ := reader.ReadString('\n')
+	fmt.Print("Enter city (e.g. bruxelles, empty for all): ")
+	city, _ := reader.ReadString('\n')
+	company = strings.TrimSpace(strings.ToLower(company))
+	city = strings.TrimSpace(strings.ToLower(city))
+	if company == "" && city == "" {
+		fmt.Print("This changes the whole cargo market. Continue? (y/n): ")
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return 0, "", "", fmt.Errorf("cancelled")
+		}
+	}
+	return action, company, city, nil
+}
+
+// manageTrucks lists the trucks of the player and applies the chosen change.
+// It reports whether the save was changed.
+func manageTrucks(doc *sii.Document) (bool, error) {
+	trucks, err := save.ListTrucks(doc)
+	if err != nil {
+		return false, err
+	}
+	if len(trucks) == 0 {
+		return false, fmt.Errorf("the player has no truck")
+	}
+	for i, t := range trucks {
+		wear := max(t.EngineWear, t.TransmissionWear, t.CabinWear, t.ChassisWear)
+		for _, w := range t.WheelsWear {
+			wear = max(wear, w)
+		}
+		fmt.Printf("  %d. %s, %d km, fuel %.0f%%, wear %.1f%%\n", i+1,
+			save.LicensePlateText(string(t.LicensePlate)), t.Odometer, float32(t.FuelRelative)*100, float32(wear)*100)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(text string) string {
+		fmt.Print(text)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	// pickTruck returns the chosen truck, or "" for all of them if allowAll.
+	pickTruck := func(allowAll bool) (string, error) {
+		text := "Enter truck number: "
+		if allowAll {
+			text = "Enter truck number (empty for all): "
+		}
+		input := prompt(text)
+		if input == "" && allowAll {
+			return "", nil
+		}
+		n, err := strconv.Atoi(input)
+		if err != nil || n < 1 || n > len(trucks) {
+			return "", fmt.Errorf("invalid truck number")
+		}
+		return trucks[n-1].Name, nil
+	}
+
+	fmt.Println("  a. Repair")
+	fmt.Println("  b. Repair, including unfixable wear")
+	fmt.Println("  c. Refuel")
+	fmt.Println("  d. Set odometer")
+	fmt.Println("  e. Reset trip stats")
+	fmt.Println("  f. Change license plate")
+	action := strings.ToLower(prompt("Select action (a-f): "))
+	switch action {
+	case "a", "b":
+		unfixable := action == "b"
+		name, err := pickTruck(true)
+		if err != nil {
+			return false, err
+		}
+		if name == "" {
+			err = save.RepairAllTrucks(doc, unfixable)
+		} else {
+			err = save.RepairTruck(doc, name, unfixable)
+		}
+		return err == nil, err
+	case "c":
+		name, err := pickTruck(true)
+		if err != nil {
+			return false, err
+		}
+		level := float32(1)
+		if input := prompt("Enter fuel level in percent (default 100): "); input != "" {
+			percent, err := strconv.ParseFloat(input, 32)
+			if err != nil {
+				return false, fmt.Errorf("invalid fuel level: %v", err)
+			}
+			level = float32(percent / 100)
+		}
+		if name == "" {
+			err = save.RefuelAllTrucks(doc, level)
+		} else {
+			err = save.RefuelTruck(doc, name, level)
+		}
+		return err == nil, err
+	case "d":
+		name, err := pickTruck(false)
+		if err != nil {
+			return false, err
+		}
+		km, err := strconv.ParseUint(prompt("Enter odometer in km (0 to clear): "), 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("invalid odometer: %v", err)
+		}
+		err = save.SetOdometer(doc, name, uint32(km))
+		return err == nil, err
+	case "e":
+		name, err := pickTruck(false)
+		if err != nil {
+			return false, err
+		}
+		err = save.ResetTripStats(doc, name)
+		return err == nil, err
+	case "f":
+		name, err := pickTruck(false)
+		if err != nil {
+			return false, err
+		}
+		text := prompt("Enter plate text: ")
+		country := strings.ToLower(prompt("Enter plate country (e.g. germany, empty to keep): "))
+		err = save.SetLicensePlate(doc, name, text, country)
+		return err == nil, err
+	}
+	return false, fmt.Errorf("invalid action")
+}
+
+// manageTrailers lists the trailers of the save and applies the chosen
+// change. It reports whether the save was changed.
+func manageTrailers(doc *sii.Document) (bool, error) {
+	trailers, err := save.ListTrailers(doc)
+	if err != nil {
+		return false, err
+	}
+	for i, t := range trailers {
+		owner := "company"
+		if t.Owned {
+			owner = "owned"
+		}
+		wear := max(t.TrailerBodyWear, t.ChassisWear)
+		for _, w := range t.WheelsWear {
+			wear = max(wear, w)
+		}
+		fmt.Printf("  %d. [%s] %s, %s, wear %.1f%%, cargo damage %.1f%%\n", i+1, owner,
+			t.Definition, save.LicensePlateText(string(t.LicensePlate)), float32(wear)*100, float32(t.CargoDamage)*100)
+		for _, slave := range t.Slaves {
+			fmt.Printf("       pulls %s\n", slave)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(text string) string {
+		fmt.Print(text)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	pickTrailer := func() (string, error) {
+		n, err := strconv.Atoi(prompt("Enter trailer number: "))
+		if err != nil || n < 1 || n > len(trailers) {
+			return "", fmt.Errorf("invalid trailer number")
+		}
+		return trailers[n-1].Name, nil
+	}
+
+	fmt.Println("  a. Repair a trailer")
+	fmt.Println("  b. Repair all trailers of the player")
+	fmt.Println("  c. Clear the cargo damage of the current job")
+	fmt.Println("  d. Change license plate")
+	switch strings.ToLower(prompt("Select action (a-d): ")) {
+	case "a":
+		name, err := pickTrailer()
+		if err != nil {
+			return false, err
+		}
+		unfixable := strings.ToLower(prompt("Also repair unfixable wear? (y/n): ")) == "y"
+		err = save.RepairTrailer(doc, name, unfixable)
+		return err == nil, err
+	case "b":
+		unfixable := strings.ToLower(prompt("Also repair unfixable wear? (y/n): ")) == "y"
+		err := save.RepairAllTrailers(doc, unfixable)
+		return err == nil, err
+	case "c":
+		err := save.ClearCargoDamage(doc)
+		return err == nil, err
+	case "d":
+		name, err := pickTrailer()
+		if err != nil {
+			return false, err
+		}
+		text := prompt("Enter plate text: ")
+		country := strings.ToLower(prompt("Enter plate country (e.g. germany, empty to keep): "))
+		err = save.SetTrailerLicensePlate(doc, name, text, country)
+		return err == nil, err
+	}
+	return false, fmt.Errorf("invalid action")
+}
+
+// shareTruckSettings copies settings of a truck of the player to a file, or
+// pastes them from a file, possibly written from another profile. It
+// reports whether the save was changed.
+func shareTruckSettings(doc *sii.Document) (bool, error) {
+	trucks, err := save.ListTrucks(doc)
+	if err != nil {
+		return false, err
+	}
+	if len(trucks) == 0 {
+		return false, fmt.Errorf("the player has no truck")
+	}
+	for i, t := range trucks {
+		fmt.Printf("  %d. %s\n", i+1, save.LicensePlateText(string(t.LicensePlate)))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(text string) string {
+		fmt.Print(text)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	n, err := strconv.Atoi(prompt("Enter truck number: "))
+	if err != nil || n < 1 || n > len(trucks) {
+		return false, fmt.Errorf("invalid truck number")
+	}
+	truck := trucks[n-1].Name
+
+	fmt.Println("  1. Whole truck")
+	fmt.Println("  2. Paint job")
+	fmt.Println("  3. Truck details (wear, fuel, odometer)")
+	fmt.Println("  4. License plate")
+	what, err := strconv.Atoi(prompt("Select settings (1-4): "))
+	if err != nil || what < 1 || what > 4 {
+		return false, fmt.Errorf("invalid settings")
+	}
+	settings := save.TruckSettings(what - 1)
+
+	path := prompt("Enter settings file (default truck_settings.sii): ")
+	if path == "" {
+		path = "truck_settings.sii"
+	}
+
+	switch strings.ToLower(prompt("Copy from this truck (c) or paste onto it (p)? ")) {
+	case "c":
+		data, err := save.CopyTruckSettings(doc, truck, settings)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return false, fmt.Errorf("write truck settings: %w", err)
+		}
+		fmt.Printf("Truck settings copied to %s\n", path)
+		return false, nil
+	case "p":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("read truck settings: %w", err)
+		}
+		err = save.PasteTruckSettings(doc, truck, data, settings)
+		return err == nil, err
+	}
+	return false, fmt.Errorf("invalid action")
+}
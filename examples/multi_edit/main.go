@@ -49,13 +49,7 @@ func main() {
 	fmt.Printf("document has %d blocks\n", len(doc.Blocks))
 
 	// Find the bank block.
-	var bankBlock *sii.Block
-	for i := range doc.Blocks {
-		if doc.Blocks[i].Type == "bank" {
-			bankBlock = &doc.Blocks[i]
-			break
-		}
-	}
+	bankBlock := doc.FirstBlockByType("bank")
 	if bankBlock == nil {
 		log.Fatalf("no bank block found in %s", path)
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -75,6 +76,21 @@ func (cd *CountryDictionary) GetCountry(city string) string {
 	return cd.byCity[city]
 }
 
+// Cities returns the cities of country, sorted.
+func (cd *CountryDictionary) Cities(country string) []string {
+	if cd == nil {
+		return nil
+	}
+	var out []string
+	for city, c := range cd.byCity {
+		if c == country {
+			out = append(out, city)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 // CountryProperties mirrors the data loaded from CountryProperties.csv in
 // TS SE Tool: a small lookup table of extra attributes per country.
 type CountryProperties struct {
@@ -1,9 +1,12 @@
 package gameref
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/externaldata"
 	"github.com/robebs/ts-se-tool-go/internal/sii"
@@ -43,6 +46,37 @@ func BuildGameRefCache(root, gameType string) (*externaldata.GameRefCache, error
 	return cache, nil
 }
 
+// readDefDocument decodes and parses a def file. @include directives are
+// resolved relative to the file, or to archiveRoot (the folder holding def/)
+// for absolute paths such as "/def/cargo/shared.sui".
+func readDefDocument(path, archiveRoot string) (*sii.Document, error) {
+	plain, err := siidecrypt.DecryptFile(path, true)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	doc, err := sii.ReadDocumentWithOptions(plain, sii.ReadOptions{
+		ResolveInclude: func(include string) ([]byte, error) {
+			target := filepath.Join(filepath.Dir(path), filepath.FromSlash(include))
+			if strings.HasPrefix(include, "/") {
+				target = filepath.Join(archiveRoot, filepath.FromSlash(include))
+			}
+			data, err := os.ReadFile(target)
+			if err != nil {
+				return nil, err
+			}
+			// Included fragments have no SiiNunit header, but may be 3nK scrambled.
+			if len(data) >= 4 && siidecrypt.SignatureType(binary.LittleEndian.Uint32(data)) == siidecrypt.Signature3nK {
+				return siidecrypt.Decode3nK(data)
+			}
+			return data, nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return doc, nil
+}
+
 func scanCargoDefs(cache *externaldata.GameRefCache, defRoot string) error {
 	cargoRoot := filepath.Join(defRoot, "cargo")
 	files, err := filepath.Glob(filepath.Join(cargoRoot, "*.sii"))
@@ -50,13 +84,9 @@ func scanCargoDefs(cache *externaldata.GameRefCache, defRoot string) error {
 		return err
 	}
 	for _, f := range files {
-		plain, err := siidecrypt.DecryptFile(f, true)
-		if err != nil {
-			return fmt.Errorf("decrypt cargo def %s: %w", f, err)
-		}
-		doc, err := sii.ReadDocument(plain)
+		doc, err := readDefDocument(f, filepath.Dir(defRoot))
 		if err != nil {
-			return fmt.Errorf("parse cargo def %s: %w", f, err)
+			return fmt.Errorf("cargo def: %w", err)
 		}
 		for _, b := range doc.Blocks {
 			if b.Type != "cargo_data" {
@@ -88,8 +118,24 @@ func scanCompanyDefs(cache *externaldata.GameRefCache, defRoot string) error {
 			c = &externaldata.ExtCompany{ID: companyID}
 			cache.CompaniesByID[companyID] = c
 		}
-		// We do not parse individual in/out files in detail here; it is enough
-		// for now to record the presence of the company in the cache.
+		// Cargo definitions are named after the cargo they are for:
+		// company/<id>/out/<cargo>.sii for the cargos the company ships.
+		c.CargosIn = appendCargoFiles(c.CargosIn, filepath.Join(companyRoot, companyID, "in"))
+		c.CargosOut = appendCargoFiles(c.CargosOut, filepath.Join(companyRoot, companyID, "out"))
 	}
 	return nil
 }
+
+// appendCargoFiles appends to cargos the names of the .sii files of dir
+// without their extension, skipping the ones already listed (DLCs extend
+// the companies of the base game).
+func appendCargoFiles(cargos []string, dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.sii"))
+	for _, f := range files {
+		id := strings.TrimSuffix(filepath.Base(f), ".sii")
+		if !slices.Contains(cargos, id) {
+			cargos = append(cargos, id)
+		}
+	}
+	return cargos
+}
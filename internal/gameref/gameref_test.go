@@ -0,0 +1,47 @@
+package gameref
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildGameRefCache_Companies(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"ETS2/base/def/company/ikea/in/wood.sii",
+		"ETS2/base/def/company/ikea/out/furniture.sii",
+		"ETS2/base/def/company/ikea/out/readme.txt",
+		"ETS2/base/def/company/ikea/editor/ikea.sii",
+		"ETS2/dlc_north/def/company/ikea/out/furniture.sii",
+		"ETS2/dlc_north/def/company/ikea/out/lamps.sii",
+		"ETS2/dlc_north/def/company/tesco/in/furniture.sii",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache, err := BuildGameRefCache(root, "ETS2")
+	if err != nil {
+		t.Fatalf("BuildGameRefCache: %v", err)
+	}
+	ikea := cache.CompaniesByID["ikea"]
+	if ikea == nil {
+		t.Fatalf("company ikea not found")
+	}
+	if !slices.Equal(ikea.CargosIn, []string{"wood"}) {
+		t.Errorf("ikea cargos in = %v, want [wood]", ikea.CargosIn)
+	}
+	if !slices.Equal(ikea.CargosOut, []string{"furniture", "lamps"}) {
+		t.Errorf("ikea cargos out = %v, want [furniture lamps]", ikea.CargosOut)
+	}
+	if tesco := cache.CompaniesByID["tesco"]; tesco == nil || len(tesco.CargosOut) != 0 || !slices.Equal(tesco.CargosIn, []string{"furniture"}) {
+		t.Errorf("tesco = %+v", tesco)
+	}
+}
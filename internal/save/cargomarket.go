@@ -0,0 +1,233 @@
+package save
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/externaldata"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// defaultCargoMarketJobs is how many jobs RandomizeCargoMarket offers at a
+// company that had none.
+const defaultCargoMarketJobs = 3
+
+// marketCompany is a company unit of the save, named
+// "company.volatile.<company>.<city>".
+type marketCompany struct {
+	block         *sii.Block
+	company, city string
+}
+
+// marketCompanies returns the company units of company in city. An empty
+// company or city matches all of them.
+func marketCompanies(doc *sii.Document, company, city string) []marketCompany {
+	var out []marketCompany
+	for _, b := range doc.BlocksByType("company") {
+		parts := strings.Split(b.Name, ".")
+		if len(parts) != 4 {
+			continue
+		}
+		c := marketCompany{block: b, company: parts[2], city: parts[3]}
+		if (company == "" || company == c.company) && (city == "" || city == c.city) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// RandomizeCargoMarket replaces the job offers of company in city (an empty
+// company or city selects all of them) with random jobs, as many as each
+// company offered. Cargos are only those the company ships according to the
+// game definitions of the world (World.GameRef), and they go to companies of
+// other cities that take them in. Trailers and trucks come from the jobs of
+// the world, so cargos that no job of the save uses are skipped. It returns
+// how many jobs were created.
+//
+// Saves do not hold the road network, so the distance of a new job is taken
+// from the offers of the save between the same cities, and targets with a
+// known distance are preferred. Other jobs are left at zero.
+func RandomizeCargoMarket(doc *sii.Document, w *world.World, company, city string) (int, error) {
+	if w == nil || w.GameRef == nil {
+		return 0, fmt.Errorf("randomize cargo market: game definitions are not loaded")
+	}
+	companies := marketCompanies(doc, company, city)
+	if len(companies) == 0 {
+		return 0, fmt.Errorf("no company %q in %q", company, city)
+	}
+	all := marketCompanies(doc, "", "")
+	distances := offerDistances(doc, all)
+
+	known := make(map[string]bool, len(w.Cargoes))
+	for _, cg := range w.Cargoes {
+		known[cg.ID] = true
+	}
+
+	// Every job is built and checked before any offer is replaced, so that a
+	// failure leaves the market as it was
+	type market struct {
+		company marketCompany
+		jobs    []JobOffer
+	}
+	var markets []market
+	for _, c := range companies {
+		def := w.GameRef.CompaniesByID[c.company]
+		if def == nil {
+			continue
+		}
+		var cargos []string
+		for _, cargo := range def.CargosOut {
+			if known[cargo] {
+				cargos = append(cargos, cargo)
+			}
+		}
+		if len(cargos) == 0 {
+			continue
+		}
+
+		count := len(sii.GetArray(c.block.Properties, "job_offer"))
+		if count == 0 {
+			count = defaultCargoMarketJobs
+		}
+		var jobs []JobOffer
+		for len(jobs) < count {
+			cargo := cargos[rand.Intn(len(cargos))]
+			target, ok := pickCargoTarget(all, w.GameRef, distances, c.city, cargo)
+			if !ok {
+				cargos = slices.DeleteFunc(cargos, func(s string) bool { return s == cargo })
+				if len(cargos) == 0 {
+					break
+				}
+				continue
+			}
+			job := JobOffer{
+				SourceCompany: c.company, SourceCity: c.city,
+				TargetCompany: target.company, TargetCity: target.city,
+				Cargo:      cargo,
+				Urgency:    rand.Intn(3),
+				DistanceKm: distances[cityPair{c.city, target.city}],
+				ExpiresIn:  defaultJobExpiration + rand.Intn(defaultJobExpiration),
+			}
+			if err := prepareJobOffer(doc, w, &job); err != nil {
+				// No trailer or truck known for this cargo
+				cargos = slices.DeleteFunc(cargos, func(s string) bool { return s == cargo })
+				if len(cargos) == 0 {
+					break
+				}
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+		if len(jobs) > 0 {
+			markets = append(markets, market{company: c, jobs: jobs})
+		}
+	}
+	if len(markets) == 0 {
+		return 0, fmt.Errorf("no cargo known for the selected companies")
+	}
+
+	created := 0
+	for _, m := range markets {
+		RemoveJobOffers(doc, sii.GetArray(m.company.block.Properties, "job_offer")...)
+		names, err := AddJobOffers(doc, w, m.jobs...)
+		created += len(names)
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// cityPair is a source and a target city.
+type cityPair struct{ from, to string }
+
+// offerDistances returns the shortest_distance_km of the job offers of the
+// save between two cities, both ways.
+func offerDistances(doc *sii.Document, all []marketCompany) map[cityPair]int {
+	distances := make(map[cityPair]int)
+	for _, c := range all {
+		for _, name := range sii.GetArray(c.block.Properties, "job_offer") {
+			b := doc.Block(name)
+			if b == nil {
+				continue
+			}
+			_, to, ok := strings.Cut(sii.Unquote(firstProp(b.Properties, "target")), ".")
+			km := parseIntProp(b.Properties, "shortest_distance_km")
+			if !ok || km <= 0 || to == c.city {
+				continue
+			}
+			distances[cityPair{c.city, to}] = km
+			if _, known := distances[cityPair{to, c.city}]; !known {
+				distances[cityPair{to, c.city}] = km
+			}
+		}
+	}
+	return distances
+}
+
+// pickCargoTarget returns a random company outside of city that takes cargo
+// in, or any company outside of city when the game definitions do not say.
+// Companies at a known distance from city are preferred.
+func pickCargoTarget(all []marketCompany, ref *externaldata.GameRefCache, distances map[cityPair]int, city, cargo string) (marketCompany, bool) {
+	var targets, near []marketCompany
+	for _, c := range all {
+		if c.city == city {
+			continue
+		}
+		def := ref.CompaniesByID[c.company]
+		if def == nil || len(def.CargosIn) > 0 && !slices.Contains(def.CargosIn, cargo) {
+			continue
+		}
+		targets = append(targets, c)
+		if distances[cityPair{city, c.city}] > 0 {
+			near = append(near, c)
+		}
+	}
+	if len(near) > 0 {
+		targets = near
+	}
+	if len(targets) == 0 {
+		return marketCompany{}, false
+	}
+	return targets[rand.Intn(len(targets))], true
+}
+
+// ResetCargoMarket clears the job offers of company in city (an empty
+// company or city selects all of them) so that the game offers new ones.
+// The offers are kept but expire now, as they would in the game; with
+// remove, they are deleted instead. It returns how many offers were reset.
+func ResetCargoMarket(doc *sii.Document, company, city string, remove bool) (int, error) {
+	companies := marketCompanies(doc, company, city)
+	if len(companies) == 0 {
+		return 0, fmt.Errorf("no company %q in %q", company, city)
+	}
+
+	var offers []string
+	for _, c := range companies {
+		offers = append(offers, sii.GetArray(c.block.Properties, "job_offer")...)
+	}
+	if remove {
+		return RemoveJobOffers(doc, offers...), nil
+	}
+
+	var now string
+	if econBlock := doc.FirstBlockByType("economy"); econBlock != nil {
+		now = firstProp(econBlock.Properties, "game_time")
+	}
+	if now == "" {
+		return 0, fmt.Errorf("economy game_time not found")
+	}
+	reset := 0
+	for _, name := range offers {
+		b := doc.Block(name)
+		if b == nil || b.Type != "job_offer_data" {
+			continue
+		}
+		b.Properties["expiration_time"] = []string{now}
+		reset++
+	}
+	return reset, nil
+}
@@ -0,0 +1,130 @@
+package save
+
+import (
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/externaldata"
+	"github.com/robebs/ts-se-tool-go/internal/save/itemsextra"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// marketDoc has a furniture maker in paris, a shop that takes furniture in
+// berlin and lyon, and a quarry in madrid. The only job goes to berlin.
+const marketDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ game_time: 5000
+}
+company : company.volatile.ikea.paris {
+ job_offer: 1
+ job_offer[0]: _nameless.10
+}
+job_offer_data : _nameless.10 {
+ target: "tesco.berlin"
+ expiration_time: 9000
+ shortest_distance_km: 1050
+ cargo: cargo.furniture
+}
+company : company.volatile.tesco.berlin {
+ job_offer: 0
+}
+company : company.volatile.tesco.lyon {
+ job_offer: 0
+}
+company : company.volatile.quarry.madrid {
+ job_offer: 0
+}
+}
+`
+
+// marketGameRef stands for the company definitions of the game.
+var marketGameRef = &externaldata.GameRefCache{
+	CompaniesByID: map[string]*externaldata.ExtCompany{
+		"ikea":   {ID: "ikea", CargosOut: []string{"furniture", "lamps"}},
+		"tesco":  {ID: "tesco", CargosIn: []string{"furniture"}},
+		"quarry": {ID: "quarry", CargosIn: []string{"gravel"}},
+	},
+}
+
+func TestPickCargoTarget(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(marketDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	all := marketCompanies(doc, "", "")
+
+	for range 20 {
+		target, ok := pickCargoTarget(all, marketGameRef, nil, "paris", "furniture")
+		if !ok || target.company != "tesco" {
+			t.Fatalf("pickCargoTarget = %+v, %v; want a tesco shop", target, ok)
+		}
+	}
+	distances := offerDistances(doc, all)
+	for range 20 {
+		target, _ := pickCargoTarget(all, marketGameRef, distances, "paris", "furniture")
+		if target.city != "berlin" {
+			t.Fatalf("pickCargoTarget went to %s, want berlin at a known distance", target.city)
+		}
+	}
+	if _, ok := pickCargoTarget(all, marketGameRef, nil, "paris", "lamps"); ok {
+		t.Errorf("pickCargoTarget found a target for a cargo nobody takes in")
+	}
+	if _, ok := pickCargoTarget(all, marketGameRef, nil, "berlin", "gravel"); !ok {
+		t.Errorf("pickCargoTarget found no quarry for gravel")
+	}
+}
+
+func TestRandomizeCargoMarket(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(marketDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	w := jobsWorld()
+	w.Cities = append(w.Cities, &itemsextra.City{Name: "lyon", Companies: []string{"tesco"}})
+	w.GameRef = marketGameRef
+
+	if _, err := RandomizeCargoMarket(doc, world.NewWorld(nil), "ikea", ""); err == nil {
+		t.Errorf("RandomizeCargoMarket ran without game definitions")
+	}
+	n, err := RandomizeCargoMarket(doc, w, "ikea", "paris")
+	if err != nil {
+		t.Fatalf("RandomizeCargoMarket: %v", err)
+	}
+	offers := sii.GetArray(doc.Block("company.volatile.ikea.paris").Properties, "job_offer")
+	if n != 1 || len(offers) != 1 || offers[0] == "_nameless.10" {
+		t.Fatalf("RandomizeCargoMarket = %d, job_offer = %v; want the job replaced", n, offers)
+	}
+	props := doc.Block(offers[0]).Properties
+	if target := firstProp(props, "target"); target != `"tesco.berlin"` {
+		t.Errorf("target = %s, want the company at a known distance", target)
+	}
+	if km := firstProp(props, "shortest_distance_km"); km != "1050" {
+		t.Errorf("shortest_distance_km = %s, want 1050", km)
+	}
+}
+
+func TestResetCargoMarket(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		doc, err := sii.ReadDocument([]byte(marketDoc))
+		if err != nil {
+			t.Fatalf("ReadDocument: %v", err)
+		}
+		n, err := ResetCargoMarket(doc, "", "paris", remove)
+		if err != nil || n != 1 {
+			t.Fatalf("ResetCargoMarket(remove %v) = %d, %v; want 1", remove, n, err)
+		}
+		offer := doc.Block("_nameless.10")
+		switch {
+		case remove && offer != nil:
+			t.Errorf("the offer was not removed")
+		case !remove && offer == nil:
+			t.Errorf("the offer was removed instead of expired")
+		case !remove && firstProp(offer.Properties, "expiration_time") != "5000":
+			t.Errorf("expiration_time = %s, want the game time", firstProp(offer.Properties, "expiration_time"))
+		}
+	}
+	if _, err := ResetCargoMarket(&sii.Document{}, "ikea", "", false); err == nil {
+		t.Errorf("ResetCargoMarket accepted a save without companies")
+	}
+}
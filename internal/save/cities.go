@@ -0,0 +1,138 @@
+package save
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// VisitCities marks cities as visited in economy.visited_cities, with one
+// visit in visited_cities_count. Cities already visited are left alone, and
+// names that are not cities of the save (see saveCities) are skipped. It
+// returns how many cities were added and the skipped names.
+func VisitCities(doc *sii.Document, cities ...string) (int, []string, error) {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return 0, nil, fmt.Errorf("economy block not found")
+	}
+	visited, counts := visitedCities(econBlock)
+	inSave := saveCities(doc)
+
+	known := make(map[string]bool, len(visited))
+	for _, c := range visited {
+		known[c] = true
+	}
+	added := 0
+	var skipped []string
+	for _, city := range cities {
+		if city == "" || known[city] {
+			continue
+		}
+		if !inSave[city] {
+			skipped = append(skipped, city)
+			continue
+		}
+		known[city] = true
+		visited = append(visited, city)
+		counts = append(counts, "1")
+		added++
+	}
+
+	putVisitedCities(econBlock, visited, counts)
+	return added, skipped, nil
+}
+
+// saveCities returns the cities of the save: those of the company units
+// ("company.volatile.<company>.<city>") and of the garages.
+func saveCities(doc *sii.Document) map[string]bool {
+	cities := make(map[string]bool)
+	for _, c := range marketCompanies(doc, "", "") {
+		cities[c.city] = true
+	}
+	for _, b := range doc.BlocksByType("garage") {
+		if city, ok := strings.CutPrefix(b.Name, "garage."); ok {
+			cities[city] = true
+		}
+	}
+	return cities
+}
+
+// UnvisitCities removes cities from economy.visited_cities and their visit
+// counts. It returns how many cities were removed.
+func UnvisitCities(doc *sii.Document, cities ...string) (int, error) {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return 0, fmt.Errorf("economy block not found")
+	}
+	visited, counts := visitedCities(econBlock)
+
+	drop := make(map[string]bool, len(cities))
+	for _, c := range cities {
+		drop[c] = true
+	}
+	var keptCities, keptCounts []string
+	for i, c := range visited {
+		if !drop[c] {
+			keptCities = append(keptCities, c)
+			keptCounts = append(keptCounts, counts[i])
+		}
+	}
+
+	putVisitedCities(econBlock, keptCities, keptCounts)
+	return len(visited) - len(keptCities), nil
+}
+
+// ResetExploration clears the world map exploration of the player: the
+// discovered map items and the discovered road length.
+func ResetExploration(doc *sii.Document) error {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+	sii.PutArray(econBlock.Properties, "discovered_items", nil)
+	if _, ok := econBlock.Properties["discovered_roads"]; ok {
+		econBlock.Properties["discovered_roads"] = []string{"0"}
+	}
+	return nil
+}
+
+// visitedCities returns economy.visited_cities and visited_cities_count,
+// the counts padded with one visit if the arrays disagree.
+func visitedCities(econBlock *sii.Block) ([]string, []string) {
+	visited := sii.GetArray(econBlock.Properties, "visited_cities")
+	counts := sii.GetArray(econBlock.Properties, "visited_cities_count")
+	for len(counts) < len(visited) {
+		counts = append(counts, "1")
+	}
+	return visited, counts[:len(visited)]
+}
+
+func putVisitedCities(econBlock *sii.Block, visited, counts []string) {
+	cityValues := make(sii.Array, len(visited))
+	countValues := make(sii.Array, len(counts))
+	for i := range visited {
+		cityValues[i] = sii.Token(visited[i])
+		countValues[i] = sii.Token(counts[i])
+	}
+	sii.PutArray(econBlock.Properties, "visited_cities", cityValues)
+	sii.PutArray(econBlock.Properties, "visited_cities_count", countValues)
+}
+
+// SyncInfoVisitedCities sets info_visited_cities of the save_container of
+// info.sii to the number of cities visited in game.sii, as the game shows it
+// in the save list.
+func SyncInfoVisitedCities(info, game *sii.Document) error {
+	container := info.FirstBlockByType("save_container")
+	if container == nil {
+		return fmt.Errorf("save_container block not found")
+	}
+	econBlock := game.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+	visited, _ := visitedCities(econBlock)
+	container.Properties["info_visited_cities"] = []string{strconv.Itoa(len(visited))}
+	return nil
+}
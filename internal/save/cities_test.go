@@ -0,0 +1,102 @@
+package save
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const citiesDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ companies: 3
+ companies[0]: company.volatile.ikea.paris
+ companies[1]: company.volatile.ikea.lyon
+ companies[2]: company.volatile.tesco.berlin
+ garages: 1
+ garages[0]: garage.madrid
+ visited_cities: 1
+ visited_cities[0]: paris
+ visited_cities_count: 1
+ visited_cities_count[0]: 4
+ discovered_items: 2
+ discovered_items[0]: 123
+ discovered_items[1]: 456
+ discovered_roads: 5000
+}
+company : company.volatile.ikea.paris {
+}
+company : company.volatile.ikea.lyon {
+}
+company : company.volatile.tesco.berlin {
+}
+garage : garage.madrid {
+}
+}
+`
+
+func TestVisitCities(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(citiesDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	n, skipped, err := VisitCities(doc, "paris", "lyon", "madrid", "atlantis", "lyon")
+	if err != nil {
+		t.Fatalf("VisitCities: %v", err)
+	}
+	if n != 2 || !slices.Equal(skipped, []string{"atlantis"}) {
+		t.Errorf("VisitCities = %d, %v; want 2, [atlantis]", n, skipped)
+	}
+	econ := doc.FirstBlockByType("economy").Properties
+	if got := sii.GetArray(econ, "visited_cities"); !slices.Equal(got, []string{"paris", "lyon", "madrid"}) {
+		t.Errorf("visited_cities = %v", got)
+	}
+	if got := sii.GetArray(econ, "visited_cities_count"); !slices.Equal(got, []string{"4", "1", "1"}) {
+		t.Errorf("visited_cities_count = %v", got)
+	}
+}
+
+func TestUnvisitCities(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(citiesDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if _, _, err := VisitCities(doc, "lyon", "berlin"); err != nil {
+		t.Fatalf("VisitCities: %v", err)
+	}
+	n, err := UnvisitCities(doc, "paris", "madrid")
+	if err != nil {
+		t.Fatalf("UnvisitCities: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("UnvisitCities = %d, want 1", n)
+	}
+	econ := doc.FirstBlockByType("economy").Properties
+	if got := sii.GetArray(econ, "visited_cities"); !slices.Equal(got, []string{"lyon", "berlin"}) {
+		t.Errorf("visited_cities = %v", got)
+	}
+	if got := sii.GetArray(econ, "visited_cities_count"); len(got) != 2 {
+		t.Errorf("visited_cities_count = %v, want 2 counts", got)
+	}
+}
+
+func TestResetExploration(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(citiesDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if err := ResetExploration(doc); err != nil {
+		t.Fatalf("ResetExploration: %v", err)
+	}
+	econ := doc.FirstBlockByType("economy").Properties
+	if got := sii.GetArray(econ, "discovered_items"); len(got) != 0 {
+		t.Errorf("discovered_items = %v, want none", got)
+	}
+	if roads := firstProp(econ, "discovered_roads"); roads != "0" {
+		t.Errorf("discovered_roads = %s, want 0", roads)
+	}
+	if got := sii.GetArray(econ, "visited_cities"); len(got) != 1 {
+		t.Errorf("visited_cities = %v, want them kept", got)
+	}
+}
@@ -0,0 +1,112 @@
+package convoy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/save"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// CloneSave writes a copy of docs, the save loaded from profileDir, into a
+// new numbered save slot for each position, with the player moved there.
+// This is the "Create multiple saves with different truck positions" convoy
+// tool. The clones are named "<name> <n>" in the save list, name defaulting
+// to the name of the save. profile.sii is left untouched, and docs is
+// restored afterwards. It returns the new slots.
+func CloneSave(profileDir string, docs *save.Documents, positions []Position, name string) ([]string, error) {
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("no position to clone the save to")
+	}
+	if docs.Game == nil || docs.Info == nil {
+		return nil, fmt.Errorf("clone save: game.sii and info.sii are required")
+	}
+	playerBlock := docs.Game.FirstBlockByType("player")
+	if playerBlock == nil {
+		return nil, fmt.Errorf("player block not found")
+	}
+	container := docs.Info.FirstBlockByType("save_container")
+	if container == nil {
+		return nil, fmt.Errorf("save_container block not found")
+	}
+	if name == "" {
+		name = sii.Unquote(firstProp(container.Properties, "name"))
+	}
+	for i, pos := range positions {
+		if err := pos.check(); err != nil {
+			return nil, fmt.Errorf("position %d: %w", i+1, err)
+		}
+	}
+
+	// Put the player and the save container back as they were
+	savedPlayer, savedContainer := snapshot(playerBlock), snapshot(container)
+	defer func() {
+		savedPlayer.restore(playerBlock)
+		savedContainer.restore(container)
+	}()
+
+	next, err := nextNumberedSlot(profileDir)
+	if err != nil {
+		return nil, err
+	}
+	var slots []string
+	for i, pos := range positions {
+		if err := SetPosition(docs.Game, pos); err != nil {
+			return slots, fmt.Errorf("position %d: %w", i+1, err)
+		}
+		container.Properties["name"] = []string{sii.Quote(fmt.Sprintf("%s %d", name, i+1))}
+
+		slot := strconv.Itoa(next + i)
+		if err := save.WriteGameSII(profileDir, slot, docs.Game, docs.GameFormat); err != nil {
+			return slots, fmt.Errorf("write save %s: %w", slot, err)
+		}
+		saveDir := filepath.Join(profileDir, "save", slot)
+		if err := save.WriteInfoSII(saveDir, docs.Info, docs.InfoFormat); err != nil {
+			return slots, fmt.Errorf("write save %s: %w", slot, err)
+		}
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+// blockState is a copy of the properties of a block and of their order.
+type blockState struct {
+	props map[string][]string
+	order []string
+}
+
+func snapshot(b *sii.Block) blockState {
+	s := blockState{
+		props: make(map[string][]string, len(b.Properties)),
+		order: slices.Clone(b.PropertyOrder),
+	}
+	for key, vals := range b.Properties {
+		s.props[key] = slices.Clone(vals)
+	}
+	return s
+}
+
+// restore puts the properties of s back in b, dropping those added since.
+func (s blockState) restore(b *sii.Block) {
+	b.Properties = s.props
+	b.PropertyOrder = s.order
+}
+
+// nextNumberedSlot returns the number following the highest numbered save
+// slot of the profile.
+func nextNumberedSlot(profileDir string) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(profileDir, "save"))
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("list save slots: %w", err)
+	}
+	next := 1
+	for _, e := range entries {
+		if n, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() && n >= next {
+			next = n + 1
+		}
+	}
+	return next, nil
+}
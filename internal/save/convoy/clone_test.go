@@ -0,0 +1,80 @@
+package convoy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const infoDoc = `SiiNunit
+{
+save_container : _nameless.1 {
+ name: "Before the convoy"
+ time: 1000
+}
+}
+`
+
+func TestCloneSave(t *testing.T) {
+	profile := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(profile, "save", "3"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	docs := &save.Documents{Game: readDoc(t, leaderDoc), Info: readDoc(t, infoDoc)}
+	player := docs.Game.FirstBlockByType("player")
+	wantPlayer := snapshot(player)
+
+	positions := []Position{
+		{TruckPlacement: "(1, 0, 1) (1; 0, 0, 0)", TrailerPlacement: "(2, 0, 1) (1; 0, 0, 0)"},
+		{TruckPlacement: "(5, 0, 5) (1; 0, 0, 0)", TrailerPlacement: "(6, 0, 5) (1; 0, 0, 0)",
+			SlaveTrailerPlacements: []string{"(7, 0, 5) (1; 0, 0, 0)", "(8, 0, 5) (1; 0, 0, 0)"}},
+	}
+	slots, err := CloneSave(profile, docs, positions, "Convoy")
+	if err != nil {
+		t.Fatalf("CloneSave: %v", err)
+	}
+	if !slices.Equal(slots, []string{"4", "5"}) {
+		t.Fatalf("slots = %v, want [4 5]", slots)
+	}
+
+	for i, slot := range slots {
+		dir := filepath.Join(profile, "save", slot)
+		game := readFile(t, filepath.Join(dir, "game.sii"))
+		pos, err := PositionOf(game)
+		if err != nil {
+			t.Fatalf("slot %s: %v", slot, err)
+		}
+		if !slices.Equal(pos.SlaveTrailerPlacements, positions[i].SlaveTrailerPlacements) {
+			t.Errorf("slot %s: slave trailers at %v, want %v", slot, pos.SlaveTrailerPlacements, positions[i].SlaveTrailerPlacements)
+		}
+		if pos.TruckPlacement != positions[i].TruckPlacement {
+			t.Errorf("slot %s: truck at %s, want %s", slot, pos.TruckPlacement, positions[i].TruckPlacement)
+		}
+		info := readFile(t, filepath.Join(dir, "info.sii"))
+		want := sii.Quote("Convoy " + []string{"1", "2"}[i])
+		if name := firstProp(info.FirstBlockByType("save_container").Properties, "name"); name != want {
+			t.Errorf("slot %s: name = %s, want %s", slot, name, want)
+		}
+	}
+
+	if got := snapshot(player); !reflect.DeepEqual(got, wantPlayer) {
+		t.Errorf("player not restored:\n got %+v\nwant %+v", got, wantPlayer)
+	}
+	if name := firstProp(docs.Info.FirstBlockByType("save_container").Properties, "name"); name != `"Before the convoy"` {
+		t.Errorf("save name not restored: %s", name)
+	}
+}
+
+func readFile(t *testing.T, path string) *sii.Document {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return readDoc(t, string(data))
+}
@@ -0,0 +1,310 @@
+// Package convoy copies the position of the player's truck and the GPS route
+// from one save to another, so that a group of players can start a convoy
+// side by side. A Bundle is exported from game.sii, passed around as JSON or
+// as a one-line text blob, and applied to the game.sii of another profile.
+package convoy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// Bundle is the position of the player's truck and trailers and the GPS
+// routes of a save.
+type Bundle struct {
+	Position
+	GPS       Route `json:"gps"`
+	OnlineGPS Route `json:"online_gps"`
+}
+
+// Position is where the player's truck and trailers stand. Placements keep
+// their game.sii text, "(x, y, z) (w; x, y, z)".
+type Position struct {
+	TruckPlacement         string   `json:"truck_placement"`
+	TrailerPlacement       string   `json:"trailer_placement,omitempty"` // only with a connected trailer
+	SlaveTrailerPlacements []string `json:"slave_trailer_placements,omitempty"`
+}
+
+// Route is a GPS route of the economy unit: the stored_gps_* properties, or
+// the stored_online_gps_* ones used by World of Trucks jobs.
+type Route struct {
+	Behind          []string   `json:"behind,omitempty"`
+	Ahead           []string   `json:"ahead,omitempty"`
+	BehindWaypoints []Waypoint `json:"behind_waypoints,omitempty"`
+	AheadWaypoints  []Waypoint `json:"ahead_waypoints,omitempty"`
+	AvoidWaypoints  []Waypoint `json:"avoid_waypoints,omitempty"`
+}
+
+// Waypoint is a gps_waypoint_storage unit, with its game.sii text.
+type Waypoint struct {
+	NavNodePosition string `json:"nav_node_position"` // "(x, y, z)"
+	Direction       string `json:"direction"`
+}
+
+// Format is an encoding of a Bundle.
+type Format int
+
+const (
+	FormatText Format = iota // one line, easy to paste in a chat
+	FormatJSON
+)
+
+// textPrefix starts the text form of a bundle, followed by its JSON in
+// base64.
+const textPrefix = "convoy1:"
+
+// Export reads the bundle of the player of doc.
+func Export(doc *sii.Document) (*Bundle, error) {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return nil, fmt.Errorf("economy block not found")
+	}
+	pos, err := PositionOf(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{Position: pos}
+	if b.GPS, err = exportRoute(doc, econBlock, "stored_gps_"); err != nil {
+		return nil, err
+	}
+	if b.OnlineGPS, err = exportRoute(doc, econBlock, "stored_online_gps_"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// PositionOf returns the position of the player of doc.
+func PositionOf(doc *sii.Document) (Position, error) {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return Position{}, fmt.Errorf("player block not found")
+	}
+	pos := Position{TruckPlacement: firstProp(playerBlock.Properties, "truck_placement")}
+	if pos.TruckPlacement == "" {
+		return Position{}, fmt.Errorf("player has no truck_placement")
+	}
+	if firstProp(playerBlock.Properties, "assigned_trailer_connected") == "true" {
+		pos.TrailerPlacement = firstProp(playerBlock.Properties, "trailer_placement")
+		pos.SlaveTrailerPlacements = sii.GetArray(playerBlock.Properties, "slave_trailer_placements")
+	}
+	return pos, nil
+}
+
+func exportRoute(doc *sii.Document, econBlock *sii.Block, prefix string) (Route, error) {
+	r := Route{
+		Behind: sii.GetArray(econBlock.Properties, prefix+"behind"),
+		Ahead:  sii.GetArray(econBlock.Properties, prefix+"ahead"),
+	}
+	for _, w := range []struct {
+		key string
+		dst *[]Waypoint
+	}{
+		{prefix + "behind_waypoints", &r.BehindWaypoints},
+		{prefix + "ahead_waypoints", &r.AheadWaypoints},
+		{prefix + "avoid_waypoints", &r.AvoidWaypoints},
+	} {
+		for _, name := range sii.GetArray(econBlock.Properties, w.key) {
+			block := doc.Block(name)
+			if block == nil {
+				return r, fmt.Errorf("%s: waypoint %s not found", w.key, name)
+			}
+			var wp items.GPSWaypointStorage
+			if err := wp.FromProperties(block.Properties); err != nil {
+				return r, fmt.Errorf("%s: %w", name, err)
+			}
+			*w.dst = append(*w.dst, Waypoint{NavNodePosition: wp.NavNodePosition.Format(), Direction: wp.Direction})
+		}
+	}
+	return r, nil
+}
+
+// Apply moves the player of doc to the position of b and replaces its GPS
+// routes with those of b. A trailer connected to the player's truck needs a
+// trailer position in b, and the player of b needs one connected for its
+// trailer position to be used.
+func Apply(doc *sii.Document, b *Bundle) error {
+	if err := b.check(); err != nil {
+		return err
+	}
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+	if err := SetPosition(doc, b.Position); err != nil {
+		return err
+	}
+
+	if err := applyRoute(doc, econBlock, "stored_gps_", b.GPS); err != nil {
+		return err
+	}
+	return applyRoute(doc, econBlock, "stored_online_gps_", b.OnlineGPS)
+}
+
+// SetPosition moves the player of doc to pos. A trailer connected to the
+// player's truck needs a trailer position in pos, which is ignored
+// otherwise.
+func SetPosition(doc *sii.Document, pos Position) error {
+	if err := pos.check(); err != nil {
+		return err
+	}
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return fmt.Errorf("player block not found")
+	}
+	connected := firstProp(playerBlock.Properties, "assigned_trailer_connected") == "true"
+	if connected && pos.TrailerPlacement == "" {
+		return fmt.Errorf("no trailer position for the connected trailer")
+	}
+
+	playerBlock.Properties["truck_placement"] = []string{pos.TruckPlacement}
+	if connected {
+		playerBlock.Properties["trailer_placement"] = []string{pos.TrailerPlacement}
+		sii.PutArray(playerBlock.Properties, "slave_trailer_placements", rawArray(pos.SlaveTrailerPlacements))
+	}
+	return nil
+}
+
+func applyRoute(doc *sii.Document, econBlock *sii.Block, prefix string, r Route) error {
+	for _, p := range []struct {
+		key    string
+		values []string
+	}{
+		{prefix + "behind", r.Behind},
+		{prefix + "ahead", r.Ahead},
+	} {
+		sii.PutArray(econBlock.Properties, p.key, rawArray(p.values))
+	}
+
+	for _, w := range []struct {
+		key       string
+		waypoints []Waypoint
+	}{
+		{prefix + "behind_waypoints", r.BehindWaypoints},
+		{prefix + "ahead_waypoints", r.AheadWaypoints},
+		{prefix + "avoid_waypoints", r.AvoidWaypoints},
+	} {
+		blocks := make([]*sii.Block, len(w.waypoints))
+		for i, wp := range w.waypoints {
+			pos, err := sii.ParseVec3i(wp.NavNodePosition)
+			if err != nil {
+				return fmt.Errorf("%s: %w", w.key, err)
+			}
+			storage := items.GPSWaypointStorage{NavNodePosition: pos, Direction: wp.Direction}
+			blocks[i] = &sii.Block{
+				Type:          "gps_waypoint_storage",
+				Name:          doc.NewNameless(),
+				Properties:    storage.ToProperties(),
+				PropertyOrder: []string{"nav_node_position", "direction"},
+			}
+		}
+
+		doc.RemoveCascade(items.Pointers, sii.GetArray(econBlock.Properties, w.key)...)
+		sii.PutArray(econBlock.Properties, w.key, nil)
+		if len(blocks) == 0 {
+			continue
+		}
+		if err := doc.AddOwned(items.Pointers, econBlock.Name, w.key, blocks...); err != nil {
+			return fmt.Errorf("add %s: %w", w.key, err)
+		}
+	}
+	return nil
+}
+
+// check validates the placements of pos, which end up in game.sii as they
+// are.
+func (pos Position) check() error {
+	placements := append([]string{pos.TruckPlacement}, pos.SlaveTrailerPlacements...)
+	if pos.TrailerPlacement != "" {
+		placements = append(placements, pos.TrailerPlacement)
+	}
+	for _, p := range placements {
+		if _, err := sii.ParsePlacement(p); err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+	}
+	return nil
+}
+
+// check validates the placements and route positions of b.
+func (b *Bundle) check() error {
+	if err := b.Position.check(); err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	for _, r := range []Route{b.GPS, b.OnlineGPS} {
+		for _, pos := range append(r.Behind, r.Ahead...) {
+			if _, err := sii.ParseVec3f(pos); err != nil {
+				return fmt.Errorf("invalid bundle: %w", err)
+			}
+		}
+		for _, waypoints := range [][]Waypoint{r.BehindWaypoints, r.AheadWaypoints, r.AvoidWaypoints} {
+			for _, wp := range waypoints {
+				if _, err := sii.ParseVec3i(wp.NavNodePosition); err != nil {
+					return fmt.Errorf("invalid bundle: %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Encode writes b in the given format.
+func Encode(b *Bundle, format Format) ([]byte, error) {
+	// Placements are full of '&', which is better left unescaped
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if format == FormatJSON {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(b); err != nil {
+		return nil, fmt.Errorf("encode bundle: %w", err)
+	}
+	if format == FormatJSON {
+		return buf.Bytes(), nil
+	}
+	blob := base64.StdEncoding.EncodeToString(bytes.TrimSpace(buf.Bytes()))
+	return []byte(textPrefix + blob + "\n"), nil
+}
+
+// Decode reads a bundle written by Encode in either format.
+func Decode(data []byte) (*Bundle, error) {
+	text := strings.TrimSpace(string(data))
+	if blob, ok := strings.CutPrefix(text, textPrefix); ok {
+		decoded, err := base64.StdEncoding.DecodeString(blob)
+		if err != nil {
+			return nil, fmt.Errorf("decode bundle: %w", err)
+		}
+		text = string(decoded)
+	}
+	var b Bundle
+	if err := json.Unmarshal([]byte(text), &b); err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// rawArray returns values, as read by sii.GetArray, for sii.PutArray.
+func rawArray(values []string) sii.Array {
+	arr := make(sii.Array, len(values))
+	for i, v := range values {
+		arr[i] = sii.Token(v)
+	}
+	return arr
+}
+
+func firstProp(props map[string][]string, key string) string {
+	if vals := props[key]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package convoy
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// leaderDoc is the save of a player with a connected trailer and a GPS
+// route through one waypoint.
+const leaderDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ stored_gps_behind: 1
+ stored_gps_behind[0]: (&c71f6d84, &41f017a0, &47085e11)
+ stored_gps_ahead: 2
+ stored_gps_ahead[0]: (100, 0, 200)
+ stored_gps_ahead[1]: (300, 0, 400)
+ stored_gps_behind_waypoints: 0
+ stored_gps_ahead_waypoints: 1
+ stored_gps_ahead_waypoints[0]: _nameless.2
+ stored_gps_avoid_waypoints: 0
+ stored_online_gps_behind: 0
+ stored_online_gps_ahead: 0
+ stored_online_gps_behind_waypoints: 0
+ stored_online_gps_ahead_waypoints: 0
+ stored_online_gps_avoid_waypoints: 0
+}
+gps_waypoint_storage : _nameless.2 {
+ nav_node_position: (300, 0, 400)
+ direction: forward
+}
+player : _nameless.3 {
+ assigned_trailer_connected: true
+ truck_placement: (&c71f6d84, &41f017a0, &47085e11) (&bf755420; &38568dd2, &3e924921, &b97be82b)
+ trailer_placement: (-10, 0, 5) (1; 0, 0, 0)
+ slave_trailer_placements: 1
+ slave_trailer_placements[0]: (-20, 0, 5) (1; 0, 0, 0)
+}
+}
+`
+
+// followerDoc is the save of a player without a route, whose trailer is
+// connected or not depending on the test.
+const followerDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ stored_gps_behind: 0
+ stored_gps_ahead: 0
+ stored_gps_behind_waypoints: 0
+ stored_gps_ahead_waypoints: 0
+ stored_gps_avoid_waypoints: 1
+ stored_gps_avoid_waypoints[0]: _nameless.9
+ stored_online_gps_behind: 0
+ stored_online_gps_ahead: 0
+ stored_online_gps_behind_waypoints: 0
+ stored_online_gps_ahead_waypoints: 0
+ stored_online_gps_avoid_waypoints: 0
+}
+gps_waypoint_storage : _nameless.9 {
+ nav_node_position: (1, 2, 3)
+ direction: backward
+}
+player : _nameless.3 {
+ assigned_trailer_connected: true
+ truck_placement: (0, 0, 0) (1; 0, 0, 0)
+ trailer_placement: (0, 0, 0) (1; 0, 0, 0)
+ slave_trailer_placements: 0
+}
+}
+`
+
+func readDoc(t *testing.T, text string) *sii.Document {
+	t.Helper()
+	doc, err := sii.ReadDocument([]byte(text))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	return doc
+}
+
+// sameBundle compares bundles by their JSON, which has no nil and empty
+// slices to tell apart.
+func sameBundle(t *testing.T, a, b *Bundle) bool {
+	t.Helper()
+	ja, err := Encode(a, FormatJSON)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	jb, err := Encode(b, FormatJSON)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return bytes.Equal(ja, jb)
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	leader := readDoc(t, leaderDoc)
+	bundle, err := Export(leader)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(bundle.SlaveTrailerPlacements) != 1 || len(bundle.GPS.AheadWaypoints) != 1 {
+		t.Fatalf("Export = %+v, want the slave trailer and the waypoint", bundle)
+	}
+
+	for _, format := range []Format{FormatText, FormatJSON} {
+		data, err := Encode(bundle, format)
+		if err != nil {
+			t.Fatalf("Encode(%d): %v", format, err)
+		}
+		decoded, err := Decode(data)
+		if err != nil {
+			t.Fatalf("Decode(%d): %v", format, err)
+		}
+		if !sameBundle(t, decoded, bundle) {
+			t.Errorf("format %d: decoded %+v, want %+v", format, decoded, bundle)
+		}
+
+		follower := readDoc(t, followerDoc)
+		if err := Apply(follower, decoded); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+		got, err := Export(follower)
+		if err != nil {
+			t.Fatalf("Export after Apply: %v", err)
+		}
+		if !sameBundle(t, got, bundle) {
+			t.Errorf("format %d: applied bundle reads back as %+v, want %+v", format, got, bundle)
+		}
+		if follower.Block("_nameless.9") != nil {
+			t.Errorf("the old avoid waypoint is still in the save")
+		}
+		if refs := follower.DanglingReferences(items.Pointers); len(refs) != 0 {
+			t.Errorf("dangling references: %v", refs)
+		}
+	}
+}
+
+func TestApply_ConnectedTrailer(t *testing.T) {
+	bundle, err := Export(readDoc(t, leaderDoc))
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	bundle.TrailerPlacement = ""
+	bundle.SlaveTrailerPlacements = nil
+
+	follower := readDoc(t, followerDoc)
+	if err := Apply(follower, bundle); err == nil {
+		t.Fatalf("Apply moved a connected trailer without a trailer position")
+	}
+	player := follower.FirstBlockByType("player").Properties
+	if got := player["truck_placement"][0]; got != "(0, 0, 0) (1; 0, 0, 0)" {
+		t.Errorf("truck_placement = %s, want the save left alone", got)
+	}
+	if got := sii.GetArray(follower.FirstBlockByType("economy").Properties, "stored_gps_avoid_waypoints"); !slices.Equal(got, []string{"_nameless.9"}) {
+		t.Errorf("stored_gps_avoid_waypoints = %v, want the save left alone", got)
+	}
+
+	// Without a connected trailer, the truck alone is moved
+	player["assigned_trailer_connected"] = []string{"false"}
+	if err := Apply(follower, bundle); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := player["truck_placement"][0]; got != bundle.TruckPlacement {
+		t.Errorf("truck_placement = %s, want %s", got, bundle.TruckPlacement)
+	}
+	if got := player["trailer_placement"][0]; got != "(0, 0, 0) (1; 0, 0, 0)" {
+		t.Errorf("trailer_placement = %s, want it left alone", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // This package contains low-level data types used across save-game items,
@@ -66,33 +68,18 @@ type Float float32
 // String wraps a plain string; added for symmetry with SCS_string.
 type String string
 
-// Vector2f mirrors Vector_2f.
-type Vector2f struct {
-	X float32
-	Y float32
-}
+// Vector2f mirrors Vector_2f. The vector types are those of the sii value
+// layer, which formats and parses them.
+type Vector2f = sii.Vec2f
 
 // Vector3f mirrors Vector_3f.
-type Vector3f struct {
-	X float32
-	Y float32
-	Z float32
-}
+type Vector3f = sii.Vec3f
 
 // Vector4f mirrors Vector_4f and the 3f_4f variants.
-type Vector4f struct {
-	X float32
-	Y float32
-	Z float32
-	W float32
-}
+type Vector4f = sii.Quat
 
 // Vector3i mirrors Vector_3i.
-type Vector3i struct {
-	X int32
-	Y int32
-	Z int32
-}
+type Vector3i = sii.Vec3i
 
 // Uint32ToFloat32 converts a uint32 to float32 by reinterpreting the bits.
 func Uint32ToFloat32(v uint32) float32 {
@@ -0,0 +1,312 @@
+package save
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// SkillMode selects how the skills and experience of recruited drivers are
+// chosen.
+type SkillMode int
+
+const (
+	// SkillsFixed gives every driver DriverSkills.Level and DriverSkills.XP.
+	SkillsFixed SkillMode = iota
+	// SkillsRandom draws each skill in [Level, MaxLevel] and the experience
+	// in [XP, MaxXP].
+	SkillsRandom
+	// SkillsMirrorPlayer copies the skills and experience of the player.
+	SkillsMirrorPlayer
+)
+
+// MaxSkillLevel is the highest level of a driver skill. The ADR skill is
+// stored as a mask of the learned classes, one bit per level.
+const MaxSkillLevel = 6
+
+// DriverSkills is the skill and experience distribution of recruited
+// drivers.
+type DriverSkills struct {
+	Mode     SkillMode
+	Level    int
+	XP       int
+	MaxLevel int // SkillsRandom only
+	MaxXP    int // SkillsRandom only
+}
+
+// RecruitOptions configures RecruitEmployeesAndPopulateTrucks.
+type RecruitOptions struct {
+	// Names is the driver name table of the game
+	// (lang/Default/<Game>/driver_names.csv, see externaldata.LoadDriverNames).
+	// Drivers are first hired from economy.driver_pool, like the game does;
+	// once the pool is empty, new drivers take IDs from Names that no unit of
+	// the save uses yet, so that the game shows them with a name. Without
+	// it, or once it is exhausted, drivers are numbered after the existing
+	// ones.
+	Names  map[string]string
+	Skills DriverSkills
+}
+
+// driverSkillKeys are the skill properties shared by economy (the player)
+// and driver_ai.
+var driverSkillKeys = []string{"adr", "long_dist", "heavy", "fragile", "urgent", "mechanical"}
+
+// driverAIOrder is the property order of driver_ai units in game saves.
+var driverAIOrder = []string{
+	"adr", "long_dist", "heavy", "fragile", "urgent", "mechanical",
+	"hometown", "current_city", "state", "on_duty_timer", "extra_maintenance",
+	"driver_job", "experience_points", "training_policy",
+	"adopted_truck", "assigned_truck", "assigned_truck_efficiency",
+	"assigned_truck_axle_count", "assigned_truck_mass",
+	"slot_truck_efficiency", "slot_truck_axle_count", "slot_truck_mass",
+	"adopted_trailer", "assigned_trailer", "old_hometown", "profit_log",
+}
+
+// jobInfoOrder is the property order of job_info units in game saves.
+var jobInfoOrder = []string{
+	"cargo", "source_company", "target_company", "cargo_model_index",
+	"is_articulated", "is_cargo_market_job", "start_time", "planned_distance_km",
+	"ferry_time", "ferry_price", "urgency", "special", "units_count", "fill_ratio",
+}
+
+// recruiter creates drivers for RecruitEmployeesAndPopulateTrucks.
+type recruiter struct {
+	doc       *sii.Document
+	opts      RecruitOptions
+	player    *sii.Block
+	econ      *sii.Block
+	pool      []string // drivers of economy.driver_pool, in pool order
+	names     []string // unused IDs of opts.Names, in table order
+	hometowns []string
+	mirror    map[string]string // player skills, for SkillsMirrorPlayer
+}
+
+func newRecruiter(doc *sii.Document, opts RecruitOptions) (*recruiter, error) {
+	r := &recruiter{doc: doc, opts: opts}
+	r.player = doc.FirstBlockByType("player")
+	if r.player == nil {
+		return nil, fmt.Errorf("player block not found")
+	}
+	r.econ = doc.FirstBlockByType("economy")
+	if r.econ == nil {
+		return nil, fmt.Errorf("economy block not found")
+	}
+	for _, name := range sii.GetArray(r.econ.Properties, "driver_pool") {
+		if b := doc.Block(name); b != nil && b.Type == "driver_ai" {
+			r.pool = append(r.pool, name)
+		}
+	}
+
+	for id := range opts.Names {
+		if doc.Block(id) == nil {
+			r.names = append(r.names, id)
+		}
+	}
+	sort.Slice(r.names, func(i, j int) bool { return driverNumber(r.names[i]) < driverNumber(r.names[j]) })
+
+	for _, block := range doc.BlocksByType("garage") {
+		status, _ := strconv.Atoi(firstProp(block.Properties, "status"))
+		if status == items.GarageNotOwned {
+			continue
+		}
+		if city, ok := strings.CutPrefix(block.Name, "garage."); ok {
+			r.hometowns = append(r.hometowns, city)
+		}
+	}
+	if len(r.hometowns) == 0 {
+		if hq := firstProp(r.player.Properties, "hq_city"); hq != "" && hq != `""` {
+			r.hometowns = append(r.hometowns, hq)
+		}
+	}
+
+	if opts.Skills.Mode == SkillsMirrorPlayer {
+		r.mirror = make(map[string]string)
+		for _, key := range append(driverSkillKeys, "experience_points") {
+			r.mirror[key] = firstProp(r.econ.Properties, key)
+		}
+	}
+	return r, nil
+}
+
+// driverNumber returns N of "driver.N", or -1.
+func driverNumber(name string) int {
+	rest, ok := strings.CutPrefix(name, "driver.")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// nextName returns the name of the next driver.
+func (r *recruiter) nextName() string {
+	for len(r.names) > 0 {
+		name := r.names[0]
+		r.names = r.names[1:]
+		if r.doc.Block(name) == nil {
+			return name
+		}
+	}
+	return r.doc.NewNumberedName("driver")
+}
+
+// hometown returns a random city of a garage of the player, or fallback.
+func (r *recruiter) hometown(fallback string) string {
+	if len(r.hometowns) == 0 {
+		return fallback
+	}
+	return r.hometowns[rand.Intn(len(r.hometowns))]
+}
+
+// skills returns the skill levels (ADR as a mask) and the experience of a
+// new driver.
+func (r *recruiter) skills() (map[string]int, int) {
+	s := r.opts.Skills
+	levels := make(map[string]int, len(driverSkillKeys))
+	xp := s.XP
+	switch s.Mode {
+	case SkillsMirrorPlayer:
+		for _, key := range driverSkillKeys {
+			levels[key], _ = strconv.Atoi(r.mirror[key])
+		}
+		xp, _ = strconv.Atoi(r.mirror["experience_points"])
+		return levels, xp
+	case SkillsRandom:
+		for _, key := range driverSkillKeys {
+			levels[key] = randomBetween(s.Level, s.MaxLevel)
+		}
+		xp = randomBetween(s.XP, s.MaxXP)
+	default:
+		for _, key := range driverSkillKeys {
+			levels[key] = s.Level
+		}
+	}
+	for key, level := range levels {
+		levels[key] = min(max(level, 0), MaxSkillLevel)
+	}
+	levels["adr"] = 1<<levels["adr"] - 1
+	return levels, max(xp, 0)
+}
+
+func randomBetween(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rand.Intn(hi-lo+1)
+}
+
+// recruit hires a driver for truck, parked in the garage of city: the next
+// driver of the pool, or a new one. The driver is added to player.drivers
+// with its job and profit log. It returns the name of the driver.
+func (r *recruiter) recruit(truck, city string) (string, error) {
+	var name string
+	var job, profitLog *sii.Block
+	if len(r.pool) > 0 {
+		name, r.pool = r.pool[0], r.pool[1:]
+		var err error
+		if job, profitLog, err = r.takeFromPool(name); err != nil {
+			return "", err
+		}
+	} else {
+		name = r.nextName()
+	}
+	levels, xp := r.skills()
+	driver := createDriverAIBlock(name, truck, r.hometown(city), city, levels, xp)
+	if job == nil {
+		job = newJobInfoBlock(r.doc.NewNameless())
+	}
+	if profitLog == nil {
+		profitLog = newProfitLogBlock(r.doc.NewNameless())
+	}
+
+	if err := r.doc.AddOwned(items.Pointers, r.player.Name, "drivers", driver); err != nil {
+		return "", fmt.Errorf("add driver %s: %w", name, err)
+	}
+	if err := r.doc.AddOwned(items.Pointers, name, "driver_job", job); err != nil {
+		return "", fmt.Errorf("add job of driver %s: %w", name, err)
+	}
+	if err := r.doc.AddOwned(items.Pointers, name, "profit_log", profitLog); err != nil {
+		return "", fmt.Errorf("add profit log of driver %s: %w", name, err)
+	}
+
+	// The player keeps per-driver arrays parallel to drivers
+	for _, key := range []string{"driver_flags", "driver_readiness_timer", "driver_undrivable_truck_timers"} {
+		if _, ok := r.player.Properties[key]; ok {
+			appendArray(r.player.Properties, key, sii.Int(0))
+		}
+	}
+	return name, nil
+}
+
+// takeFromPool removes the named driver from economy.driver_pool and from
+// the document, along with its job and profit log. These are returned (nil
+// when the driver has none) to be added again under the hired driver, which
+// takes the skills chosen for recruits.
+func (r *recruiter) takeFromPool(name string) (job, profitLog *sii.Block, err error) {
+	driver := r.doc.Block(name)
+	if driver == nil {
+		return nil, nil, fmt.Errorf("pool driver %s not found", name)
+	}
+	job = r.doc.Block(firstProp(driver.Properties, "driver_job"))
+	profitLog = r.doc.Block(firstProp(driver.Properties, "profit_log"))
+
+	var pool sii.Array
+	for _, v := range sii.GetArray(r.econ.Properties, "driver_pool") {
+		if v != name {
+			pool = append(pool, sii.OwnerPointer(v))
+		}
+	}
+	sii.PutArray(r.econ.Properties, "driver_pool", pool)
+
+	names := []string{name}
+	for _, b := range []*sii.Block{job, profitLog} {
+		if b != nil {
+			names = append(names, b.Name)
+		}
+	}
+	r.doc.Remove(names...)
+	return job, profitLog, nil
+}
+
+// appendArray appends v to the array key of props, keeping the existing
+// values as they are written.
+func appendArray(props map[string][]string, key string, v sii.Value) {
+	var values sii.Array
+	for _, s := range sii.GetArray(props, key) {
+		values = append(values, sii.Token(s))
+	}
+	sii.PutArray(props, key, append(values, v))
+}
+
+// firstProp returns the first value of key, or "".
+func firstProp(props map[string][]string, key string) string {
+	if vals := props[key]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// newJobInfoBlock returns the job_info of a driver without a job.
+func newJobInfoBlock(name string) *sii.Block {
+	job := items.JobInfo{
+		Cargo:         sii.Null,
+		SourceCompany: sii.Null,
+		TargetCompany: sii.Null,
+		Special:       sii.Null,
+		FillRatio:     1,
+	}
+	return &sii.Block{
+		Type:          "job_info",
+		Name:          name,
+		Properties:    job.ToProperties(),
+		PropertyOrder: append([]string(nil), jobInfoOrder...),
+	}
+}
@@ -0,0 +1,117 @@
+package save
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// recruitDoc is a save with two trucks without drivers in paris and one
+// driver in the pool of the economy.
+const recruitDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ driver_pool: 1
+ driver_pool[0]: driver.5
+ adr: 1
+ long_dist: 0
+ heavy: 0
+ fragile: 0
+ urgent: 0
+ mechanical: 0
+ experience_points: 0
+}
+player : _nameless.2 {
+ hq_city: paris
+ trucks: 2
+ trucks[0]: _nameless.10
+ trucks[1]: _nameless.11
+ drivers: 0
+ driver_flags: 0
+ driver_readiness_timer: 0
+ driver_undrivable_truck_timers: 0
+}
+vehicle : _nameless.10 {
+}
+vehicle : _nameless.11 {
+}
+garage : garage.paris {
+ vehicles: 2
+ vehicles[0]: _nameless.10
+ vehicles[1]: _nameless.11
+ drivers: 2
+ drivers[0]: null
+ drivers[1]: null
+ trailers: 0
+ status: 2
+ profit_log: _nameless.20
+ productivity: 0
+}
+profit_log : _nameless.20 {
+}
+driver_ai : driver.5 {
+ adr: 63
+ hometown: ""
+ current_city: ""
+ state: 1
+ driver_job: _nameless.30
+ experience_points: 149568
+ profit_log: _nameless.31
+}
+job_info : _nameless.30 {
+ cargo: null
+}
+profit_log : _nameless.31 {
+}
+}
+`
+
+func TestRecruitEmployeesAndPopulateTrucks(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(recruitDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	opts := RecruitOptions{
+		Names:  map[string]string{"driver.5": "Pooled", "driver.7": "Anna"},
+		Skills: DriverSkills{Mode: SkillsFixed, Level: 3, XP: 1000},
+	}
+	if err := RecruitEmployeesAndPopulateTrucks(doc, nil, opts); err != nil {
+		t.Fatalf("RecruitEmployeesAndPopulateTrucks: %v", err)
+	}
+
+	if g := loadGarage(t, doc, "paris"); !slices.Equal(g.Drivers, []string{"driver.5", "driver.7"}) {
+		t.Errorf("garage drivers = %v, want the pool driver then a named one", g.Drivers)
+	}
+	if pool := sii.GetArray(doc.FirstBlockByType("economy").Properties, "driver_pool"); len(pool) != 0 {
+		t.Errorf("driver_pool = %v, want the hired driver removed", pool)
+	}
+	player := doc.FirstBlockByType("player").Properties
+	if drivers := sii.GetArray(player, "drivers"); !slices.Equal(drivers, []string{"driver.5", "driver.7"}) {
+		t.Errorf("player.drivers = %v", drivers)
+	}
+	if flags := sii.GetArray(player, "driver_flags"); len(flags) != 2 {
+		t.Errorf("driver_flags = %v, want one per driver", flags)
+	}
+
+	pooled := doc.Block("driver.5").Properties
+	for key, want := range map[string]string{
+		"driver_job":        "_nameless.30",
+		"profit_log":        "_nameless.31",
+		"adr":               "7",
+		"experience_points": "1000",
+		"assigned_truck":    "_nameless.10",
+		"current_city":      "paris",
+	} {
+		if got := firstProp(pooled, key); got != want {
+			t.Errorf("driver.5 %s = %s, want %s", key, got, want)
+		}
+	}
+	if owned := doc.Owned(items.Pointers, "_nameless.2"); !slices.Contains(owned, "_nameless.30") {
+		t.Errorf("the job of the pool driver is not owned by the player: %v", owned)
+	}
+	if refs := doc.DanglingReferences(items.Pointers); len(refs) != 0 {
+		t.Errorf("dangling references: %v", refs)
+	}
+}
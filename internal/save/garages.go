@@ -0,0 +1,404 @@
+package save
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// GaragePrices holds what a garage is worth at each size. Changing the
+// status of a garage costs the difference, so selling or downgrading refunds
+// it.
+type GaragePrices struct {
+	Tiny, Small, Large int64
+}
+
+// price returns what a garage with the given status is worth.
+func (p *GaragePrices) price(status int) int64 {
+	switch status {
+	case items.GarageTiny:
+		return p.Tiny
+	case items.GarageSmall:
+		return p.Small
+	case items.GarageLarge:
+		return p.Large
+	}
+	return 0
+}
+
+// GarageOptions configures the garage operations.
+type GarageOptions struct {
+	// Prices, when set, makes the bank pay for the change (or receive the
+	// refund). The operation fails if the account cannot afford it.
+	Prices *GaragePrices
+}
+
+// BuyGarage buys the garage of city as a tiny garage. The garage unit is
+// created when the save has none for that city.
+func BuyGarage(doc *sii.Document, city string, opts GarageOptions) error {
+	if status := garageStatus(doc, city); status != items.GarageNotOwned {
+		return fmt.Errorf("garage %s is already owned", city)
+	}
+	return SetGarageStatus(doc, city, items.GarageTiny, opts)
+}
+
+// BuyAndUpgradeGarage buys the garage of city straight as a large garage.
+func BuyAndUpgradeGarage(doc *sii.Document, city string, opts GarageOptions) error {
+	if status := garageStatus(doc, city); status != items.GarageNotOwned {
+		return fmt.Errorf("garage %s is already owned", city)
+	}
+	return SetGarageStatus(doc, city, items.GarageLarge, opts)
+}
+
+// SellGarage sells the garage of city. Its trucks and drivers move to free
+// slots of the other garages.
+func SellGarage(doc *sii.Document, city string, opts GarageOptions) error {
+	if status := garageStatus(doc, city); status == items.GarageNotOwned {
+		return fmt.Errorf("garage %s is not owned", city)
+	}
+	return SetGarageStatus(doc, city, items.GarageNotOwned, opts)
+}
+
+// UpgradeGarage makes the garage of city one size larger.
+func UpgradeGarage(doc *sii.Document, city string, opts GarageOptions) error {
+	status := garageStatus(doc, city)
+	size := garageSize(status)
+	switch {
+	case status == items.GarageNotOwned:
+		return fmt.Errorf("garage %s is not owned", city)
+	case size < 0:
+		return fmt.Errorf("garage %s has an unknown status %d", city, status)
+	case status == items.GarageLarge:
+		return fmt.Errorf("garage %s is already fully upgraded", city)
+	}
+	return SetGarageStatus(doc, city, items.GarageSizes[size+1], opts)
+}
+
+// DowngradeGarage makes the garage of city one size smaller. A tiny garage
+// cannot be downgraded: it has to be sold. Trucks and drivers that no longer
+// fit move to free slots of the other garages.
+func DowngradeGarage(doc *sii.Document, city string, opts GarageOptions) error {
+	status := garageStatus(doc, city)
+	size := garageSize(status)
+	switch {
+	case status == items.GarageNotOwned:
+		return fmt.Errorf("garage %s is not owned", city)
+	case size < 0:
+		return fmt.Errorf("garage %s has an unknown status %d", city, status)
+	case status == items.GarageTiny:
+		return fmt.Errorf("garage %s is already tiny", city)
+	}
+	return SetGarageStatus(doc, city, items.GarageSizes[size-1], opts)
+}
+
+// garageSize returns the index of status in items.GarageSizes, or -1 when
+// status is not the one of an owned garage.
+func garageSize(status int) int {
+	for i, s := range items.GarageSizes {
+		if s == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetHQCity moves the headquarters of the player to city, which must have an
+// owned garage listed in economy.garages. With transfer, the game moves the
+// player and their truck to the new headquarters when the save is loaded.
+func SetHQCity(doc *sii.Document, city string, transfer bool) error {
+	player := doc.FirstBlockByType("player")
+	if player == nil {
+		return fmt.Errorf("player block not found")
+	}
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+
+	name := "garage." + city
+	listed := false
+	for _, g := range sii.GetArray(econBlock.Properties, "garages") {
+		if g == name {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		return fmt.Errorf("garage %s is not in the economy", city)
+	}
+	if garageStatus(doc, city) == items.GarageNotOwned {
+		return fmt.Errorf("garage %s is not owned", city)
+	}
+
+	player.Properties["hq_city"] = []string{city}
+	player.Properties["schedule_transfer_to_hq"] = []string{sii.Bool(transfer).Format()}
+	return nil
+}
+
+// garageStatus returns the status of the garage of city, or
+// items.GarageNotOwned when the save has no such garage.
+func garageStatus(doc *sii.Document, city string) int {
+	block := doc.Block("garage." + city)
+	if block == nil {
+		return items.GarageNotOwned
+	}
+	var garage items.Garage
+	if err := garage.FromProperties(block.Properties); err != nil {
+		return items.GarageNotOwned
+	}
+	return garage.Status
+}
+
+// evicted is a garage slot that no longer fits in its garage.
+type evicted struct {
+	truck, driver string
+}
+
+// ownedGarage is a garage unit loaded with its slots padded to its size.
+type ownedGarage struct {
+	block  *sii.Block
+	garage *items.Garage
+}
+
+// garageSlot is a free slot of another garage.
+type garageSlot struct {
+	*ownedGarage
+	index int
+}
+
+// SetGarageStatus sets the status of the garage of city and resizes its
+// vehicle, driver and trailer slots to match. Trucks and drivers of the slots
+// that are removed move, as pairs, to the empty slots left in the garage,
+// then to empty slots of the other owned garages; trailers that no longer
+// fit move to the other owned garages. The change is refused when there are
+// not enough free slots, or when it would sell the headquarters.
+func SetGarageStatus(doc *sii.Document, city string, status int, opts GarageOptions) error {
+	if status != items.GarageNotOwned && garageSize(status) < 0 {
+		return fmt.Errorf("invalid garage status %d", status)
+	}
+	name := "garage." + city
+
+	block := doc.Block(name)
+	var garage items.Garage
+	if block != nil {
+		if err := garage.FromProperties(block.Properties); err != nil {
+			return fmt.Errorf("load %s: %w", name, err)
+		}
+	}
+	from := garage.Status
+	if from == status {
+		return nil
+	}
+
+	if status == items.GarageNotOwned {
+		if player := doc.FirstBlockByType("player"); player != nil && firstProp(player.Properties, "hq_city") == city {
+			return fmt.Errorf("garage %s is the headquarters and cannot be sold", city)
+		}
+	}
+
+	// Slots that do not fit anymore, and where their content goes: first the
+	// free slots that are kept, then the other garages
+	slots := items.GarageSlots(status)
+	var moving []evicted
+	for i := slots; i < len(garage.Vehicles) || i < len(garage.Drivers); i++ {
+		e := evicted{truck: slotAt(garage.Vehicles, i), driver: slotAt(garage.Drivers, i)}
+		if e.truck != "" || e.driver != "" {
+			moving = append(moving, e)
+		}
+	}
+	garage.Vehicles = resizeSlots(garage.Vehicles, max(slots, len(garage.Vehicles)))
+	garage.Drivers = resizeSlots(garage.Drivers, max(slots, len(garage.Drivers)))
+	var packed []int
+	for i := 0; i < slots && len(packed) < len(moving); i++ {
+		if slotAt(garage.Vehicles, i) == "" && slotAt(garage.Drivers, i) == "" {
+			packed = append(packed, i)
+		}
+	}
+	others := otherGarages(doc, name)
+	targets := freeGarageSlots(others, len(moving)-len(packed))
+	if len(packed)+len(targets) < len(moving) {
+		return fmt.Errorf("garage %s: no free slot in other garages for %d trucks or drivers", city, len(moving)-len(packed)-len(targets))
+	}
+	var movingTrailers []string
+	if len(garage.Trailers) > slots {
+		movingTrailers = garage.Trailers[slots:]
+	}
+	trailerTargets := freeTrailerSlots(others, len(movingTrailers))
+	if len(trailerTargets) < len(movingTrailers) {
+		return fmt.Errorf("garage %s: no free slot in other garages for %d trailers", city, len(movingTrailers)-len(trailerTargets))
+	}
+
+	var bankBlock *sii.Block
+	var bank items.Bank
+	var cost int64
+	if opts.Prices != nil {
+		bankBlock = doc.FirstBlockByType("bank")
+		if bankBlock == nil {
+			return fmt.Errorf("bank block not found")
+		}
+		if err := bank.FromProperties(bankBlock.Properties); err != nil {
+			return fmt.Errorf("load bank: %w", err)
+		}
+		cost = opts.Prices.price(status) - opts.Prices.price(from)
+		if cost > 0 && bank.MoneyAccount < cost {
+			return fmt.Errorf("garage %s: not enough money (%d needed, %d available)", city, cost, bank.MoneyAccount)
+		}
+	}
+
+	if block == nil {
+		var err error
+		if block, err = addGarageBlock(doc, name); err != nil {
+			return err
+		}
+		garage.ProfitLog = firstProp(block.Properties, "profit_log")
+	}
+	if err := ensureEconomyGarage(doc, name); err != nil {
+		return err
+	}
+	if garage.ProfitLog == "" || garage.ProfitLog == sii.Null {
+		garage.ProfitLog = doc.NewNameless()
+		if err := doc.AddOwned(items.Pointers, name, "profit_log", newProfitLogBlock(garage.ProfitLog)); err != nil {
+			return fmt.Errorf("add profit log of %s: %w", name, err)
+		}
+	}
+
+	for i, e := range moving[:len(packed)] {
+		garage.Vehicles[packed[i]] = e.truck
+		garage.Drivers[packed[i]] = e.driver
+	}
+	for i, e := range moving[len(packed):] {
+		t := targets[i]
+		t.garage.Vehicles[t.index] = e.truck
+		t.garage.Drivers[t.index] = e.driver
+		if d := doc.Block(e.driver); d != nil && d.Type == "driver_ai" {
+			d.Properties["current_city"] = []string{strings.TrimPrefix(t.block.Name, "garage.")}
+		}
+	}
+	for i, trailer := range movingTrailers {
+		t := trailerTargets[i]
+		t.garage.Trailers = append(t.garage.Trailers, trailer)
+	}
+	for _, t := range append(targets, trailerTargets...) {
+		updateBlockProperties(t.block, t.garage.ToProperties())
+	}
+
+	garage.Vehicles = resizeSlots(garage.Vehicles, slots)
+	garage.Drivers = resizeSlots(garage.Drivers, slots)
+	if len(garage.Trailers) > slots {
+		garage.Trailers = garage.Trailers[:slots]
+	}
+	garage.Status = status
+	updateBlockProperties(block, garage.ToProperties())
+
+	if bankBlock != nil {
+		bank.MoneyAccount -= cost
+		updateBlockProperties(bankBlock, bank.ToProperties())
+	}
+	return nil
+}
+
+// slotAt returns the content of slot i, or "" when it is empty or missing.
+func slotAt(slots []string, i int) string {
+	if i < len(slots) && slots[i] != sii.Null {
+		return slots[i]
+	}
+	return ""
+}
+
+// resizeSlots returns slots cut or padded with empty slots to n.
+func resizeSlots(slots []string, n int) []string {
+	for len(slots) < n {
+		slots = append(slots, "")
+	}
+	return slots[:n]
+}
+
+// otherGarages loads the owned garages other than skip, in document order.
+func otherGarages(doc *sii.Document, skip string) []*ownedGarage {
+	var out []*ownedGarage
+	for _, block := range doc.BlocksByType("garage") {
+		if block.Name == skip {
+			continue
+		}
+		garage := &items.Garage{}
+		if err := garage.FromProperties(block.Properties); err != nil {
+			continue
+		}
+		slots := items.GarageSlots(garage.Status)
+		if slots == 0 {
+			continue
+		}
+		garage.Vehicles = resizeSlots(garage.Vehicles, max(slots, len(garage.Vehicles)))
+		garage.Drivers = resizeSlots(garage.Drivers, max(slots, len(garage.Drivers)))
+		out = append(out, &ownedGarage{block: block, garage: garage})
+	}
+	return out
+}
+
+// freeGarageSlots returns up to n slots without truck nor driver in
+// garages.
+func freeGarageSlots(garages []*ownedGarage, n int) []garageSlot {
+	var out []garageSlot
+	for _, g := range garages {
+		slots := items.GarageSlots(g.garage.Status)
+		for i := 0; i < slots && len(out) < n; i++ {
+			if slotAt(g.garage.Vehicles, i) == "" && slotAt(g.garage.Drivers, i) == "" {
+				out = append(out, garageSlot{ownedGarage: g, index: i})
+			}
+		}
+	}
+	return out
+}
+
+// freeTrailerSlots returns up to n places for a trailer in garages, which
+// park as many trailers as they have truck slots.
+func freeTrailerSlots(garages []*ownedGarage, n int) []garageSlot {
+	var out []garageSlot
+	for _, g := range garages {
+		slots := items.GarageSlots(g.garage.Status)
+		for i := len(g.garage.Trailers); i < slots && len(out) < n; i++ {
+			out = append(out, garageSlot{ownedGarage: g, index: i})
+		}
+	}
+	return out
+}
+
+// addGarageBlock creates the unit of a garage that is not owned, with its
+// profit log, and lists it in economy.garages.
+func addGarageBlock(doc *sii.Document, name string) (*sii.Block, error) {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return nil, fmt.Errorf("economy block not found")
+	}
+	garage := items.Garage{ProfitLog: sii.Null}
+	block := &sii.Block{
+		Type:          "garage",
+		Name:          name,
+		Properties:    garage.ToProperties(),
+		PropertyOrder: []string{"vehicles", "drivers", "trailers", "status", "profit_log", "productivity"},
+	}
+	if err := doc.AddOwned(items.Pointers, econBlock.Name, "garages", block); err != nil {
+		return nil, fmt.Errorf("add %s: %w", name, err)
+	}
+	if err := doc.AddOwned(items.Pointers, name, "profit_log", newProfitLogBlock(doc.NewNameless())); err != nil {
+		return nil, fmt.Errorf("add profit log of %s: %w", name, err)
+	}
+	return block, nil
+}
+
+// ensureEconomyGarage lists the garage in economy.garages if it is missing.
+func ensureEconomyGarage(doc *sii.Document, name string) error {
+	econBlock := doc.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+	for _, g := range sii.GetArray(econBlock.Properties, "garages") {
+		if g == name {
+			return nil
+		}
+	}
+	appendArray(econBlock.Properties, "garages", sii.Token(name))
+	return nil
+}
@@ -0,0 +1,291 @@
+package save
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// garageDoc is a save with a large headquarters in paris, a small garage in
+// lyon and an empty large garage in berlin.
+const garageDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ garages: 3
+ garages[0]: garage.paris
+ garages[1]: garage.lyon
+ garages[2]: garage.berlin
+}
+bank : _nameless.2 {
+ money_account: 1000
+}
+player : _nameless.3 {
+ hq_city: paris
+}
+garage : garage.paris {
+ vehicles: 5
+ vehicles[0]: _nameless.10
+ vehicles[1]: null
+ vehicles[2]: null
+ vehicles[3]: null
+ vehicles[4]: null
+ drivers: 5
+ drivers[0]: driver.player
+ drivers[1]: null
+ drivers[2]: null
+ drivers[3]: null
+ drivers[4]: null
+ trailers: 0
+ status: 3
+ profit_log: _nameless.20
+ productivity: 0
+}
+profit_log : _nameless.20 {
+}
+garage : garage.lyon {
+ vehicles: 3
+ vehicles[0]: null
+ vehicles[1]: _nameless.11
+ vehicles[2]: _nameless.12
+ drivers: 3
+ drivers[0]: null
+ drivers[1]: driver.anna
+ drivers[2]: driver.bob
+ trailers: 3
+ trailers[0]: _nameless.30
+ trailers[1]: _nameless.31
+ trailers[2]: _nameless.32
+ status: 2
+ profit_log: _nameless.21
+ productivity: 0
+}
+profit_log : _nameless.21 {
+}
+garage : garage.berlin {
+ vehicles: 5
+ vehicles[0]: null
+ vehicles[1]: null
+ vehicles[2]: null
+ vehicles[3]: null
+ vehicles[4]: null
+ drivers: 5
+ drivers[0]: null
+ drivers[1]: null
+ drivers[2]: null
+ drivers[3]: null
+ drivers[4]: null
+ trailers: 0
+ status: 3
+ profit_log: _nameless.22
+ productivity: 0
+}
+profit_log : _nameless.22 {
+}
+driver_ai : driver.anna {
+ current_city: lyon
+}
+driver_ai : driver.bob {
+ current_city: lyon
+}
+}
+`
+
+func loadGarage(t *testing.T, doc *sii.Document, city string) items.Garage {
+	t.Helper()
+	var garage items.Garage
+	block := doc.Block("garage." + city)
+	if block == nil {
+		t.Fatalf("garage.%s not found", city)
+	}
+	if err := garage.FromProperties(block.Properties); err != nil {
+		t.Fatalf("load garage.%s: %v", city, err)
+	}
+	return garage
+}
+
+func TestSetGarageStatus(t *testing.T) {
+	prices := &GaragePrices{Tiny: 100, Small: 300, Large: 700}
+
+	tests := []struct {
+		name    string
+		edit    func(doc *sii.Document)
+		city    string
+		status  int
+		opts    GarageOptions
+		wantErr string
+		check   func(t *testing.T, doc *sii.Document)
+	}{
+		{
+			name:   "upgrade",
+			city:   "lyon",
+			status: items.GarageLarge,
+			check: func(t *testing.T, doc *sii.Document) {
+				g := loadGarage(t, doc, "lyon")
+				if len(g.Vehicles) != 5 || len(g.Drivers) != 5 {
+					t.Errorf("got %d vehicles and %d drivers, want 5", len(g.Vehicles), len(g.Drivers))
+				}
+				if g.Vehicles[1] != "_nameless.11" || g.Drivers[2] != "driver.bob" {
+					t.Errorf("slots moved: %v %v", g.Vehicles, g.Drivers)
+				}
+			},
+		},
+		{
+			name:   "downgrade packs into free slots",
+			city:   "lyon",
+			status: items.GarageTiny,
+			edit: func(doc *sii.Document) {
+				// Only bob is left, in the last slot
+				b := doc.Block("garage.lyon")
+				b.Properties["vehicles[1]"] = []string{"null"}
+				b.Properties["drivers[1]"] = []string{"null"}
+			},
+			check: func(t *testing.T, doc *sii.Document) {
+				g := loadGarage(t, doc, "lyon")
+				if !slices.Equal(g.Vehicles, []string{"_nameless.12"}) || !slices.Equal(g.Drivers, []string{"driver.bob"}) {
+					t.Errorf("lyon = %v %v, want bob in slot 0", g.Vehicles, g.Drivers)
+				}
+				if !slices.Equal(g.Trailers, []string{"_nameless.30"}) {
+					t.Errorf("lyon trailers = %v, want [_nameless.30]", g.Trailers)
+				}
+				if city := firstProp(doc.Block("driver.bob").Properties, "current_city"); city != "lyon" {
+					t.Errorf("bob is in %s, want lyon", city)
+				}
+				paris := loadGarage(t, doc, "paris")
+				if !slices.Equal(paris.Trailers, []string{"_nameless.31", "_nameless.32"}) {
+					t.Errorf("paris trailers = %v", paris.Trailers)
+				}
+			},
+		},
+		{
+			name:   "downgrade moves to other garages",
+			city:   "lyon",
+			status: items.GarageTiny,
+			check: func(t *testing.T, doc *sii.Document) {
+				g := loadGarage(t, doc, "lyon")
+				if !slices.Equal(g.Vehicles, []string{"_nameless.11"}) || !slices.Equal(g.Drivers, []string{"driver.anna"}) {
+					t.Errorf("lyon = %v %v, want anna in slot 0", g.Vehicles, g.Drivers)
+				}
+				paris := loadGarage(t, doc, "paris")
+				if paris.Vehicles[1] != "_nameless.12" || paris.Drivers[1] != "driver.bob" {
+					t.Errorf("paris = %v %v, want bob in slot 1", paris.Vehicles, paris.Drivers)
+				}
+				if city := firstProp(doc.Block("driver.bob").Properties, "current_city"); city != "paris" {
+					t.Errorf("bob is in %s, want paris", city)
+				}
+			},
+		},
+		{
+			name:   "downgrade without free slots",
+			city:   "lyon",
+			status: items.GarageTiny,
+			edit: func(doc *sii.Document) {
+				doc.Block("garage.berlin").Properties["status"] = []string{"0"}
+				doc.Block("garage.paris").Properties["status"] = []string{"6"}
+			},
+			wantErr: "no free slot",
+		},
+		{
+			name:    "sell headquarters",
+			city:    "paris",
+			status:  items.GarageNotOwned,
+			wantErr: "headquarters",
+		},
+		{
+			name:   "sell charges the refund",
+			city:   "berlin",
+			status: items.GarageNotOwned,
+			opts:   GarageOptions{Prices: prices},
+			check: func(t *testing.T, doc *sii.Document) {
+				if money := firstProp(doc.FirstBlockByType("bank").Properties, "money_account"); money != "1700" {
+					t.Errorf("money_account = %s, want 1700", money)
+				}
+				if g := loadGarage(t, doc, "berlin"); len(g.Vehicles) != 0 || g.Status != items.GarageNotOwned {
+					t.Errorf("berlin = %+v, want not owned and empty", g)
+				}
+			},
+		},
+		{
+			name:   "buy charges the price",
+			city:   "madrid",
+			status: items.GarageTiny,
+			opts:   GarageOptions{Prices: prices},
+			check: func(t *testing.T, doc *sii.Document) {
+				if money := firstProp(doc.FirstBlockByType("bank").Properties, "money_account"); money != "900" {
+					t.Errorf("money_account = %s, want 900", money)
+				}
+				g := loadGarage(t, doc, "madrid")
+				if g.Status != items.GarageTiny || len(g.Vehicles) != 1 {
+					t.Errorf("madrid = %+v, want a tiny garage with 1 slot", g)
+				}
+				if !slices.Contains(sii.GetArray(doc.FirstBlockByType("economy").Properties, "garages"), "garage.madrid") {
+					t.Errorf("garage.madrid is not listed in economy.garages")
+				}
+			},
+		},
+		{
+			name:    "not enough money",
+			city:    "lyon",
+			status:  items.GarageLarge,
+			opts:    GarageOptions{Prices: &GaragePrices{Small: 300, Large: 2000}},
+			wantErr: "not enough money",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := sii.ReadDocument([]byte(garageDoc))
+			if err != nil {
+				t.Fatalf("ReadDocument: %v", err)
+			}
+			if tt.edit != nil {
+				tt.edit(doc)
+			}
+			err = SetGarageStatus(doc, tt.city, tt.status, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SetGarageStatus = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetGarageStatus: %v", err)
+			}
+			tt.check(t, doc)
+		})
+	}
+}
+
+func TestUpgradeDowngradeGarage(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(garageDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if err := BuyGarage(doc, "madrid", GarageOptions{}); err != nil {
+		t.Fatalf("BuyGarage: %v", err)
+	}
+	for _, want := range []int{items.GarageSmall, items.GarageLarge} {
+		if err := UpgradeGarage(doc, "madrid", GarageOptions{}); err != nil {
+			t.Fatalf("UpgradeGarage: %v", err)
+		}
+		if got := garageStatus(doc, "madrid"); got != want {
+			t.Errorf("status = %d, want %d", got, want)
+		}
+	}
+	if err := UpgradeGarage(doc, "madrid", GarageOptions{}); err == nil {
+		t.Errorf("UpgradeGarage accepted a large garage")
+	}
+	for _, want := range []int{items.GarageSmall, items.GarageTiny} {
+		if err := DowngradeGarage(doc, "madrid", GarageOptions{}); err != nil {
+			t.Fatalf("DowngradeGarage: %v", err)
+		}
+		if got := garageStatus(doc, "madrid"); got != want {
+			t.Errorf("status = %d, want %d", got, want)
+		}
+	}
+	if err := DowngradeGarage(doc, "madrid", GarageOptions{}); err == nil {
+		t.Errorf("DowngradeGarage accepted a tiny garage")
+	}
+}
@@ -0,0 +1,62 @@
+package save
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// SyncInfo recomputes the info_* summary fields of info.sii, shown in the
+// save list of the game, from game.sii: money, experience, visited cities,
+// unlocked dealers and recruitment agencies. The explored ratio needs map
+// data and is only reset when the map exploration was cleared. With
+// touchFileTime, file_time is set to now, so that the save sorts as the
+// latest one.
+//
+// Fields missing from info.sii are not added, as older info versions do not
+// have all of them. It does nothing when the save has no info.sii.
+func SyncInfo(docs *Documents, touchFileTime bool) error {
+	if docs.Info == nil || docs.Game == nil {
+		return nil
+	}
+	container := docs.Info.FirstBlockByType("save_container")
+	if container == nil {
+		return fmt.Errorf("save_container block not found")
+	}
+	econBlock := docs.Game.FirstBlockByType("economy")
+	if econBlock == nil {
+		return fmt.Errorf("economy block not found")
+	}
+	bankBlock := docs.Game.FirstBlockByType("bank")
+	if bankBlock == nil {
+		return fmt.Errorf("bank block not found")
+	}
+
+	count := func(key string) string {
+		return strconv.Itoa(len(sii.GetArray(econBlock.Properties, key)))
+	}
+	values := map[string]string{
+		"info_players_experience":    firstProp(econBlock.Properties, "experience_points"),
+		"info_unlocked_recruitments": count("unlocked_recruitments"),
+		"info_unlocked_dealers":      count("unlocked_dealers"),
+		"info_money_account":         firstProp(bankBlock.Properties, "money_account"),
+	}
+	if len(sii.GetArray(econBlock.Properties, "discovered_items")) == 0 {
+		values["info_explored_ratio"] = "0"
+	}
+	if touchFileTime {
+		values["file_time"] = strconv.FormatInt(time.Now().Unix(), 10)
+	}
+
+	for key, v := range values {
+		if _, ok := container.Properties[key]; ok && v != "" {
+			container.Properties[key] = []string{v}
+		}
+	}
+	if _, ok := container.Properties["info_visited_cities"]; ok {
+		return SyncInfoVisitedCities(docs.Info, docs.Game)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Bank mirrors the C# Bank class from CustomClasses/Save/Items/Bank.cs.
@@ -27,7 +28,8 @@ type Bank struct {
 
 // FromProperties populates the Bank from SII properties.
 func (b *Bank) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
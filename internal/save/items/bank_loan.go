@@ -1,6 +1,9 @@
 package items
 
-import "github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+import (
+	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
 
 // BankLoan mirrors the C# Bank_Loan class and represents a single bank_loan block.
 type BankLoan struct {
@@ -11,14 +14,38 @@ type BankLoan struct {
 	Duration       int
 }
 
-// FromLines constructs a BankLoan from the lines of a bank_loan SII block.
-// It is a partial port of the C# string[] constructor; full integration with
-// a generic parser will come later.
-func (b *BankLoan) FromLines(lines []string) {
-	for _, line := range lines {
-		// TODO: delegate to a shared SII line parser; for now this is just a stub.
-		_ = line
+// FromProperties populates the BankLoan from the properties of a bank_loan
+// block. Unparsable values are left at zero.
+func (b *BankLoan) FromProperties(props map[string][]string) {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
+		if len(vals) == 0 {
+			continue
+		}
+		val := vals[0]
+
+		switch key {
+		case "amount":
+			b.Amount = parseInt(val)
+		case "original_amount":
+			b.OriginalAmount = parseInt(val)
+		case "time_stamp":
+			b.TimeStamp = parseInt(val)
+		case "interest_rate":
+			b.InterestRate = parseFloat(val)
+		case "duration":
+			b.Duration = parseInt(val)
+		}
 	}
 }
 
-
+// ToProperties converts the BankLoan to the properties of a bank_loan block.
+func (b *BankLoan) ToProperties() map[string][]string {
+	return map[string][]string{
+		"amount":          {sii.Int(b.Amount).Format()},
+		"original_amount": {sii.Int(b.OriginalAmount).Format()},
+		"time_stamp":      {sii.Int(b.TimeStamp).Format()},
+		"interest_rate":   {formatFloat(b.InterestRate)},
+		"duration":        {sii.Int(b.Duration).Format()},
+	}
+}
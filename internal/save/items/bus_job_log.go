@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // BusJobLog mirrors the C# Bus_job_Log class from CustomClasses/Save/Items/Bus_job_Log.cs.
@@ -14,7 +16,8 @@ type BusJobLog struct {
 
 // FromProperties populates the BusJobLog from a map of SII properties.
 func (b *BusJobLog) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // BusStop mirrors the C# Bus_stop class from CustomClasses/Save/Items/Bus_stop.cs.
@@ -14,7 +16,8 @@ type BusStop struct {
 
 // FromProperties populates the BusStop from a map of SII properties.
 func (b *BusStop) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // DeliveryLog mirrors the C# Delivery_log class from CustomClasses/Save/Items/Delivery_log.cs.
@@ -15,7 +17,8 @@ type DeliveryLog struct {
 
 // FromProperties populates the DeliveryLog from a map of SII properties.
 func (d *DeliveryLog) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
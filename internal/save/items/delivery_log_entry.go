@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // DeliveryLogEntry mirrors the C# Delivery_log_Entry class from CustomClasses/Save/Items/Delivery_log_Entry.cs.
@@ -13,7 +15,8 @@ type DeliveryLogEntry struct {
 
 // FromProperties populates the DeliveryLogEntry from a map of SII properties.
 func (d *DeliveryLogEntry) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -0,0 +1,43 @@
+package items
+
+import "github.com/robebs/ts-se-tool-go/internal/sii"
+
+// BankLoansFromDocument builds typed BankLoans from the "bank_loan" blocks of
+// a document.
+func BankLoansFromDocument(doc *sii.Document) []BankLoan {
+	var out []BankLoan
+	for _, b := range doc.BlocksByType("bank_loan") {
+		var loan BankLoan
+		loan.FromProperties(b.Properties)
+		out = append(out, loan)
+	}
+	return out
+}
+
+// EconomyFromDocument converts the first "economy" block of a document to a
+// typed Economy. It returns nil when there is none.
+func EconomyFromDocument(doc *sii.Document) (*Economy, error) {
+	b := doc.FirstBlockByType("economy")
+	if b == nil {
+		return nil, nil
+	}
+	var e Economy
+	if err := e.FromProperties(b.Properties); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// PlayerFromDocument converts the first "player" block of a document to a
+// typed Player. It returns nil when there is none.
+func PlayerFromDocument(doc *sii.Document) (*Player, error) {
+	b := doc.FirstBlockByType("player")
+	if b == nil {
+		return nil, nil
+	}
+	var p Player
+	if err := p.FromProperties(b.Properties); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
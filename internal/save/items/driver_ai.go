@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // DriverAI mirrors the C# Driver_AI class from CustomClasses/Save/Items/Driver_AI.cs.
@@ -38,7 +39,8 @@ type DriverAI struct {
 
 // FromProperties populates the DriverAI from a map of SII properties.
 func (d *DriverAI) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -136,19 +138,3 @@ func (d *DriverAI) ToProperties() map[string][]string {
 	return props
 }
 
-func parseUint8(s string) uint8 {
-	val, err := strconv.ParseUint(s, 10, 8)
-	if err != nil {
-		return 0
-	}
-	return uint8(val)
-}
-
-func parseUint32(s string) uint32 {
-	val, err := strconv.ParseUint(s, 10, 32)
-	if err != nil {
-		return 0
-	}
-	return uint32(val)
-}
-
@@ -1,5 +1,7 @@
 package items
 
+import "github.com/robebs/ts-se-tool-go/internal/sii"
+
 // DriverPlayer mirrors the C# Driver_Player class from CustomClasses/Save/Items/Driver_Player.cs.
 type DriverPlayer struct {
 	ProfitLog string
@@ -7,7 +9,8 @@ type DriverPlayer struct {
 
 // FromProperties populates the DriverPlayer from a map of SII properties.
 func (d *DriverPlayer) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Economy mirrors Economy in C# CustomClasses/Save/Items/Economy.cs
@@ -38,7 +39,7 @@ type Economy struct {
 	StoredCameraMode               int
 	StoredActorState               int
 	StoredHighBeamStyle            int
-	StoredActorWindowsState        dataformat.Vector2f
+	StoredActorWindowsState        sii.Vec2f
 	StoredActorWiperMode           int
 	StoredActorRetarder            int
 	StoredDisplayMode              int
@@ -59,17 +60,17 @@ type Economy struct {
 	StoredRainWetness              dataformat.Float
 	TimeZone                       int
 	TimeZoneName                   string
-	LastFerryPosition              dataformat.Vector3i
+	LastFerryPosition              sii.Vec3i
 	StoredShowWeigh                bool
 	StoredNeedToWeigh              bool
-	StoredNavStartPos              dataformat.Vector3i
-	StoredNavEndPos                dataformat.Vector3i
+	StoredNavStartPos              sii.Vec3i
+	StoredNavEndPos                sii.Vec3i
 	StoredGPSBehind                []string
 	StoredGPSAhead                 []string
 	StoredGPSBehindWaypoints       []string
 	StoredGPSAheadWaypoints        []string
 	StoredGPSAvoidWaypoints        []string
-	StoredStartTollgatePos         dataformat.Vector3i
+	StoredStartTollgatePos         sii.Vec3i
 	StoredTutorialState            int
 	StoredMapActions               []string
 	CleanDistanceCounter           int
@@ -90,9 +91,9 @@ type Economy struct {
 	TotalScreenshotCount           int
 	UndamagedCargoRow              int
 	ServiceVisitCount              int
-	LastServicePos                 dataformat.Vector3f
+	LastServicePos                 sii.Vec3f
 	GasStationVisitCount           int
-	LastGasStationPos              dataformat.Vector3f
+	LastGasStationPos              sii.Vec3f
 	EmergencyCallCount             int
 	AICrashCount                   int
 	TruckColorChangeCount          int
@@ -128,7 +129,8 @@ type Economy struct {
 
 // FromProperties fills the Economy struct from a map of properties.
 func (e *Economy) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -163,15 +165,15 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 			v, _ := strconv.ParseUint(val, 10, 32)
 			e.GameTime = uint32(v)
 		case key == "game_time_secs":
-			e.GameTimeSecs = parseEconomyFloat(val)
+			e.GameTimeSecs = parseFloat(val)
 		case key == "game_time_initial":
-			e.GameTimeInitial = parseEconomyInt(val)
+			e.GameTimeInitial = parseInt(val)
 		case key == "achievements_added":
-			e.AchievementsAdded = parseEconomyInt(val)
+			e.AchievementsAdded = parseInt(val)
 		case key == "new_game":
-			e.NewGame = parseEconomyBool(val)
+			e.NewGame = parseBool(val)
 		case key == "total_distance":
-			e.TotalDistance = parseEconomyInt(val)
+			e.TotalDistance = parseInt(val)
 		case key == "experience_points":
 			v, _ := strconv.ParseUint(val, 10, 32)
 			e.ExperiencePoints = uint32(v)
@@ -196,39 +198,35 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "user_colors":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "user_colors["):
-			color, err := parseColor(val)
-			if err != nil {
-				return fmt.Errorf("parse user_colors: %w", err)
-			}
-			e.UserColors = append(e.UserColors, color)
+			e.UserColors = append(e.UserColors, dataformat.NewColorFromString(val))
 		case key == "delivery_log":
 			e.DeliveryLog = val
 		case key == "ferry_log":
 			e.FerryLog = val
 		case key == "stored_camera_mode":
-			e.StoredCameraMode = parseEconomyInt(val)
+			e.StoredCameraMode = parseInt(val)
 		case key == "stored_actor_state":
-			e.StoredActorState = parseEconomyInt(val)
+			e.StoredActorState = parseInt(val)
 		case key == "stored_high_beam_style":
-			e.StoredHighBeamStyle = parseEconomyInt(val)
+			e.StoredHighBeamStyle = parseInt(val)
 		case key == "stored_actor_windows_state":
-			vec, err := parseVector2f(val)
+			vec, err := sii.ParseVec2f(val)
 			if err != nil {
 				return fmt.Errorf("parse stored_actor_windows_state: %w", err)
 			}
 			e.StoredActorWindowsState = vec
 		case key == "stored_actor_wiper_mode":
-			e.StoredActorWiperMode = parseEconomyInt(val)
+			e.StoredActorWiperMode = parseInt(val)
 		case key == "stored_actor_retarder":
-			e.StoredActorRetarder = parseEconomyInt(val)
+			e.StoredActorRetarder = parseInt(val)
 		case key == "stored_display_mode":
-			e.StoredDisplayMode = parseEconomyInt(val)
+			e.StoredDisplayMode = parseInt(val)
 		case key == "stored_dashboard_map_mode":
-			e.StoredDashboardMapMode = parseEconomyInt(val)
+			e.StoredDashboardMapMode = parseInt(val)
 		case key == "stored_world_map_zoom":
-			e.StoredWorldMapZoom = parseEconomyInt(val)
+			e.StoredWorldMapZoom = parseInt(val)
 		case key == "stored_online_job_id":
-			e.StoredOnlineJobID = parseEconomyInt(val)
+			e.StoredOnlineJobID = parseInt(val)
 		case key == "stored_online_gps_behind":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "stored_online_gps_behind["):
@@ -254,37 +252,37 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "police_ctrl":
 			e.PoliceCtrl = val
 		case key == "stored_map_state":
-			e.StoredMapState = parseEconomyInt(val)
+			e.StoredMapState = parseInt(val)
 		case key == "stored_gas_pump_money":
-			e.StoredGasPumpMoney = parseEconomyInt(val)
+			e.StoredGasPumpMoney = parseInt(val)
 		case key == "stored_weather_change_timer":
-			e.StoredWeatherChangeTimer = parseEconomyFloat(val)
+			e.StoredWeatherChangeTimer = parseFloat(val)
 		case key == "stored_current_weather":
-			e.StoredCurrentWeather = parseEconomyInt(val)
+			e.StoredCurrentWeather = parseInt(val)
 		case key == "stored_rain_wetness":
-			e.StoredRainWetness = parseEconomyFloat(val)
+			e.StoredRainWetness = parseFloat(val)
 		case key == "time_zone":
-			e.TimeZone = parseEconomyInt(val)
+			e.TimeZone = parseInt(val)
 		case key == "time_zone_name":
 			e.TimeZoneName = val
 		case key == "last_ferry_position":
-			vec, err := parseVector3i(val)
+			vec, err := sii.ParseVec3i(val)
 			if err != nil {
 				return fmt.Errorf("parse last_ferry_position: %w", err)
 			}
 			e.LastFerryPosition = vec
 		case key == "stored_show_weigh":
-			e.StoredShowWeigh = parseEconomyBool(val)
+			e.StoredShowWeigh = parseBool(val)
 		case key == "stored_need_to_weigh":
-			e.StoredNeedToWeigh = parseEconomyBool(val)
+			e.StoredNeedToWeigh = parseBool(val)
 		case key == "stored_nav_start_pos":
-			vec, err := parseVector3i(val)
+			vec, err := sii.ParseVec3i(val)
 			if err != nil {
 				return fmt.Errorf("parse stored_nav_start_pos: %w", err)
 			}
 			e.StoredNavStartPos = vec
 		case key == "stored_nav_end_pos":
-			vec, err := parseVector3i(val)
+			vec, err := sii.ParseVec3i(val)
 			if err != nil {
 				return fmt.Errorf("parse stored_nav_end_pos: %w", err)
 			}
@@ -310,33 +308,33 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case strings.HasPrefix(key, "stored_gps_avoid_waypoints["):
 			e.StoredGPSAvoidWaypoints = append(e.StoredGPSAvoidWaypoints, val)
 		case key == "stored_start_tollgate_pos":
-			vec, err := parseVector3i(val)
+			vec, err := sii.ParseVec3i(val)
 			if err != nil {
 				return fmt.Errorf("parse stored_start_tollgate_pos: %w", err)
 			}
 			e.StoredStartTollgatePos = vec
 		case key == "stored_tutorial_state":
-			e.StoredTutorialState = parseEconomyInt(val)
+			e.StoredTutorialState = parseInt(val)
 		case key == "stored_map_actions":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "stored_map_actions["):
 			e.StoredMapActions = append(e.StoredMapActions, val)
 		case key == "clean_distance_counter":
-			e.CleanDistanceCounter = parseEconomyInt(val)
+			e.CleanDistanceCounter = parseInt(val)
 		case key == "clean_distance_max":
-			e.CleanDistanceMax = parseEconomyInt(val)
+			e.CleanDistanceMax = parseInt(val)
 		case key == "no_cargo_damage_distance_counter":
-			e.NoCargoDamageDistanceCounter = parseEconomyInt(val)
+			e.NoCargoDamageDistanceCounter = parseInt(val)
 		case key == "no_cargo_damage_distance_max":
-			e.NoCargoDamageDistanceMax = parseEconomyInt(val)
+			e.NoCargoDamageDistanceMax = parseInt(val)
 		case key == "no_violation_distance_counter":
-			e.NoViolationDistanceCounter = parseEconomyInt(val)
+			e.NoViolationDistanceCounter = parseInt(val)
 		case key == "no_violation_distance_max":
-			e.NoViolationDistanceMax = parseEconomyInt(val)
+			e.NoViolationDistanceMax = parseInt(val)
 		case key == "total_real_time":
-			e.TotalRealTime = parseEconomyInt(val)
+			e.TotalRealTime = parseInt(val)
 		case key == "real_time_seconds":
-			e.RealTimeSeconds = parseEconomyFloat(val)
+			e.RealTimeSeconds = parseFloat(val)
 		case key == "visited_cities":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "visited_cities["):
@@ -344,7 +342,7 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "visited_cities_count":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "visited_cities_count["):
-			e.VisitedCitiesCount = append(e.VisitedCitiesCount, parseEconomyInt(val))
+			e.VisitedCitiesCount = append(e.VisitedCitiesCount, parseInt(val))
 		case key == "last_visited_city":
 			e.LastVisitedCity = val
 		case key == "discovered_cutscene_items":
@@ -355,7 +353,7 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "discovered_cutscene_items_states":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "discovered_cutscene_items_states["):
-			e.DiscoveredCutsceneItemsStates = append(e.DiscoveredCutsceneItemsStates, parseEconomyInt(val))
+			e.DiscoveredCutsceneItemsStates = append(e.DiscoveredCutsceneItemsStates, parseInt(val))
 		case key == "unlocked_dealers":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "unlocked_dealers["):
@@ -365,47 +363,47 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case strings.HasPrefix(key, "unlocked_recruitments["):
 			e.UnlockedRecruitments = append(e.UnlockedRecruitments, val)
 		case key == "total_screeshot_count":
-			e.TotalScreenshotCount = parseEconomyInt(val)
+			e.TotalScreenshotCount = parseInt(val)
 		case key == "undamaged_cargo_row":
-			e.UndamagedCargoRow = parseEconomyInt(val)
+			e.UndamagedCargoRow = parseInt(val)
 		case key == "service_visit_count":
-			e.ServiceVisitCount = parseEconomyInt(val)
+			e.ServiceVisitCount = parseInt(val)
 		case key == "last_service_pos":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse last_service_pos: %w", err)
 			}
 			e.LastServicePos = vec
 		case key == "gas_station_visit_count":
-			e.GasStationVisitCount = parseEconomyInt(val)
+			e.GasStationVisitCount = parseInt(val)
 		case key == "last_gas_station_pos":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse last_gas_station_pos: %w", err)
 			}
 			e.LastGasStationPos = vec
 		case key == "emergency_call_count":
-			e.EmergencyCallCount = parseEconomyInt(val)
+			e.EmergencyCallCount = parseInt(val)
 		case key == "ai_crash_count":
-			e.AICrashCount = parseEconomyInt(val)
+			e.AICrashCount = parseInt(val)
 		case key == "truck_color_change_count":
-			e.TruckColorChangeCount = parseEconomyInt(val)
+			e.TruckColorChangeCount = parseInt(val)
 		case key == "red_light_fine_count":
-			e.RedLightFineCount = parseEconomyInt(val)
+			e.RedLightFineCount = parseInt(val)
 		case key == "cancelled_job_count":
-			e.CancelledJobCount = parseEconomyInt(val)
+			e.CancelledJobCount = parseInt(val)
 		case key == "total_fuel_litres":
-			e.TotalFuelLitres = parseEconomyInt(val)
+			e.TotalFuelLitres = parseInt(val)
 		case key == "total_fuel_price":
-			e.TotalFuelPrice = parseEconomyInt(val)
+			e.TotalFuelPrice = parseInt(val)
 		case key == "transported_cargo_types":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "transported_cargo_types["):
 			e.TransportedCargoTypes = append(e.TransportedCargoTypes, val)
 		case key == "achieved_feats":
-			e.AchievedFeats = parseEconomyInt(val)
+			e.AchievedFeats = parseInt(val)
 		case key == "discovered_roads":
-			e.DiscoveredRoads = parseEconomyInt(val)
+			e.DiscoveredRoads = parseInt(val)
 		case key == "discovered_items":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "discovered_items["):
@@ -418,9 +416,9 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "freelance_truck_offer":
 			e.FreelanceTruckOffer = val
 		case key == "trucks_bought_online":
-			e.TrucksBoughtOnline = parseEconomyInt(val)
+			e.TrucksBoughtOnline = parseInt(val)
 		case key == "special_cargo_timer":
-			e.SpecialCargoTimer = parseEconomyInt(val)
+			e.SpecialCargoTimer = parseInt(val)
 		case key == "screen_access_list":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "screen_access_list["):
@@ -432,14 +430,14 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "registry":
 			e.Registry = val
 		case key == "company_jobs_invitation_sent":
-			e.CompanyJobsInvitationSent = parseEconomyBool(val)
+			e.CompanyJobsInvitationSent = parseBool(val)
 		case key == "company_check_hash":
 			v, _ := strconv.ParseUint(val, 10, 64)
 			e.CompanyCheckHash = v
 		case key == "relations":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "relations["):
-			e.Relations = append(e.Relations, parseEconomyInt(val))
+			e.Relations = append(e.Relations, parseInt(val))
 		case key == "bus_stops":
 			// capacity hint ignored
 		case strings.HasPrefix(key, "bus_stops["):
@@ -447,21 +445,21 @@ func (e *Economy) FromProperties(props map[string][]string) error {
 		case key == "bus_job_log":
 			e.BusJobLog = val
 		case key == "bus_experience_points":
-			e.BusExperiencePoints = parseEconomyInt(val)
+			e.BusExperiencePoints = parseInt(val)
 		case key == "bus_total_distance":
-			e.BusTotalDistance = parseEconomyInt(val)
+			e.BusTotalDistance = parseInt(val)
 		case key == "bus_finished_job_count":
-			e.BusFinishedJobCount = parseEconomyInt(val)
+			e.BusFinishedJobCount = parseInt(val)
 		case key == "bus_cancelled_job_count":
-			e.BusCancelledJobCount = parseEconomyInt(val)
+			e.BusCancelledJobCount = parseInt(val)
 		case key == "bus_total_passengers":
-			e.BusTotalPassengers = parseEconomyInt(val)
+			e.BusTotalPassengers = parseInt(val)
 		case key == "bus_total_stops":
-			e.BusTotalStops = parseEconomyInt(val)
+			e.BusTotalStops = parseInt(val)
 		case key == "bus_game_time":
-			e.BusGameTime = parseEconomyInt(val)
+			e.BusGameTime = parseInt(val)
 		case key == "bus_playing_time":
-			e.BusPlayingTime = parseEconomyInt(val)
+			e.BusPlayingTime = parseInt(val)
 		}
 	}
 	return nil
@@ -494,10 +492,10 @@ func (e *Economy) ToProperties() map[string][]string {
 	props["mail_ctrl"] = []string{e.MailCtrl}
 	props["oversize_offer_ctrl"] = []string{e.OversizeOfferCtrl}
 	props["game_time"] = []string{strconv.FormatUint(uint64(e.GameTime), 10)}
-	props["game_time_secs"] = []string{formatEconomyFloat(e.GameTimeSecs)}
+	props["game_time_secs"] = []string{formatFloat(e.GameTimeSecs)}
 	props["game_time_initial"] = []string{strconv.Itoa(e.GameTimeInitial)}
 	props["achievements_added"] = []string{strconv.Itoa(e.AchievementsAdded)}
-	props["new_game"] = []string{formatEconomyBool(e.NewGame)}
+	props["new_game"] = []string{formatBool(e.NewGame)}
 	props["total_distance"] = []string{strconv.Itoa(e.TotalDistance)}
 	props["experience_points"] = []string{strconv.FormatUint(uint64(e.ExperiencePoints), 10)}
 	props["adr"] = []string{strconv.FormatUint(uint64(e.Adr), 10)}
@@ -509,7 +507,7 @@ func (e *Economy) ToProperties() map[string][]string {
 
 	props["user_colors"] = []string{strconv.Itoa(len(e.UserColors))}
 	for i, v := range e.UserColors {
-		props[fmt.Sprintf("user_colors[%d]", i)] = []string{formatColor(v)}
+		props[fmt.Sprintf("user_colors[%d]", i)] = []string{v.ToString()}
 	}
 
 	props["delivery_log"] = []string{e.DeliveryLog}
@@ -517,7 +515,7 @@ func (e *Economy) ToProperties() map[string][]string {
 	props["stored_camera_mode"] = []string{strconv.Itoa(e.StoredCameraMode)}
 	props["stored_actor_state"] = []string{strconv.Itoa(e.StoredActorState)}
 	props["stored_high_beam_style"] = []string{strconv.Itoa(e.StoredHighBeamStyle)}
-	props["stored_actor_windows_state"] = []string{formatVector2f(e.StoredActorWindowsState)}
+	props["stored_actor_windows_state"] = []string{e.StoredActorWindowsState.Format()}
 	props["stored_actor_wiper_mode"] = []string{strconv.Itoa(e.StoredActorWiperMode)}
 	props["stored_actor_retarder"] = []string{strconv.Itoa(e.StoredActorRetarder)}
 	props["stored_display_mode"] = []string{strconv.Itoa(e.StoredDisplayMode)}
@@ -554,16 +552,16 @@ func (e *Economy) ToProperties() map[string][]string {
 	props["police_ctrl"] = []string{e.PoliceCtrl}
 	props["stored_map_state"] = []string{strconv.Itoa(e.StoredMapState)}
 	props["stored_gas_pump_money"] = []string{strconv.Itoa(e.StoredGasPumpMoney)}
-	props["stored_weather_change_timer"] = []string{formatEconomyFloat(e.StoredWeatherChangeTimer)}
+	props["stored_weather_change_timer"] = []string{formatFloat(e.StoredWeatherChangeTimer)}
 	props["stored_current_weather"] = []string{strconv.Itoa(e.StoredCurrentWeather)}
-	props["stored_rain_wetness"] = []string{formatEconomyFloat(e.StoredRainWetness)}
+	props["stored_rain_wetness"] = []string{formatFloat(e.StoredRainWetness)}
 	props["time_zone"] = []string{strconv.Itoa(e.TimeZone)}
 	props["time_zone_name"] = []string{e.TimeZoneName}
-	props["last_ferry_position"] = []string{formatVector3i(e.LastFerryPosition)}
-	props["stored_show_weigh"] = []string{formatEconomyBool(e.StoredShowWeigh)}
-	props["stored_need_to_weigh"] = []string{formatEconomyBool(e.StoredNeedToWeigh)}
-	props["stored_nav_start_pos"] = []string{formatVector3i(e.StoredNavStartPos)}
-	props["stored_nav_end_pos"] = []string{formatVector3i(e.StoredNavEndPos)}
+	props["last_ferry_position"] = []string{e.LastFerryPosition.Format()}
+	props["stored_show_weigh"] = []string{formatBool(e.StoredShowWeigh)}
+	props["stored_need_to_weigh"] = []string{formatBool(e.StoredNeedToWeigh)}
+	props["stored_nav_start_pos"] = []string{e.StoredNavStartPos.Format()}
+	props["stored_nav_end_pos"] = []string{e.StoredNavEndPos.Format()}
 
 	props["stored_gps_behind"] = []string{strconv.Itoa(len(e.StoredGPSBehind))}
 	for i, v := range e.StoredGPSBehind {
@@ -590,7 +588,7 @@ func (e *Economy) ToProperties() map[string][]string {
 		props[fmt.Sprintf("stored_gps_avoid_waypoints[%d]", i)] = []string{v}
 	}
 
-	props["stored_start_tollgate_pos"] = []string{formatVector3i(e.StoredStartTollgatePos)}
+	props["stored_start_tollgate_pos"] = []string{e.StoredStartTollgatePos.Format()}
 	props["stored_tutorial_state"] = []string{strconv.Itoa(e.StoredTutorialState)}
 
 	props["stored_map_actions"] = []string{strconv.Itoa(len(e.StoredMapActions))}
@@ -605,7 +603,7 @@ func (e *Economy) ToProperties() map[string][]string {
 	props["no_violation_distance_counter"] = []string{strconv.Itoa(e.NoViolationDistanceCounter)}
 	props["no_violation_distance_max"] = []string{strconv.Itoa(e.NoViolationDistanceMax)}
 	props["total_real_time"] = []string{strconv.Itoa(e.TotalRealTime)}
-	props["real_time_seconds"] = []string{formatEconomyFloat(e.RealTimeSeconds)}
+	props["real_time_seconds"] = []string{formatFloat(e.RealTimeSeconds)}
 
 	props["visited_cities"] = []string{strconv.Itoa(len(e.VisitedCities))}
 	for i, v := range e.VisitedCities {
@@ -642,9 +640,9 @@ func (e *Economy) ToProperties() map[string][]string {
 	props["total_screeshot_count"] = []string{strconv.Itoa(e.TotalScreenshotCount)}
 	props["undamaged_cargo_row"] = []string{strconv.Itoa(e.UndamagedCargoRow)}
 	props["service_visit_count"] = []string{strconv.Itoa(e.ServiceVisitCount)}
-	props["last_service_pos"] = []string{formatVector3f(e.LastServicePos)}
+	props["last_service_pos"] = []string{e.LastServicePos.Format()}
 	props["gas_station_visit_count"] = []string{strconv.Itoa(e.GasStationVisitCount)}
-	props["last_gas_station_pos"] = []string{formatVector3f(e.LastGasStationPos)}
+	props["last_gas_station_pos"] = []string{e.LastGasStationPos.Format()}
 	props["emergency_call_count"] = []string{strconv.Itoa(e.EmergencyCallCount)}
 	props["ai_crash_count"] = []string{strconv.Itoa(e.AICrashCount)}
 	props["truck_color_change_count"] = []string{strconv.Itoa(e.TruckColorChangeCount)}
@@ -686,7 +684,7 @@ func (e *Economy) ToProperties() map[string][]string {
 	}
 
 	props["registry"] = []string{e.Registry}
-	props["company_jobs_invitation_sent"] = []string{formatEconomyBool(e.CompanyJobsInvitationSent)}
+	props["company_jobs_invitation_sent"] = []string{formatBool(e.CompanyJobsInvitationSent)}
 	props["company_check_hash"] = []string{strconv.FormatUint(e.CompanyCheckHash, 10)}
 
 	props["relations"] = []string{strconv.Itoa(len(e.Relations))}
@@ -711,98 +709,3 @@ func (e *Economy) ToProperties() map[string][]string {
 
 	return props
 }
-
-// Helper functions for parsing and formatting
-
-func parseEconomyInt(s string) int {
-	v, _ := strconv.Atoi(s)
-	return v
-}
-
-func parseEconomyFloat(s string) dataformat.Float {
-	f, _ := strconv.ParseFloat(s, 32)
-	return dataformat.Float(f)
-}
-
-func formatEconomyFloat(f dataformat.Float) string {
-	return strconv.FormatFloat(float64(f), 'f', -1, 32)
-}
-
-func parseEconomyBool(s string) bool {
-	return strings.ToLower(s) == "true"
-}
-
-func formatEconomyBool(b bool) string {
-	return strconv.FormatBool(b)
-}
-
-// parseVector2f parses a Vector2f from a string like "(&bd85bf17, &bd5ecfd4)"
-func parseVector2f(s string) (dataformat.Vector2f, error) {
-	s = strings.TrimSpace(s)
-	s = strings.TrimPrefix(s, "(")
-	s = strings.TrimSuffix(s, ")")
-	parts := strings.Split(s, ",")
-	if len(parts) != 2 {
-		return dataformat.Vector2f{}, fmt.Errorf("invalid vector2f format: expected 2 components, got %d", len(parts))
-	}
-
-	x, err := parseHexFloat(strings.TrimSpace(parts[0]))
-	if err != nil {
-		return dataformat.Vector2f{}, fmt.Errorf("parse X: %w", err)
-	}
-	y, err := parseHexFloat(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return dataformat.Vector2f{}, fmt.Errorf("parse Y: %w", err)
-	}
-
-	return dataformat.Vector2f{X: x, Y: y}, nil
-}
-
-// formatVector2f formats a Vector2f to string like "(&bd85bf17, &bd5ecfd4)"
-func formatVector2f(v dataformat.Vector2f) string {
-	return fmt.Sprintf("(%s, %s)", formatHexFloat(v.X), formatHexFloat(v.Y))
-}
-
-// parseVector3i parses a Vector3i from a string like "(2147483647, 2147483647, 2147483647)"
-func parseVector3i(s string) (dataformat.Vector3i, error) {
-	s = strings.TrimSpace(s)
-	s = strings.TrimPrefix(s, "(")
-	s = strings.TrimSuffix(s, ")")
-	parts := strings.Split(s, ",")
-	if len(parts) != 3 {
-		return dataformat.Vector3i{}, fmt.Errorf("invalid vector3i format: expected 3 components, got %d", len(parts))
-	}
-
-	x, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 32)
-	if err != nil {
-		return dataformat.Vector3i{}, fmt.Errorf("parse X: %w", err)
-	}
-	y, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 32)
-	if err != nil {
-		return dataformat.Vector3i{}, fmt.Errorf("parse Y: %w", err)
-	}
-	z, err := strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 32)
-	if err != nil {
-		return dataformat.Vector3i{}, fmt.Errorf("parse Z: %w", err)
-	}
-
-	return dataformat.Vector3i{X: int32(x), Y: int32(y), Z: int32(z)}, nil
-}
-
-// formatVector3i formats a Vector3i to string like "(2147483647, 2147483647, 2147483647)"
-func formatVector3i(v dataformat.Vector3i) string {
-	return fmt.Sprintf("(%d, %d, %d)", v.X, v.Y, v.Z)
-}
-
-// parseColor parses a Color from a string (uses dataformat.Color parsing)
-func parseColor(s string) (dataformat.Color, error) {
-	return dataformat.NewColorFromString(s), nil
-}
-
-// formatColor formats a Color to string
-func formatColor(c dataformat.Color) string {
-	return c.ToString()
-}
-
-// parseVector3f, formatVector3f, parseHexFloat, formatHexFloat are defined in company.go
-// They are reused here since they're in the same package
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // EconomyEvent mirrors the C# Economy_event class from CustomClasses/Save/Items/Economy_event.cs.
@@ -13,7 +15,8 @@ type EconomyEvent struct {
 
 // FromProperties populates the EconomyEvent from a map of SII properties.
 func (e *EconomyEvent) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
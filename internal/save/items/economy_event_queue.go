@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // EconomyEventQueue mirrors the C# Economy_event_Queue class from CustomClasses/Save/Items/Economy_event_Queue.cs.
@@ -13,7 +15,8 @@ type EconomyEventQueue struct {
 
 // FromProperties populates the EconomyEventQueue from a map of SII properties.
 func (e *EconomyEventQueue) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
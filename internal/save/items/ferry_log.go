@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // FerryLog mirrors the C# Ferry_log class from CustomClasses/Save/Items/Ferry_log.cs.
@@ -13,7 +15,8 @@ type FerryLog struct {
 
 // FromProperties populates the FerryLog from a map of SII properties.
 func (f *FerryLog) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
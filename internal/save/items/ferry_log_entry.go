@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // FerryLogEntry mirrors the C# Ferry_log_Entry class from CustomClasses/Save/Items/Ferry_log_Entry.cs.
@@ -14,7 +16,8 @@ type FerryLogEntry struct {
 
 // FromProperties populates the FerryLogEntry from a map of SII properties.
 func (f *FerryLogEntry) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
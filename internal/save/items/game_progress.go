@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // GameProgress mirrors the C# Game_Progress class from CustomClasses/Save/Items/Game_Progress.cs.
@@ -18,7 +19,8 @@ type GameProgress struct {
 
 // FromProperties populates the GameProgress from a map of SII properties.
 func (g *GameProgress) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Garage mirrors the C# Garage class from CustomClasses/Save/Items/Garage.cs.
@@ -20,7 +21,8 @@ type Garage struct {
 
 // FromProperties populates the Garage from a map of SII properties.
 func (g *Garage) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -92,3 +94,29 @@ func (g *Garage) ToProperties() map[string][]string {
 	return props
 }
 
+// Garage statuses, as stored in garage.status. The values do not follow the
+// garage size: the tiny garage was added to the game after the others.
+const (
+	GarageNotOwned = 0
+	GarageSmall    = 2
+	GarageLarge    = 3
+	GarageTiny     = 6
+)
+
+// GarageSizes lists the statuses of owned garages from the smallest to the
+// largest.
+var GarageSizes = []int{GarageTiny, GarageSmall, GarageLarge}
+
+// GarageSlots returns how many trucks (and drivers) a garage with the given
+// status holds.
+func GarageSlots(status int) int {
+	switch status {
+	case GarageTiny:
+		return 1
+	case GarageSmall:
+		return 3
+	case GarageLarge:
+		return 5
+	}
+	return 0
+}
@@ -3,18 +3,19 @@ package items
 import (
 	"fmt"
 
-	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // GPSWaypointStorage mirrors the C# GPS_waypoint_Storage class from CustomClasses/Save/Items/GPS_waypoint_Storage.cs.
 type GPSWaypointStorage struct {
-	NavNodePosition dataformat.Vector3i
+	NavNodePosition sii.Vec3i
 	Direction       string
 }
 
 // FromProperties populates the GPSWaypointStorage from a map of SII properties.
 func (g *GPSWaypointStorage) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -22,7 +23,7 @@ func (g *GPSWaypointStorage) FromProperties(props map[string][]string) error {
 
 		switch {
 		case key == "nav_node_position":
-			vec, err := parseVector3i(val)
+			vec, err := sii.ParseVec3i(val)
 			if err != nil {
 				return fmt.Errorf("parse nav_node_position: %w", err)
 			}
@@ -38,7 +39,7 @@ func (g *GPSWaypointStorage) FromProperties(props map[string][]string) error {
 func (g *GPSWaypointStorage) ToProperties() map[string][]string {
 	props := make(map[string][]string)
 
-	props["nav_node_position"] = []string{formatVector3i(g.NavNodePosition)}
+	props["nav_node_position"] = []string{g.NavNodePosition.Format()}
 	props["direction"] = []string{g.Direction}
 
 	return props
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // JobInfo mirrors the C# Job_Info class from CustomClasses/Save/Items/Job_Info.cs.
@@ -24,7 +26,8 @@ type JobInfo struct {
 
 // FromProperties populates the JobInfo from a map of SII properties.
 func (j *JobInfo) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // MailCtrl mirrors the C# Mail_Ctrl class from CustomClasses/Save/Items/Mail_Ctrl.cs.
@@ -17,7 +19,8 @@ type MailCtrl struct {
 
 // FromProperties populates the MailCtrl from a map of SII properties.
 func (m *MailCtrl) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // MailDef mirrors the C# Mail_Def class from CustomClasses/Save/Items/Mail_Def.cs.
@@ -22,7 +23,8 @@ type MailDef struct {
 
 // FromProperties populates the MailDef from a map of SII properties.
 func (m *MailDef) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
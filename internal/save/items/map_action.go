@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // MapAction mirrors the C# Map_action class from CustomClasses/Save/Items/Map_action.cs.
@@ -22,7 +23,8 @@ type MapAction struct {
 
 // FromProperties populates the MapAction from a map of SII properties.
 func (m *MapAction) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
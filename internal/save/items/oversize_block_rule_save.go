@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // OversizeBlockRuleSave mirrors the C# Oversize_Block_rule_Save class from CustomClasses/Save/Items/Oversize_Block_rule_Save.cs.
@@ -13,7 +15,8 @@ type OversizeBlockRuleSave struct {
 
 // FromProperties populates the OversizeBlockRuleSave from a map of SII properties.
 func (o *OversizeBlockRuleSave) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -42,11 +45,3 @@ func (o *OversizeBlockRuleSave) ToProperties() map[string][]string {
 	return props
 }
 
-func parseUint64(s string) uint64 {
-	val, err := strconv.ParseUint(s, 10, 64)
-	if err != nil {
-		return 0
-	}
-	return val
-}
-
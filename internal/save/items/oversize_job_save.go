@@ -6,18 +6,19 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // OversizeJobSave mirrors the C# Oversize_Job_save class from CustomClasses/Save/Items/Oversize_Job_save.cs.
 type OversizeJobSave struct {
-	FrontEscortWSPosition          dataformat.Vector3f
-	BackEscortWSPosition           dataformat.Vector3f
+	FrontEscortWSPosition          sii.Vec3f
+	BackEscortWSPosition           sii.Vec3f
 	FrontTrajectoryUID             *uint64 // nullable uint64
 	BackTrajectoryUID              *uint64 // nullable uint64
 	FrontTrajectoryPosition        dataformat.Float
 	BackTrajectoryPosition         dataformat.Float
-	FrontEscortRotation            dataformat.Vector4f
-	BackEscortRotation             dataformat.Vector4f
+	FrontEscortRotation            sii.Quat
+	BackEscortRotation             sii.Quat
 	FrontEscortSpeed               dataformat.Float
 	BackEscortSpeed                dataformat.Float
 	SpawnEscortActive              bool
@@ -26,7 +27,7 @@ type OversizeJobSave struct {
 	BackCharType                   string
 	OversizeManagerState           int
 	OversizeManagerCurrentKdopIdx  *int // nullable int
-	OversizeManagerLastValidPos    dataformat.Vector3f
+	OversizeManagerLastValidPos    sii.Vec3f
 	ActiveBlocksRules              []string
 	FrontTypeState                 int
 	BackTypeState                  int
@@ -38,7 +39,8 @@ type OversizeJobSave struct {
 
 // FromProperties populates the OversizeJobSave from a map of SII properties.
 func (o *OversizeJobSave) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -46,13 +48,13 @@ func (o *OversizeJobSave) FromProperties(props map[string][]string) error {
 
 		switch {
 		case key == "front_escort_ws_position":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse front_escort_ws_position: %w", err)
 			}
 			o.FrontEscortWSPosition = vec
 		case key == "back_escort_ws_position":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse back_escort_ws_position: %w", err)
 			}
@@ -76,13 +78,13 @@ func (o *OversizeJobSave) FromProperties(props map[string][]string) error {
 		case key == "back_trajectory_position":
 			o.BackTrajectoryPosition = parseFloat(val)
 		case key == "front_escort_rotation":
-			vec, err := parseVector4f(val)
+			vec, err := sii.ParseQuat(val)
 			if err != nil {
 				return fmt.Errorf("parse front_escort_rotation: %w", err)
 			}
 			o.FrontEscortRotation = vec
 		case key == "back_escort_rotation":
-			vec, err := parseVector4f(val)
+			vec, err := sii.ParseQuat(val)
 			if err != nil {
 				return fmt.Errorf("parse back_escort_rotation: %w", err)
 			}
@@ -111,7 +113,7 @@ func (o *OversizeJobSave) FromProperties(props map[string][]string) error {
 				o.OversizeManagerCurrentKdopIdx = &idx
 			}
 		case key == "oversize_manager_last_valid_pos":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse oversize_manager_last_valid_pos: %w", err)
 			}
@@ -143,8 +145,8 @@ func (o *OversizeJobSave) FromProperties(props map[string][]string) error {
 func (o *OversizeJobSave) ToProperties() map[string][]string {
 	props := make(map[string][]string)
 
-	props["front_escort_ws_position"] = []string{formatVector3f(o.FrontEscortWSPosition)}
-	props["back_escort_ws_position"] = []string{formatVector3f(o.BackEscortWSPosition)}
+	props["front_escort_ws_position"] = []string{o.FrontEscortWSPosition.Format()}
+	props["back_escort_ws_position"] = []string{o.BackEscortWSPosition.Format()}
 
 	if o.FrontTrajectoryUID == nil {
 		props["front_trajectory_uid"] = []string{"nil"}
@@ -159,8 +161,8 @@ func (o *OversizeJobSave) ToProperties() map[string][]string {
 
 	props["front_trajectory_position"] = []string{formatFloat(o.FrontTrajectoryPosition)}
 	props["back_trajectory_position"] = []string{formatFloat(o.BackTrajectoryPosition)}
-	props["front_escort_rotation"] = []string{formatVector4f(o.FrontEscortRotation)}
-	props["back_escort_rotation"] = []string{formatVector4f(o.BackEscortRotation)}
+	props["front_escort_rotation"] = []string{o.FrontEscortRotation.Format()}
+	props["back_escort_rotation"] = []string{o.BackEscortRotation.Format()}
 	props["front_escort_speed"] = []string{formatFloat(o.FrontEscortSpeed)}
 	props["back_escort_speed"] = []string{formatFloat(o.BackEscortSpeed)}
 	props["spawn_escort_active"] = []string{formatBool(o.SpawnEscortActive)}
@@ -178,7 +180,7 @@ func (o *OversizeJobSave) ToProperties() map[string][]string {
 	} else {
 		props["oversize_manager_current_kdop_idx"] = []string{strconv.Itoa(*o.OversizeManagerCurrentKdopIdx)}
 	}
-	props["oversize_manager_last_valid_pos"] = []string{formatVector3f(o.OversizeManagerLastValidPos)}
+	props["oversize_manager_last_valid_pos"] = []string{o.OversizeManagerLastValidPos.Format()}
 
 	props["active_blocks_rules"] = []string{strconv.Itoa(len(o.ActiveBlocksRules))}
 	for i, v := range o.ActiveBlocksRules {
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // OversizeOffer mirrors the C# Oversize_Offer class from CustomClasses/Save/Items/Oversize_Offer.cs.
@@ -17,7 +18,8 @@ type OversizeOffer struct {
 
 // FromProperties populates the OversizeOffer from a map of SII properties.
 func (o *OversizeOffer) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // OversizeOfferCtrl mirrors the C# Oversize_offer_Ctrl class from CustomClasses/Save/Items/Oversize_offer_Ctrl.cs.
@@ -13,7 +15,8 @@ type OversizeOfferCtrl struct {
 
 // FromProperties populates the OversizeOfferCtrl from a map of SII properties.
 func (o *OversizeOfferCtrl) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
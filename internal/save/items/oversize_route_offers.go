@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // OversizeRouteOffers mirrors the C# Oversize_Route_offers class from CustomClasses/Save/Items/Oversize_Route_offers.cs.
@@ -14,7 +16,8 @@ type OversizeRouteOffers struct {
 
 // FromProperties populates the OversizeRouteOffers from a map of SII properties.
 func (o *OversizeRouteOffers) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Player mirrors the C# Player class from CustomClasses/Save/Items/Player.cs.
@@ -50,7 +51,8 @@ type Player struct {
 // FromProperties populates the Player from a map of SII properties as produced
 // by the sii parser.
 func (p *Player) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -219,29 +221,3 @@ func (p *Player) ToProperties() map[string][]string {
 
 	return props
 }
-
-func parseInt(s string) int {
-	v, _ := strconv.Atoi(s)
-	return v
-}
-
-func parseBool(s string) bool {
-	// C# ToString().ToLower() produit "true"/"false".
-	return strings.EqualFold(s, "true")
-}
-
-func parseFloat(s string) dataformat.Float {
-	f, _ := strconv.ParseFloat(s, 32)
-	return dataformat.Float(f)
-}
-
-func formatBool(b bool) string {
-	if b {
-		return "true"
-	}
-	return "false"
-}
-
-func formatFloat(f dataformat.Float) string {
-	return strconv.FormatFloat(float64(f), 'f', -1, 32)
-}
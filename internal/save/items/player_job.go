@@ -5,17 +5,18 @@ import (
 	"strconv"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // PlayerJob mirrors the C# Player_Job class from CustomClasses/Save/Items/Player_Job.cs.
 type PlayerJob struct {
 	CompanyTruck            string
 	CompanyTrailer          string
-	TargetPlacement         dataformat.Vector4f // Vector_3f_4f stored as Vector4f
-	TargetPlacementMedium   dataformat.Vector4f
-	TargetPlacementHard     dataformat.Vector4f
-	TargetPlacementRigid    dataformat.Vector4f
-	SourcePlacement         dataformat.Vector4f
+	TargetPlacement         sii.Placement
+	TargetPlacementMedium   sii.Placement
+	TargetPlacementHard     sii.Placement
+	TargetPlacementRigid    sii.Placement
+	SourcePlacement         sii.Placement
 	SelectedTarget          *int // nullable int
 	TimeLowerLimit          int
 	TimeUpperLimit          *int // nullable int
@@ -45,7 +46,8 @@ type PlayerJob struct {
 
 // FromProperties populates the PlayerJob from a map of SII properties.
 func (p *PlayerJob) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -57,31 +59,31 @@ func (p *PlayerJob) FromProperties(props map[string][]string) error {
 		case key == "company_trailer":
 			p.CompanyTrailer = val
 		case key == "target_placement":
-			vec, err := parseVector3f4f(val)
+			vec, err := sii.ParsePlacement(val)
 			if err != nil {
 				return fmt.Errorf("parse target_placement: %w", err)
 			}
 			p.TargetPlacement = vec
 		case key == "target_placement_medium":
-			vec, err := parseVector3f4f(val)
+			vec, err := sii.ParsePlacement(val)
 			if err != nil {
 				return fmt.Errorf("parse target_placement_medium: %w", err)
 			}
 			p.TargetPlacementMedium = vec
 		case key == "target_placement_hard":
-			vec, err := parseVector3f4f(val)
+			vec, err := sii.ParsePlacement(val)
 			if err != nil {
 				return fmt.Errorf("parse target_placement_hard: %w", err)
 			}
 			p.TargetPlacementHard = vec
 		case key == "target_placement_rigid":
-			vec, err := parseVector3f4f(val)
+			vec, err := sii.ParsePlacement(val)
 			if err != nil {
 				return fmt.Errorf("parse target_placement_rigid: %w", err)
 			}
 			p.TargetPlacementRigid = vec
 		case key == "source_placement":
-			vec, err := parseVector3f4f(val)
+			vec, err := sii.ParsePlacement(val)
 			if err != nil {
 				return fmt.Errorf("parse source_placement: %w", err)
 			}
@@ -162,11 +164,11 @@ func (p *PlayerJob) ToProperties() map[string][]string {
 
 	props["company_truck"] = []string{p.CompanyTruck}
 	props["company_trailer"] = []string{p.CompanyTrailer}
-	props["target_placement"] = []string{formatVector3f4f(p.TargetPlacement)}
-	props["target_placement_medium"] = []string{formatVector3f4f(p.TargetPlacementMedium)}
-	props["target_placement_hard"] = []string{formatVector3f4f(p.TargetPlacementHard)}
-	props["target_placement_rigid"] = []string{formatVector3f4f(p.TargetPlacementRigid)}
-	props["source_placement"] = []string{formatVector3f4f(p.SourcePlacement)}
+	props["target_placement"] = []string{p.TargetPlacement.Format()}
+	props["target_placement_medium"] = []string{p.TargetPlacementMedium.Format()}
+	props["target_placement_hard"] = []string{p.TargetPlacementHard.Format()}
+	props["target_placement_rigid"] = []string{p.TargetPlacementRigid.Format()}
+	props["source_placement"] = []string{p.SourcePlacement.Format()}
 	if p.SelectedTarget == nil {
 		props["selected_target"] = []string{"nil"}
 	} else {
@@ -0,0 +1,132 @@
+package items
+
+import "github.com/robebs/ts-se-tool-go/internal/sii"
+
+const (
+	owner = sii.PointerOwner
+	link  = sii.PointerLink
+)
+
+// Pointers lists the owner and link pointers of game.sii units, for the
+// sii.Document methods that keep references consistent (AddOwned,
+// RemoveCascade, DanglingReferences).
+var Pointers = sii.PointerSchema{Kinds: pointerKinds, Parallel: parallelArrays}
+
+// pointerKinds only lists properties that always point at units of the save.
+// Some properties, such as trailer.trailer_definition, point either at a unit
+// of the save or at a game definition ("trailer_def.scs...."); they are still
+// checked when their value is a nameless unit.
+var pointerKinds = map[string]map[string]sii.PointerKind{
+	"economy": {
+		"bank":                                 owner,
+		"player":                               owner,
+		"companies[]":                          owner,
+		"garages[]":                            owner,
+		"game_progress":                        owner,
+		"event_queue":                          owner,
+		"mail_ctrl":                            owner,
+		"oversize_offer_ctrl":                  owner,
+		"delivery_log":                         owner,
+		"ferry_log":                            owner,
+		"police_offence_log":                   owner,
+		"police_ctrl":                          owner,
+		"stored_online_gps_behind_waypoints[]": owner,
+		"stored_online_gps_ahead_waypoints[]":  owner,
+		"stored_online_gps_avoid_waypoints[]":  owner,
+		"stored_gps_behind_waypoints[]":        owner,
+		"stored_gps_ahead_waypoints[]":         owner,
+		"stored_gps_avoid_waypoints[]":         owner,
+		"stored_map_actions[]":                 owner,
+		"used_vehicle_assortment":              owner,
+		"freelance_truck_offer":                owner,
+		"driver_pool[]":                        owner,
+		"registry":                             owner,
+		"bus_stops[]":                          owner,
+		"bus_job_log":                          owner,
+	},
+	"bank": {
+		"loans[]": owner,
+	},
+	"player": {
+		"trailers[]":                 owner,
+		"trailer_utilization_logs[]": owner,
+		"trailer_defs[]":             owner,
+		"assigned_truck":             link,
+		"my_truck":                   link,
+		"assigned_trailer":           link,
+		"my_trailer":                 link,
+		"current_job":                owner,
+		"current_bus_job":            owner,
+		"selected_job":               link,
+		"trucks[]":                   owner,
+		"truck_profit_logs[]":        owner,
+		"drivers[]":                  owner,
+	},
+	"company": {
+		"job_offer[]":       owner,
+		"delivered_trailer": link,
+	},
+	"garage": {
+		"vehicles[]": link,
+		"drivers[]":  link,
+		"profit_log": owner,
+	},
+	"driver_ai": {
+		"driver_job":       owner,
+		"adopted_truck":    link,
+		"assigned_truck":   link,
+		"adopted_trailer":  link,
+		"assigned_trailer": link,
+		"profit_log":       owner,
+	},
+	"driver_player": {
+		"profit_log": owner,
+	},
+	"vehicle": {
+		"accessories[]": owner,
+	},
+	"trailer": {
+		"accessories[]": owner,
+		"slave_trailer": owner,
+	},
+	"profit_log": {
+		"stats_data[]": owner,
+	},
+	"delivery_log": {
+		"entries[]": owner,
+	},
+	"ferry_log": {
+		"entries[]": owner,
+	},
+	"mail_ctrl": {
+		"inbox[]": owner,
+	},
+	"economy_event_queue": {
+		"data[]": owner,
+	},
+	"economy_event": {
+		"unit_link": link,
+	},
+	"game_progress": {
+		"generic_transports[]":   owner,
+		"undamaged_transports[]": owner,
+		"clean_transports[]":     owner,
+	},
+	"used_vehicle_assortment": {
+		"trucks[]": owner,
+	},
+	"used_truck_offer": {
+		"truck": owner,
+	},
+}
+
+// parallelArrays lists the arrays of the player whose elements go together:
+// each truck has its profit log, each trailer its utilization log, and each
+// driver a set of flags and timers.
+var parallelArrays = map[string][][]string{
+	"player": {
+		{"trucks", "truck_profit_logs"},
+		{"trailers", "trailer_utilization_logs"},
+		{"drivers", "driver_flags", "driver_readiness_timer", "driver_undrivable_truck_timers"},
+	},
+}
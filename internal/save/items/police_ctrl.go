@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // PoliceCtrl mirrors the C# Police_Ctrl class from CustomClasses/Save/Items/Police_Ctrl.cs.
@@ -17,7 +18,8 @@ type PoliceCtrl struct {
 
 // FromProperties populates the PoliceCtrl from a map of SII properties.
 func (p *PoliceCtrl) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
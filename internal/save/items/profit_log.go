@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // ProfitLog mirrors the C# Profit_log class from CustomClasses/Save/Items/Profit_log.cs.
@@ -16,7 +18,8 @@ type ProfitLog struct {
 
 // FromProperties populates the ProfitLog from a map of SII properties.
 func (p *ProfitLog) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // ProfitLogEntry mirrors the C# Profit_log_Entry class from CustomClasses/Save/Items/Profit_log_Entry.cs.
@@ -23,7 +25,8 @@ type ProfitLogEntry struct {
 
 // FromProperties populates the ProfitLogEntry from a map of SII properties.
 func (p *ProfitLogEntry) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
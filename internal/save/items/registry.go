@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Registry mirrors the C# Registry class from CustomClasses/Save/Items/Registry.cs.
@@ -16,7 +18,8 @@ type Registry struct {
 
 // FromProperties populates the Registry from a map of SII properties.
 func (r *Registry) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
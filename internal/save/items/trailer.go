@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Trailer mirrors the C# Trailer class from CustomClasses/Save/Items/Trailer.cs.
@@ -15,6 +16,9 @@ type Trailer struct {
 	TrailerBodyWear          dataformat.Float
 	ChassisWear              dataformat.Float
 	WheelsWear               []dataformat.Float
+	TrailerBodyWearUnfixable dataformat.Float
+	ChassisWearUnfixable     dataformat.Float
+	WheelsWearUnfixable      []dataformat.Float
 	Accessories              []string
 	LicensePlate             dataformat.String
 	TrailerDefinition        string
@@ -34,7 +38,8 @@ type Trailer struct {
 
 // FromProperties populates the Trailer from a map of SII properties.
 func (t *Trailer) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -57,6 +62,8 @@ func (t *Trailer) FromProperties(props map[string][]string) error {
 			t.IsPrivate = parseBool(val)
 		case key == "trailer_body_wear":
 			t.TrailerBodyWear = parseFloat(val)
+		case key == "trailer_body_wear_unfixable":
+			t.TrailerBodyWearUnfixable = parseFloat(val)
 		case key == "accessories":
 			// capacity hint; ignored in Go, slices grow dynamically
 		case strings.HasPrefix(key, "accessories["):
@@ -81,6 +88,12 @@ func (t *Trailer) FromProperties(props map[string][]string) error {
 			t.LicensePlate = dataformat.String(val)
 		case key == "chassis_wear":
 			t.ChassisWear = parseFloat(val)
+		case key == "chassis_wear_unfixable":
+			t.ChassisWearUnfixable = parseFloat(val)
+		case key == "wheels_wear_unfixable":
+			// capacity hint; ignored in Go, slices grow dynamically
+		case strings.HasPrefix(key, "wheels_wear_unfixable["):
+			t.WheelsWearUnfixable = append(t.WheelsWearUnfixable, parseFloat(val))
 		case key == "wheels_wear":
 			// capacity hint; ignored in Go, slices grow dynamically
 		case strings.HasPrefix(key, "wheels_wear["):
@@ -102,6 +115,7 @@ func (t *Trailer) ToProperties() map[string][]string {
 	props["slave_trailer"] = []string{t.SlaveTrailer}
 	props["is_private"] = []string{formatBool(t.IsPrivate)}
 	props["trailer_body_wear"] = []string{formatFloat(t.TrailerBodyWear)}
+	props["trailer_body_wear_unfixable"] = []string{formatFloat(t.TrailerBodyWearUnfixable)}
 
 	props["accessories"] = []string{strconv.Itoa(len(t.Accessories))}
 	for i, v := range t.Accessories {
@@ -118,12 +132,18 @@ func (t *Trailer) ToProperties() map[string][]string {
 	props["trip_time"] = []string{formatFloat(t.TripTime)}
 	props["license_plate"] = []string{string(t.LicensePlate)}
 	props["chassis_wear"] = []string{formatFloat(t.ChassisWear)}
+	props["chassis_wear_unfixable"] = []string{formatFloat(t.ChassisWearUnfixable)}
 
 	props["wheels_wear"] = []string{strconv.Itoa(len(t.WheelsWear))}
 	for i, v := range t.WheelsWear {
 		props[fmt.Sprintf("wheels_wear[%d]", i)] = []string{formatFloat(v)}
 	}
 
+	props["wheels_wear_unfixable"] = []string{strconv.Itoa(len(t.WheelsWearUnfixable))}
+	for i, v := range t.WheelsWearUnfixable {
+		props[fmt.Sprintf("wheels_wear_unfixable[%d]", i)] = []string{formatFloat(v)}
+	}
+
 	return props
 }
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // TrailerDef mirrors the C# Trailer_Def class from CustomClasses/Save/Items/Trailer_Def.cs.
@@ -26,7 +27,8 @@ type TrailerDef struct {
 
 // FromProperties populates the TrailerDef from a map of SII properties.
 func (t *TrailerDef) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
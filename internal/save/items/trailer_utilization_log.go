@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // TrailerUtilizationLog mirrors the C# Trailer_Utilization_log class from CustomClasses/Save/Items/Trailer_Utilization_log.cs.
@@ -18,7 +19,8 @@ type TrailerUtilizationLog struct {
 
 // FromProperties populates the TrailerUtilizationLog from a map of SII properties.
 func (t *TrailerUtilizationLog) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // TrailerUtilizationLogEntry mirrors the C# Trailer_Utilization_log_Entry class from CustomClasses/Save/Items/Trailer_Utilization_log_Entry.cs.
@@ -12,7 +14,8 @@ type TrailerUtilizationLogEntry struct {
 
 // FromProperties populates the TrailerUtilizationLogEntry from a map of SII properties.
 func (t *TrailerUtilizationLogEntry) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
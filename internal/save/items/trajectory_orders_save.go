@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // TrajectoryOrdersSave mirrors the C# Trajectory_orders_Save class from CustomClasses/Save/Items/Trajectory_orders_Save.cs.
@@ -15,7 +17,8 @@ type TrajectoryOrdersSave struct {
 
 // FromProperties populates the TrajectoryOrdersSave from a map of SII properties.
 func (t *TrajectoryOrdersSave) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
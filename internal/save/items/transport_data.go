@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // TransportData mirrors the C# Transport_Data class from CustomClasses/Save/Items/Transport_Data.cs.
@@ -20,7 +21,8 @@ type TransportData struct {
 
 // FromProperties populates the TransportData from a map of SII properties.
 func (t *TransportData) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Unidentified mirrors the C# Unidentified class from CustomClasses/Save/Items/Unidentified.cs.
@@ -15,7 +17,8 @@ func (u *Unidentified) FromProperties(props map[string][]string) error {
 	// For Unidentified, we store the raw lines
 	// This is a simplified version - in practice, you might want to preserve the original format
 	var lines []string
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -0,0 +1,53 @@
+package items
+
+import (
+	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// Lenient wrappers around the sii value parsers for FromProperties: like the
+// C# classes, unparsable (or nil) values leave the field at its zero value.
+
+func parseInt(s string) int {
+	v, _ := sii.ParseInt(s)
+	return int(v)
+}
+
+func parseUint8(s string) uint8 {
+	v, err := sii.ParseUint(s)
+	if err != nil || v > 0xff {
+		return 0
+	}
+	return uint8(v)
+}
+
+func parseUint32(s string) uint32 {
+	v, err := sii.ParseUint(s)
+	if err != nil || v > 0xffffffff {
+		return 0
+	}
+	return uint32(v)
+}
+
+func parseUint64(s string) uint64 {
+	v, _ := sii.ParseUint(s)
+	return v
+}
+
+func parseBool(s string) bool {
+	v, _ := sii.ParseBool(s)
+	return v
+}
+
+func parseFloat(s string) dataformat.Float {
+	f, _ := sii.ParseFloat(s)
+	return dataformat.Float(f)
+}
+
+func formatBool(b bool) string {
+	return sii.Bool(b).Format()
+}
+
+func formatFloat(f dataformat.Float) string {
+	return sii.FormatFloat(float32(f))
+}
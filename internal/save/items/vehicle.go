@@ -6,39 +6,50 @@ import (
 	"strings"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // Vehicle mirrors the C# Vehicle class from CustomClasses/Save/Items/Vehicle.cs.
 type Vehicle struct {
-	EngineWear                dataformat.Float
-	TransmissionWear          dataformat.Float
-	CabinWear                 dataformat.Float
-	ChassisWear               dataformat.Float
-	WheelsWear                []dataformat.Float
-	Accessories               []string
-	LicensePlate              dataformat.String
-	FuelRelative              dataformat.Float
-	Odometer                  uint32
-	OdometerFloatPart         dataformat.Float
-	RheostatFactor            dataformat.Float
-	UserMirrorRot             []dataformat.Vector4f
-	UserHeadOffset            dataformat.Vector3f
-	UserFov                   dataformat.Float
-	UserWheelUpDown           dataformat.Float
-	UserWheelFrontBack        dataformat.Float
-	UserMouseLeftRightDefault dataformat.Float
-	UserMouseUpDownDefault    dataformat.Float
-	TripFuelL                 uint32
-	TripFuel                  dataformat.Float
-	TripDistanceKm            uint32
-	TripDistance              dataformat.Float
-	TripTimeMin               uint32
-	TripTime                  dataformat.Float
+	EngineWear                 dataformat.Float
+	TransmissionWear           dataformat.Float
+	CabinWear                  dataformat.Float
+	ChassisWear                dataformat.Float
+	WheelsWear                 []dataformat.Float
+	EngineWearUnfixable        dataformat.Float
+	TransmissionWearUnfixable  dataformat.Float
+	CabinWearUnfixable         dataformat.Float
+	ChassisWearUnfixable       dataformat.Float
+	WheelsWearUnfixable        []dataformat.Float
+	Accessories                []string
+	LicensePlate               dataformat.String
+	FuelRelative               dataformat.Float
+	Odometer                   uint32
+	OdometerFloatPart          dataformat.Float
+	IntegrityOdometer          uint32
+	IntegrityOdometerFloatPart dataformat.Float
+	RheostatFactor             dataformat.Float
+	UserMirrorRot              []sii.Quat
+	UserHeadOffset             sii.Vec3f
+	UserFov                    dataformat.Float
+	UserWheelUpDown            dataformat.Float
+	UserWheelFrontBack         dataformat.Float
+	UserMouseLeftRightDefault  dataformat.Float
+	UserMouseUpDownDefault     dataformat.Float
+	TripFuelL                  uint32
+	TripFuel                   dataformat.Float
+	TripRecuperationKwh        uint32
+	TripRecuperation           dataformat.Float
+	TripDistanceKm             uint32
+	TripDistance               dataformat.Float
+	TripTimeMin                uint32
+	TripTime                   dataformat.Float
 }
 
 // FromProperties populates the Vehicle from a map of SII properties.
 func (v *Vehicle) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -57,6 +68,18 @@ func (v *Vehicle) FromProperties(props map[string][]string) error {
 			// capacity hint; ignored in Go, slices grow dynamically
 		case strings.HasPrefix(key, "wheels_wear["):
 			v.WheelsWear = append(v.WheelsWear, parseFloat(val))
+		case key == "engine_wear_unfixable":
+			v.EngineWearUnfixable = parseFloat(val)
+		case key == "transmission_wear_unfixable":
+			v.TransmissionWearUnfixable = parseFloat(val)
+		case key == "cabin_wear_unfixable":
+			v.CabinWearUnfixable = parseFloat(val)
+		case key == "chassis_wear_unfixable":
+			v.ChassisWearUnfixable = parseFloat(val)
+		case key == "wheels_wear_unfixable":
+			// capacity hint; ignored in Go, slices grow dynamically
+		case strings.HasPrefix(key, "wheels_wear_unfixable["):
+			v.WheelsWearUnfixable = append(v.WheelsWearUnfixable, parseFloat(val))
 		case key == "fuel_relative":
 			v.FuelRelative = parseFloat(val)
 		case key == "license_plate":
@@ -68,13 +91,13 @@ func (v *Vehicle) FromProperties(props map[string][]string) error {
 		case key == "user_mirror_rot":
 			// capacity hint; ignored in Go, slices grow dynamically
 		case strings.HasPrefix(key, "user_mirror_rot["):
-			vec, err := parseVector4f(val)
+			vec, err := sii.ParseQuat(val)
 			if err != nil {
 				return fmt.Errorf("parse user_mirror_rot: %w", err)
 			}
 			v.UserMirrorRot = append(v.UserMirrorRot, vec)
 		case key == "user_head_offset":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return fmt.Errorf("parse user_head_offset: %w", err)
 			}
@@ -95,10 +118,18 @@ func (v *Vehicle) FromProperties(props map[string][]string) error {
 			v.Odometer = parseUint32(val)
 		case key == "odometer_float_part":
 			v.OdometerFloatPart = parseFloat(val)
+		case key == "integrity_odometer":
+			v.IntegrityOdometer = parseUint32(val)
+		case key == "integrity_odometer_float_part":
+			v.IntegrityOdometerFloatPart = parseFloat(val)
 		case key == "trip_fuel_l":
 			v.TripFuelL = parseUint32(val)
 		case key == "trip_fuel":
 			v.TripFuel = parseFloat(val)
+		case key == "trip_recuperation_kwh":
+			v.TripRecuperationKwh = parseUint32(val)
+		case key == "trip_recuperation":
+			v.TripRecuperation = parseFloat(val)
 		case key == "trip_distance_km":
 			v.TripDistanceKm = parseUint32(val)
 		case key == "trip_distance":
@@ -119,15 +150,18 @@ func (v *Vehicle) ToProperties() map[string][]string {
 	props["engine_wear"] = []string{formatFloat(v.EngineWear)}
 	props["transmission_wear"] = []string{formatFloat(v.TransmissionWear)}
 	props["cabin_wear"] = []string{formatFloat(v.CabinWear)}
+	props["engine_wear_unfixable"] = []string{formatFloat(v.EngineWearUnfixable)}
+	props["transmission_wear_unfixable"] = []string{formatFloat(v.TransmissionWearUnfixable)}
+	props["cabin_wear_unfixable"] = []string{formatFloat(v.CabinWearUnfixable)}
 	props["fuel_relative"] = []string{formatFloat(v.FuelRelative)}
 	props["rheostat_factor"] = []string{formatFloat(v.RheostatFactor)}
 
 	props["user_mirror_rot"] = []string{strconv.Itoa(len(v.UserMirrorRot))}
 	for i, vec := range v.UserMirrorRot {
-		props[fmt.Sprintf("user_mirror_rot[%d]", i)] = []string{formatVector4f(vec)}
+		props[fmt.Sprintf("user_mirror_rot[%d]", i)] = []string{vec.Format()}
 	}
 
-	props["user_head_offset"] = []string{formatVector3f(v.UserHeadOffset)}
+	props["user_head_offset"] = []string{v.UserHeadOffset.Format()}
 	props["user_fov"] = []string{formatFloat(v.UserFov)}
 	props["user_wheel_up_down"] = []string{formatFloat(v.UserWheelUpDown)}
 	props["user_wheel_front_back"] = []string{formatFloat(v.UserWheelFrontBack)}
@@ -141,20 +175,29 @@ func (v *Vehicle) ToProperties() map[string][]string {
 
 	props["odometer"] = []string{strconv.FormatUint(uint64(v.Odometer), 10)}
 	props["odometer_float_part"] = []string{formatFloat(v.OdometerFloatPart)}
+	props["integrity_odometer"] = []string{strconv.FormatUint(uint64(v.IntegrityOdometer), 10)}
+	props["integrity_odometer_float_part"] = []string{formatFloat(v.IntegrityOdometerFloatPart)}
 	props["trip_fuel_l"] = []string{strconv.FormatUint(uint64(v.TripFuelL), 10)}
 	props["trip_fuel"] = []string{formatFloat(v.TripFuel)}
+	props["trip_recuperation_kwh"] = []string{strconv.FormatUint(uint64(v.TripRecuperationKwh), 10)}
+	props["trip_recuperation"] = []string{formatFloat(v.TripRecuperation)}
 	props["trip_distance_km"] = []string{strconv.FormatUint(uint64(v.TripDistanceKm), 10)}
 	props["trip_distance"] = []string{formatFloat(v.TripDistance)}
 	props["trip_time_min"] = []string{strconv.FormatUint(uint64(v.TripTimeMin), 10)}
 	props["trip_time"] = []string{formatFloat(v.TripTime)}
 	props["license_plate"] = []string{string(v.LicensePlate)}
 	props["chassis_wear"] = []string{formatFloat(v.ChassisWear)}
+	props["chassis_wear_unfixable"] = []string{formatFloat(v.ChassisWearUnfixable)}
 
 	props["wheels_wear"] = []string{strconv.Itoa(len(v.WheelsWear))}
 	for i, wear := range v.WheelsWear {
 		props[fmt.Sprintf("wheels_wear[%d]", i)] = []string{formatFloat(wear)}
 	}
 
+	props["wheels_wear_unfixable"] = []string{strconv.Itoa(len(v.WheelsWearUnfixable))}
+	for i, wear := range v.WheelsWearUnfixable {
+		props[fmt.Sprintf("wheels_wear_unfixable[%d]", i)] = []string{formatFloat(wear)}
+	}
+
 	return props
 }
-
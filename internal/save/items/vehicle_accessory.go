@@ -3,6 +3,8 @@ package items
 import (
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleAccessory mirrors the C# Vehicle_Accessory class from CustomClasses/Save/Items/Vehicle_Accessory.cs.
@@ -15,7 +17,8 @@ type VehicleAccessory struct {
 // FromProperties populates the VehicleAccessory from a map of SII properties.
 func (v *VehicleAccessory) FromProperties(props map[string][]string) error {
 	v.AccType = "generalpart" // default
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
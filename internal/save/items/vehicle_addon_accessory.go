@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleAddonAccessory mirrors the C# Vehicle_Addon_Accessory class from CustomClasses/Save/Items/Vehicle_Addon_Accessory.cs.
@@ -16,7 +18,8 @@ type VehicleAddonAccessory struct {
 
 // FromProperties populates the VehicleAddonAccessory from a map of SII properties.
 func (v *VehicleAddonAccessory) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleCargoAccessory mirrors the C# Vehicle_Cargo_Accessory class from CustomClasses/Save/Items/Vehicle_Cargo_Accessory.cs.
@@ -14,7 +16,8 @@ type VehicleCargoAccessory struct {
 
 // FromProperties populates the VehicleCargoAccessory from a map of SII properties.
 func (v *VehicleCargoAccessory) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
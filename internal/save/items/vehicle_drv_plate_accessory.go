@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleDrvPlateAccessory mirrors the C# Vehicle_Drv_plate_Accessory class from CustomClasses/Save/Items/Vehicle_Drv_plate_Accessory.cs.
@@ -17,7 +19,8 @@ type VehicleDrvPlateAccessory struct {
 
 // FromProperties populates the VehicleDrvPlateAccessory from a map of SII properties.
 func (v *VehicleDrvPlateAccessory) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
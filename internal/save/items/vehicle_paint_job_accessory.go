@@ -3,24 +3,25 @@ package items
 import (
 	"strconv"
 
-	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehiclePaintJobAccessory mirrors the C# Vehicle_Paint_job_Accessory class from CustomClasses/Save/Items/Vehicle_Paint_job_Accessory.cs.
 type VehiclePaintJobAccessory struct {
-	MaskRColor dataformat.Vector3f
-	MaskGColor dataformat.Vector3f
-	MaskBColor dataformat.Vector3f
-	FlakeColor dataformat.Vector3f
-	FlipColor  dataformat.Vector3f
-	BaseColor  dataformat.Vector3f
+	MaskRColor sii.Vec3f
+	MaskGColor sii.Vec3f
+	MaskBColor sii.Vec3f
+	FlakeColor sii.Vec3f
+	FlipColor  sii.Vec3f
+	BaseColor  sii.Vec3f
 	DataPath   string
 	Refund     uint32
 }
 
 // FromProperties populates the VehiclePaintJobAccessory from a map of SII properties.
 func (v *VehiclePaintJobAccessory) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -28,37 +29,37 @@ func (v *VehiclePaintJobAccessory) FromProperties(props map[string][]string) err
 
 		switch {
 		case key == "mask_r_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
 			v.MaskRColor = vec
 		case key == "mask_g_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
 			v.MaskGColor = vec
 		case key == "mask_b_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
 			v.MaskBColor = vec
 		case key == "flake_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
 			v.FlakeColor = vec
 		case key == "flip_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
 			v.FlipColor = vec
 		case key == "base_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
@@ -76,12 +77,12 @@ func (v *VehiclePaintJobAccessory) FromProperties(props map[string][]string) err
 func (v *VehiclePaintJobAccessory) ToProperties() map[string][]string {
 	props := make(map[string][]string)
 
-	props["mask_r_color"] = []string{formatVector3f(v.MaskRColor)}
-	props["mask_g_color"] = []string{formatVector3f(v.MaskGColor)}
-	props["mask_b_color"] = []string{formatVector3f(v.MaskBColor)}
-	props["flake_color"] = []string{formatVector3f(v.FlakeColor)}
-	props["flip_color"] = []string{formatVector3f(v.FlipColor)}
-	props["base_color"] = []string{formatVector3f(v.BaseColor)}
+	props["mask_r_color"] = []string{v.MaskRColor.Format()}
+	props["mask_g_color"] = []string{v.MaskGColor.Format()}
+	props["mask_b_color"] = []string{v.MaskBColor.Format()}
+	props["flake_color"] = []string{v.FlakeColor.Format()}
+	props["flip_color"] = []string{v.FlipColor.Format()}
+	props["base_color"] = []string{v.BaseColor.Format()}
 	props["data_path"] = []string{v.DataPath}
 	props["refund"] = []string{strconv.FormatUint(uint64(v.Refund), 10)}
 
@@ -2,6 +2,8 @@ package items
 
 import (
 	"strconv"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleSoundAccessory mirrors the C# Vehicle_Sound_Accessory class from CustomClasses/Save/Items/Vehicle_Sound_Accessory.cs.
@@ -12,7 +14,8 @@ type VehicleSoundAccessory struct {
 
 // FromProperties populates the VehicleSoundAccessory from a map of SII properties.
 func (v *VehicleSoundAccessory) FromProperties(props map[string][]string) error {
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
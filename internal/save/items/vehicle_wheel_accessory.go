@@ -4,13 +4,13 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // VehicleWheelAccessory mirrors the C# Vehicle_Wheel_Accessory class from CustomClasses/Save/Items/Vehicle_Wheel_Accessory.cs.
 type VehicleWheelAccessory struct {
 	Offset    int
-	PaintColor dataformat.Vector3f
+	PaintColor sii.Vec3f
 	DataPath  string
 	Refund    uint32
 	AccType   string // derived from data_path, defaults to "generalpart"
@@ -19,7 +19,8 @@ type VehicleWheelAccessory struct {
 // FromProperties populates the VehicleWheelAccessory from a map of SII properties.
 func (v *VehicleWheelAccessory) FromProperties(props map[string][]string) error {
 	v.AccType = "generalpart" // default
-	for key, vals := range props {
+	for _, key := range sii.SortedKeys(props) {
+		vals := props[key]
 		if len(vals) == 0 {
 			continue
 		}
@@ -29,7 +30,7 @@ func (v *VehicleWheelAccessory) FromProperties(props map[string][]string) error
 		case key == "offset":
 			v.Offset = parseInt(val)
 		case key == "paint_color":
-			vec, err := parseVector3f(val)
+			vec, err := sii.ParseVec3f(val)
 			if err != nil {
 				return err
 			}
@@ -54,7 +55,7 @@ func (v *VehicleWheelAccessory) ToProperties() map[string][]string {
 	props := make(map[string][]string)
 
 	props["offset"] = []string{strconv.Itoa(v.Offset)}
-	props["paint_color"] = []string{formatVector3f(v.PaintColor)}
+	props["paint_color"] = []string{v.PaintColor.Format()}
 	props["data_path"] = []string{v.DataPath}
 	props["refund"] = []string{strconv.FormatUint(uint64(v.Refund), 10)}
 
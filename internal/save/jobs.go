@@ -0,0 +1,196 @@
+package save
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/save/itemsextra"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// JobOffer describes a freight market job to add to a company.
+type JobOffer struct {
+	SourceCompany, SourceCity string // e.g. "ikea", "bruxelles"
+	TargetCompany, TargetCity string
+	Cargo                     string // "furniture" or "cargo.furniture"
+	Urgency                   int    // 0 standard, 1 important, 2 urgent
+
+	// Optional fields. With a world, empty ones are filled from the jobs
+	// already offered for the same cargo.
+	TrailerDefinition string // "trailer_def.scs.box.single_3.curtain"
+	TrailerVariant    string // "trailer.scs_curt"
+	CompanyTruck      string
+	UnitsCount        int
+
+	DistanceKm int // shortest_distance_km shown by the freight market
+	ExpiresIn  int // game minutes from now, 0 for a day
+}
+
+// defaultJobExpiration is how long an injected job is offered, in game
+// minutes.
+const defaultJobExpiration = 24 * 60
+
+// companyUnit returns the name of the company unit of company in city.
+func companyUnit(company, city string) string {
+	return "company.volatile." + company + "." + city
+}
+
+// AddJobOffers adds jobs to the job_offer list of their source company and
+// returns the names of the new job_offer_data units, for RemoveJobOffers.
+// With a world (which may be nil), cities, companies and cargoes are checked
+// against it and the optional fields are completed from it.
+func AddJobOffers(doc *sii.Document, w *world.World, jobs ...JobOffer) ([]string, error) {
+	var gameTime int
+	if econBlock := doc.FirstBlockByType("economy"); econBlock != nil {
+		gameTime = parseIntProp(econBlock.Properties, "game_time")
+	}
+
+	var names []string
+	for _, job := range jobs {
+		if err := prepareJobOffer(doc, w, &job); err != nil {
+			return names, err
+		}
+
+		source := companyUnit(job.SourceCompany, job.SourceCity)
+		expiresIn := job.ExpiresIn
+		if expiresIn <= 0 {
+			expiresIn = defaultJobExpiration
+		}
+		expiration := uint32(gameTime + expiresIn)
+		urgency := job.Urgency
+		offer := items.JobOfferData{
+			Target:             sii.Quote(job.TargetCompany + "." + job.TargetCity),
+			ExpirationTime:     &expiration,
+			Urgency:            &urgency,
+			ShortestDistanceKm: job.DistanceKm,
+			Cargo:              "cargo." + job.Cargo,
+			CompanyTruck:       job.CompanyTruck,
+			TrailerVariant:     job.TrailerVariant,
+			TrailerDefinition:  job.TrailerDefinition,
+			UnitsCount:         max(job.UnitsCount, 1),
+			FillRatio:          1,
+		}
+		block := &sii.Block{
+			Type:       "job_offer_data",
+			Name:       doc.NewNameless(),
+			Properties: offer.ToProperties(),
+			PropertyOrder: []string{"target", "expiration_time", "urgency", "shortest_distance_km",
+				"ferry_time", "ferry_price", "cargo", "company_truck", "trailer_variant",
+				"trailer_definition", "units_count", "fill_ratio", "trailer_place"},
+		}
+		if err := doc.AddOwned(items.Pointers, source, "job_offer", block); err != nil {
+			return names, fmt.Errorf("add job to %s: %w", source, err)
+		}
+		names = append(names, block.Name)
+	}
+	return names, nil
+}
+
+// prepareJobOffer checks job against doc and w, and completes it as
+// AddJobOffers would, without adding it.
+func prepareJobOffer(doc *sii.Document, w *world.World, job *JobOffer) error {
+	job.Cargo = strings.TrimPrefix(job.Cargo, "cargo.")
+	if err := completeJobOffer(job, w); err != nil {
+		return err
+	}
+	source := companyUnit(job.SourceCompany, job.SourceCity)
+	if b := doc.Block(source); b == nil || b.Type != "company" {
+		return fmt.Errorf("company %s not found", source)
+	}
+	if b := doc.Block(companyUnit(job.TargetCompany, job.TargetCity)); b == nil || b.Type != "company" {
+		return fmt.Errorf("company %s.%s not found", job.TargetCompany, job.TargetCity)
+	}
+	if job.Urgency < 0 || job.Urgency > 2 {
+		return fmt.Errorf("invalid urgency %d", job.Urgency)
+	}
+	return nil
+}
+
+// completeJobOffer checks job against w and fills its optional fields from
+// the jobs the world already knows. Without a world, the trailer and truck
+// must be given.
+func completeJobOffer(job *JobOffer, w *world.World) error {
+	if w != nil {
+		for _, c := range []struct{ company, city string }{
+			{job.SourceCompany, job.SourceCity},
+			{job.TargetCompany, job.TargetCity},
+		} {
+			if !worldHasCompany(w, c.company, c.city) {
+				return fmt.Errorf("no company %s in %s", c.company, c.city)
+			}
+		}
+
+		var cargo *itemsextra.Cargo
+		for _, cg := range w.Cargoes {
+			if cg.ID == job.Cargo {
+				cargo = cg
+				break
+			}
+		}
+		if cargo == nil {
+			return fmt.Errorf("unknown cargo %s", job.Cargo)
+		}
+		if job.TrailerDefinition == "" {
+			job.TrailerDefinition = cargo.TrailerDefName
+		}
+		if job.UnitsCount == 0 {
+			job.UnitsCount = cargo.UnitsCount
+		}
+		if job.TrailerVariant == "" {
+			for _, td := range w.TrailerDefs {
+				if td.Name == job.TrailerDefinition && len(td.Variants) > 0 {
+					job.TrailerVariant = td.Variants[0]
+					break
+				}
+			}
+		}
+		if job.CompanyTruck == "" {
+			for _, ct := range w.CompanyTrucks {
+				if ct.CargoType == cargo.CargoType {
+					job.CompanyTruck = ct.TruckID
+					break
+				}
+			}
+		}
+	}
+
+	switch {
+	case job.TrailerDefinition == "":
+		return fmt.Errorf("job with %s: no trailer definition", job.Cargo)
+	case job.TrailerVariant == "":
+		return fmt.Errorf("job with %s: no trailer variant", job.Cargo)
+	case job.CompanyTruck == "":
+		return fmt.Errorf("job with %s: no company truck", job.Cargo)
+	}
+	return nil
+}
+
+func worldHasCompany(w *world.World, company, city string) bool {
+	for _, c := range w.Cities {
+		if c.Name != city {
+			continue
+		}
+		for _, name := range c.Companies {
+			if name == company {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveJobOffers removes job offers, such as those returned by
+// AddJobOffers, from their companies. Saves do not tell injected jobs from
+// the ones the game generated, so the caller keeps their names. It returns
+// how many were removed.
+func RemoveJobOffers(doc *sii.Document, names ...string) int {
+	var offers []string
+	for _, name := range names {
+		if b := doc.Block(name); b != nil && b.Type == "job_offer_data" {
+			offers = append(offers, name)
+		}
+	}
+	return doc.RemoveCascade(items.Pointers, offers...)
+}
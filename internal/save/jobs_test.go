@@ -0,0 +1,150 @@
+package save
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/save/itemsextra"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const jobsDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ game_time: 5000
+}
+company : company.volatile.ikea.paris {
+ job_offer: 1
+ job_offer[0]: _nameless.10
+}
+job_offer_data : _nameless.10 {
+ target: "tesco.berlin"
+ cargo: cargo.furniture
+}
+company : company.volatile.tesco.berlin {
+ job_offer: 0
+}
+}
+`
+
+// jobsWorld knows the companies of jobsDoc and how furniture is carried.
+func jobsWorld() *world.World {
+	w := world.NewWorld(nil)
+	w.Cities = []*itemsextra.City{
+		{Name: "paris", Companies: []string{"ikea"}},
+		{Name: "berlin", Companies: []string{"tesco"}},
+	}
+	w.Cargoes = []*itemsextra.Cargo{{ID: "furniture", TrailerDefName: "trailer_def.scs.box.single_3.curtain", UnitsCount: 12}}
+	w.TrailerDefs = []*itemsextra.TrailerDefinition{{Name: "trailer_def.scs.box.single_3.curtain", Variants: []string{"trailer.scs_curt"}}}
+	w.CompanyTrucks = []*itemsextra.CompanyTruck{{TruckID: "truck.volvo.fh16.2013"}}
+	return w
+}
+
+func TestCompleteJobOffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     JobOffer
+		w       *world.World
+		want    JobOffer
+		wantErr string
+	}{
+		{
+			name: "from the world",
+			job:  JobOffer{SourceCompany: "ikea", SourceCity: "paris", TargetCompany: "tesco", TargetCity: "berlin", Cargo: "furniture"},
+			w:    jobsWorld(),
+			want: JobOffer{SourceCompany: "ikea", SourceCity: "paris", TargetCompany: "tesco", TargetCity: "berlin", Cargo: "furniture",
+				TrailerDefinition: "trailer_def.scs.box.single_3.curtain", TrailerVariant: "trailer.scs_curt",
+				CompanyTruck: "truck.volvo.fh16.2013", UnitsCount: 12},
+		},
+		{
+			name:    "unknown company",
+			job:     JobOffer{SourceCompany: "ikea", SourceCity: "lyon", TargetCompany: "tesco", TargetCity: "berlin", Cargo: "furniture"},
+			w:       jobsWorld(),
+			wantErr: "no company ikea in lyon",
+		},
+		{
+			name:    "unknown cargo",
+			job:     JobOffer{SourceCompany: "ikea", SourceCity: "paris", TargetCompany: "tesco", TargetCity: "berlin", Cargo: "gold"},
+			w:       jobsWorld(),
+			wantErr: "unknown cargo",
+		},
+		{
+			name:    "no world and no trailer",
+			job:     JobOffer{Cargo: "furniture", CompanyTruck: "truck.volvo.fh16.2013"},
+			wantErr: "no trailer definition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := tt.job
+			err := completeJobOffer(&job, tt.w)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("completeJobOffer = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("completeJobOffer: %v", err)
+			}
+			if job != tt.want {
+				t.Errorf("job = %+v, want %+v", job, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAndRemoveJobOffers(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(jobsDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	good := JobOffer{SourceCompany: "ikea", SourceCity: "paris", TargetCompany: "tesco", TargetCity: "berlin",
+		Cargo: "cargo.furniture", Urgency: 2, DistanceKm: 1050}
+	bad := good
+	bad.TargetCity = "lyon"
+
+	names, err := AddJobOffers(doc, jobsWorld(), good, bad)
+	if err == nil {
+		t.Fatalf("AddJobOffers accepted a job to an unknown company")
+	}
+	if len(names) != 1 {
+		t.Fatalf("AddJobOffers returned %v, want the job added before the error", names)
+	}
+	offers := sii.GetArray(doc.Block("company.volatile.ikea.paris").Properties, "job_offer")
+	if !slices.Equal(offers, []string{"_nameless.10", names[0]}) {
+		t.Fatalf("job_offer = %v, want the new job appended", offers)
+	}
+	props := doc.Block(names[0]).Properties
+	for key, want := range map[string]string{
+		"target":               `"tesco.berlin"`,
+		"cargo":                "cargo.furniture",
+		"expiration_time":      "6440",
+		"urgency":              "2",
+		"shortest_distance_km": "1050",
+		"trailer_variant":      "trailer.scs_curt",
+		"units_count":          "12",
+	} {
+		if got := firstProp(props, key); got != want {
+			t.Errorf("%s = %s, want %s", key, got, want)
+		}
+	}
+
+	if n := RemoveJobOffers(doc, append(names, "_nameless.404", "company.volatile.tesco.berlin")...); n != 1 {
+		t.Errorf("RemoveJobOffers = %d, want 1", n)
+	}
+	offers = sii.GetArray(doc.Block("company.volatile.ikea.paris").Properties, "job_offer")
+	if !slices.Equal(offers, []string{"_nameless.10"}) {
+		t.Errorf("job_offer = %v, want the game job kept", offers)
+	}
+	if doc.Block("company.volatile.tesco.berlin") == nil {
+		t.Errorf("RemoveJobOffers removed a company")
+	}
+	if refs := doc.DanglingReferences(items.Pointers); len(refs) != 0 {
+		t.Errorf("dangling references: %v", refs)
+	}
+}
@@ -28,20 +28,18 @@ func LoadWorldFromGameSII(path string) (*world.World, error) {
 	w := world.NewWorld(doc)
 
 	// Core typed blocks
-	econ, err := doc.ToEconomy()
+	econ, err := items.EconomyFromDocument(doc)
 	if err != nil {
 		return nil, fmt.Errorf("load economy: %w", err)
 	}
 	w.Economy = econ
 
-	player, err := doc.ToPlayer()
+	player, err := items.PlayerFromDocument(doc)
 	if err != nil {
 		return nil, fmt.Errorf("load player: %w", err)
 	}
 	w.Player = player
 
-	w.BlocksByID = doc.IndexByName()
-
 	if econ != nil {
 		prepareCitiesAndCompanies(w, econ)
 		prepareGarages(w, econ)
@@ -66,8 +64,8 @@ func prepareCitiesAndCompanies(w *world.World, econ *items.Economy) {
 		if len(parts) < 4 {
 			continue
 		}
-		// Example: company.ikea.city.bruxelles
-		companyID := parts[1]
+		// Example: company.volatile.ikea.bruxelles
+		companyID := parts[2]
 		cityID := parts[3]
 
 		city := cityByName[cityID]
@@ -94,7 +92,7 @@ func prepareCitiesAndCompanies(w *world.World, econ *items.Economy) {
 		}
 
 		// Optional: count job offers for this company
-		if blk, ok := w.BlocksByID[compName]; ok && blk.Type == "company" {
+		if blk := w.Document.Block(compName); blk != nil && blk.Type == "company" {
 			var c items.Company
 			if err := c.FromProperties(blk.Properties); err == nil {
 				count := len(c.JobOffer)
@@ -107,8 +105,8 @@ func prepareCitiesAndCompanies(w *world.World, econ *items.Economy) {
 // prepareGarages mirrors PrepareGaragesInitial().
 func prepareGarages(w *world.World, econ *items.Economy) {
 	for _, gname := range econ.Garages {
-		blk, ok := w.BlocksByID[gname]
-		if !ok || blk.Type != "garage" {
+		blk := w.Document.Block(gname)
+		if blk == nil || blk.Type != "garage" {
 			continue
 		}
 		var g items.Garage
@@ -140,8 +138,8 @@ func prepareCargoTrailerAndCompanyTrucks(w *world.World, econ *items.Economy) {
 	companyTrucks := make(map[string]*itemsextra.CompanyTruck)
 
 	for _, compName := range econ.Companies {
-		blk, ok := w.BlocksByID[compName]
-		if !ok || blk.Type != "company" {
+		blk := w.Document.Block(compName)
+		if blk == nil || blk.Type != "company" {
 			continue
 		}
 		var c items.Company
@@ -150,8 +148,8 @@ func prepareCargoTrailerAndCompanyTrucks(w *world.World, econ *items.Economy) {
 		}
 
 		for _, jobName := range c.JobOffer {
-			jobBlk, ok := w.BlocksByID[jobName]
-			if !ok || jobBlk.Type != "job_offer_data" {
+			jobBlk := w.Document.Block(jobName)
+			if jobBlk == nil || jobBlk.Type != "job_offer_data" {
 				continue
 			}
 			var j items.JobOfferData
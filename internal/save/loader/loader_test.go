@@ -0,0 +1,55 @@
+package loader
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/save/world"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+func TestPrepareCitiesAndCompanies(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(`SiiNunit
+{
+company : company.volatile.ikea.bruxelles {
+ job_offer: 2
+ job_offer[0]: _nameless.1
+ job_offer[1]: _nameless.2
+}
+company : company.volatile.tradeaux.bruxelles {
+ job_offer: 0
+}
+company : company.volatile.ikea.paris {
+ job_offer: 1
+ job_offer[0]: _nameless.3
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	w := world.NewWorld(doc)
+	prepareCitiesAndCompanies(w, &items.Economy{Companies: []string{
+		"company.volatile.ikea.bruxelles",
+		"company.volatile.tradeaux.bruxelles",
+		"company.volatile.ikea.paris",
+	}})
+
+	if len(w.Cities) != 2 {
+		t.Fatalf("got %d cities, want 2", len(w.Cities))
+	}
+	bruxelles := w.Cities[0]
+	if bruxelles.Name != "bruxelles" {
+		t.Fatalf("first city is %s, want bruxelles", bruxelles.Name)
+	}
+	if want := []string{"ikea", "tradeaux"}; !slices.Equal(bruxelles.Companies, want) {
+		t.Errorf("bruxelles companies = %v, want %v", bruxelles.Companies, want)
+	}
+	if n := bruxelles.JobOffersCountByCompany["ikea"]; n != 2 {
+		t.Errorf("ikea job offers in bruxelles = %d, want 2", n)
+	}
+	if paris := w.Cities[1]; !slices.Equal(paris.Companies, []string{"ikea"}) {
+		t.Errorf("paris companies = %v, want [ikea]", paris.Companies)
+	}
+}
@@ -0,0 +1,187 @@
+package save
+
+import (
+	"fmt"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// Loan is a bank loan of the player with the name of its bank_loan unit.
+type Loan struct {
+	Name string
+	items.BankLoan
+}
+
+// ListLoans returns the loans of the player, in the order of bank.loans.
+func ListLoans(doc *sii.Document) ([]Loan, error) {
+	bankBlock := doc.FirstBlockByType("bank")
+	if bankBlock == nil {
+		return nil, fmt.Errorf("bank block not found")
+	}
+	var loans []Loan
+	for _, name := range sii.GetArray(bankBlock.Properties, "loans") {
+		block := doc.Block(name)
+		if block == nil {
+			return nil, fmt.Errorf("loan %s not found", name)
+		}
+		loan := Loan{Name: name}
+		loan.FromProperties(block.Properties)
+		loans = append(loans, loan)
+	}
+	return loans, nil
+}
+
+// PayOffLoan repays what is left of the loan named name from the money
+// account, and removes the loan.
+func PayOffLoan(doc *sii.Document, name string) error {
+	return payOffLoans(doc, name)
+}
+
+// PayOffAllLoans repays every loan of the player from the money account.
+func PayOffAllLoans(doc *sii.Document) error {
+	loans, err := ListLoans(doc)
+	if err != nil {
+		return err
+	}
+	if len(loans) == 0 {
+		return fmt.Errorf("no loan to pay off")
+	}
+	names := make([]string, len(loans))
+	for i, l := range loans {
+		names[i] = l.Name
+	}
+	return payOffLoans(doc, names...)
+}
+
+func payOffLoans(doc *sii.Document, names ...string) error {
+	loans, err := ListLoans(doc)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]Loan, len(loans))
+	for _, l := range loans {
+		byName[l.Name] = l
+	}
+	var total int64
+	for _, name := range names {
+		l, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("loan %s not found", name)
+		}
+		total += int64(l.Amount)
+	}
+
+	bankBlock := doc.FirstBlockByType("bank")
+	var bank items.Bank
+	if err := bank.FromProperties(bankBlock.Properties); err != nil {
+		return fmt.Errorf("load bank: %w", err)
+	}
+	if bank.MoneyAccount < total {
+		return fmt.Errorf("not enough money to pay off %d (%d available)", total, bank.MoneyAccount)
+	}
+
+	// Dropping the loans from bank.loans goes with the units
+	doc.RemoveCascade(items.Pointers, names...)
+	bank.Loans = sii.GetArray(bankBlock.Properties, "loans")
+	bank.MoneyAccount -= total
+	updateBlockProperties(bankBlock, bank.ToProperties())
+	return nil
+}
+
+// TakeLoan borrows amount at the interest rate (0.05 for 5%) to be
+// repaid over duration days, and credits the money account. Like in the
+// game, the loans of the player may not exceed bank.loan_limit in total. It
+// returns the name of the new loan.
+func TakeLoan(doc *sii.Document, amount int, interestRate float32, duration int) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("invalid loan amount %d", amount)
+	}
+	if err := checkLoanTerms(interestRate, duration); err != nil {
+		return "", err
+	}
+	bankBlock := doc.FirstBlockByType("bank")
+	if bankBlock == nil {
+		return "", fmt.Errorf("bank block not found")
+	}
+	var bank items.Bank
+	if err := bank.FromProperties(bankBlock.Properties); err != nil {
+		return "", fmt.Errorf("load bank: %w", err)
+	}
+
+	loans, err := ListLoans(doc)
+	if err != nil {
+		return "", err
+	}
+	owed := 0
+	for _, l := range loans {
+		owed += l.Amount
+	}
+	if owed+amount > bank.LoanLimit {
+		return "", fmt.Errorf("loan of %d exceeds the limit: %d of %d left", amount, max(bank.LoanLimit-owed, 0), bank.LoanLimit)
+	}
+
+	var gameTime int
+	if econBlock := doc.FirstBlockByType("economy"); econBlock != nil {
+		gameTime = parseIntProp(econBlock.Properties, "game_time")
+	}
+	loan := items.BankLoan{
+		Amount:         amount,
+		OriginalAmount: amount,
+		TimeStamp:      gameTime,
+		InterestRate:   dataformat.Float(interestRate),
+		Duration:       duration,
+	}
+	block := &sii.Block{
+		Type:          "bank_loan",
+		Name:          doc.NewNameless(),
+		Properties:    loan.ToProperties(),
+		PropertyOrder: []string{"amount", "original_amount", "time_stamp", "interest_rate", "duration"},
+	}
+	if err := doc.AddOwned(items.Pointers, bankBlock.Name, "loans", block); err != nil {
+		return "", fmt.Errorf("add loan: %w", err)
+	}
+
+	bank.Loans = sii.GetArray(bankBlock.Properties, "loans")
+	bank.MoneyAccount += int64(amount)
+	updateBlockProperties(bankBlock, bank.ToProperties())
+	return block.Name, nil
+}
+
+// SetLoanTerms changes the interest rate and the duration in days of
+// the loan named name.
+func SetLoanTerms(doc *sii.Document, name string, interestRate float32, duration int) error {
+	if err := checkLoanTerms(interestRate, duration); err != nil {
+		return err
+	}
+	block := doc.Block(name)
+	if block == nil || block.Type != "bank_loan" {
+		return fmt.Errorf("loan %s not found", name)
+	}
+	var loan items.BankLoan
+	loan.FromProperties(block.Properties)
+	loan.InterestRate = dataformat.Float(interestRate)
+	loan.Duration = duration
+	updateBlockProperties(block, loan.ToProperties())
+	return nil
+}
+
+func checkLoanTerms(interestRate float32, duration int) error {
+	if interestRate < 0 || interestRate >= 1 {
+		return fmt.Errorf("invalid interest rate %g", interestRate)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("invalid loan duration %d", duration)
+	}
+	return nil
+}
+
+// parseIntProp returns the first value of key as an int, or 0.
+func parseIntProp(props map[string][]string, key string) int {
+	v, err := sii.ParseInt(firstProp(props, key))
+	if err != nil {
+		return 0
+	}
+	return int(v)
+}
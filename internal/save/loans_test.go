@@ -0,0 +1,88 @@
+package save
+
+import (
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const loanDoc = `SiiNunit
+{
+economy : _nameless.1 {
+ game_time: 4200
+}
+bank : _nameless.2 {
+ money_account: 1000
+ loans: 1
+ loans[0]: _nameless.3
+ loan_limit: 5000
+}
+bank_loan : _nameless.3 {
+ amount: 2000
+ original_amount: 3000
+ time_stamp: 100
+ interest_rate: &3d4ccccd
+ duration: 12
+}
+}
+`
+
+func TestTakeLoan(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(loanDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if _, err := TakeLoan(doc, 3001, 0.05, 12); err == nil {
+		t.Errorf("TakeLoan accepted a loan over the limit")
+	}
+	if _, err := TakeLoan(doc, 1000, 1, 12); err == nil {
+		t.Errorf("TakeLoan accepted an interest rate of 100%%")
+	}
+
+	name, err := TakeLoan(doc, 3000, 0.05, 24)
+	if err != nil {
+		t.Fatalf("TakeLoan: %v", err)
+	}
+	loans, err := ListLoans(doc)
+	if err != nil {
+		t.Fatalf("ListLoans: %v", err)
+	}
+	if len(loans) != 2 || loans[1].Name != name {
+		t.Fatalf("loans = %+v, want the new loan last", loans)
+	}
+	if l := loans[1]; l.Amount != 3000 || l.OriginalAmount != 3000 || l.TimeStamp != 4200 || l.Duration != 24 {
+		t.Errorf("new loan = %+v", l)
+	}
+	if money := firstProp(doc.FirstBlockByType("bank").Properties, "money_account"); money != "4000" {
+		t.Errorf("money_account = %s, want 4000", money)
+	}
+}
+
+func TestPayOffAllLoans(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(loanDoc))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if err := PayOffAllLoans(doc); err == nil {
+		t.Errorf("PayOffAllLoans paid 2000 with 1000 on the account")
+	}
+	doc.FirstBlockByType("bank").Properties["money_account"] = []string{"2500"}
+	if err := PayOffAllLoans(doc); err != nil {
+		t.Fatalf("PayOffAllLoans: %v", err)
+	}
+	bank := doc.FirstBlockByType("bank")
+	if money := firstProp(bank.Properties, "money_account"); money != "500" {
+		t.Errorf("money_account = %s, want 500", money)
+	}
+	if loans := sii.GetArray(bank.Properties, "loans"); len(loans) != 0 {
+		t.Errorf("bank.loans = %v, want none", loans)
+	}
+	if doc.Block("_nameless.3") != nil {
+		t.Errorf("the bank_loan unit was not removed")
+	}
+	if err := PayOffAllLoans(doc); err == nil {
+		t.Errorf("PayOffAllLoans succeeded without loans")
+	}
+}
@@ -3,6 +3,7 @@ package save
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/robebs/ts-se-tool-go/internal/save/items"
@@ -10,20 +11,9 @@ import (
 	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
-// Common truck names for ETS2/ATS
-var commonTrucks = []string{
-	"truck.volvo.fh16",
-	"truck.scania.streamline",
-	"truck.mercedes.actros",
-	"truck.daf.xf",
-	"truck.iveco.hiway",
-	"truck.man.tgx",
-	"truck.renault.magnum",
-}
-
 // SetMoney sets the money amount in the bank block.
 func SetMoney(doc *sii.Document, amount int64) error {
-	bankBlock := findBlockByType(doc, "bank")
+	bankBlock := doc.FirstBlockByType("bank")
 	if bankBlock == nil {
 		return fmt.Errorf("bank block not found")
 	}
@@ -41,7 +31,7 @@ func SetMoney(doc *sii.Document, amount int64) error {
 
 // SetXP sets the experience points in the economy block.
 func SetXP(doc *sii.Document, xp uint32) error {
-	econBlock := findBlockByType(doc, "economy")
+	econBlock := doc.FirstBlockByType("economy")
 	if econBlock == nil {
 		return fmt.Errorf("economy block not found")
 	}
@@ -59,7 +49,7 @@ func SetXP(doc *sii.Document, xp uint32) error {
 
 // SetSkillsMax sets all skills to maximum (255) in the economy block.
 func SetSkillsMax(doc *sii.Document) error {
-	econBlock := findBlockByType(doc, "economy")
+	econBlock := doc.FirstBlockByType("economy")
 	if econBlock == nil {
 		return fmt.Errorf("economy block not found")
 	}
@@ -82,188 +72,174 @@ func SetSkillsMax(doc *sii.Document) error {
 	return nil
 }
 
-// BuyAllGarages adds all available garages to the economy block.
+// BuyAllGarages buys, as tiny garages, every garage of the save and of the
+// world that the player does not own yet.
 func BuyAllGarages(doc *sii.Document, w *world.World) error {
-	econBlock := findBlockByType(doc, "economy")
-	if econBlock == nil {
-		return fmt.Errorf("economy block not found")
-	}
-
-	var econ items.Economy
-	if err := econ.FromProperties(econBlock.Properties); err != nil {
-		return fmt.Errorf("load economy: %w", err)
-	}
-
-	// Get all garage names from world or find all garage blocks
-	garageMap := make(map[string]bool)
-	for _, g := range econ.Garages {
-		garageMap[g] = true
-	}
-
-	// Find all garage blocks in document
-	for _, block := range doc.Blocks {
-		if block.Type == "garage" {
-			if !garageMap[block.Name] {
-				econ.Garages = append(econ.Garages, block.Name)
-				garageMap[block.Name] = true
-			}
-		}
+	var cities []string
+	seen := make(map[string]bool)
+	for _, block := range doc.BlocksByType("garage") {
+		city := strings.TrimPrefix(block.Name, "garage.")
+		cities = append(cities, city)
+		seen[city] = true
 	}
 
 	// Also add garages from world if available
 	if w != nil {
 		for _, g := range w.Garages {
-			garageName := "garage." + g.Name
-			if !garageMap[garageName] {
-				econ.Garages = append(econ.Garages, garageName)
-				garageMap[garageName] = true
+			if !seen[g.Name] {
+				cities = append(cities, g.Name)
+				seen[g.Name] = true
 			}
 		}
 	}
 
-	updateBlockProperties(econBlock, econ.ToProperties())
+	for _, city := range cities {
+		if garageStatus(doc, city) != items.GarageNotOwned {
+			continue
+		}
+		if err := BuyGarage(doc, city, GarageOptions{}); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// UpgradeAllGarages sets all garage status to maximum (2 = fully upgraded).
+// UpgradeAllGarages makes every owned garage a large one.
 func UpgradeAllGarages(doc *sii.Document) error {
 	upgraded := 0
-	for i := range doc.Blocks {
-		if doc.Blocks[i].Type == "garage" {
-			var garage items.Garage
-			if err := garage.FromProperties(doc.Blocks[i].Properties); err != nil {
-				continue
-			}
-
-			// Status 2 = fully upgraded garage
-			garage.Status = 2
-			updateBlockProperties(&doc.Blocks[i], garage.ToProperties())
-			upgraded++
+	for _, block := range doc.BlocksByType("garage") {
+		city := strings.TrimPrefix(block.Name, "garage.")
+		if garageStatus(doc, city) == items.GarageNotOwned {
+			continue
+		}
+		if err := SetGarageStatus(doc, city, items.GarageLarge, GarageOptions{}); err != nil {
+			return err
 		}
+		upgraded++
 	}
 
 	if upgraded == 0 {
-		return fmt.Errorf("no garage blocks found")
+		return fmt.Errorf("no owned garage found")
 	}
 
 	return nil
 }
 
-// PopulateGaragesWithTrucks adds random trucks to all garages.
-func PopulateGaragesWithTrucks(doc *sii.Document, w *world.World) error {
-	// Get available trucks
-	trucks := getAvailableTrucks(w)
-
-	if len(trucks) == 0 {
-		// Fallback to common trucks
-		trucks = commonTrucks
+// PopulateGaragesWithTrucks fills the empty truck slots of every owned
+// garage with new trucks. Each one is a copy of a random truck of the player
+// (see AddTruck). When the player owns none, they are built from the truck
+// settings stock instead, such as those of StockTruck (see NewTruck).
+func PopulateGaragesWithTrucks(doc *sii.Document, w *world.World, stock []byte) error {
+	var templates []string
+	for _, name := range getAvailableTrucks(doc, w) {
+		if b := doc.Block(name); b != nil && b.Type == "vehicle" {
+			templates = append(templates, name)
+		}
+	}
+	if len(templates) == 0 && stock == nil {
+		return fmt.Errorf("no truck to copy: the player owns no truck")
 	}
 
 	rand.Seed(time.Now().UnixNano())
 	populated := 0
 
-	for i := range doc.Blocks {
-		if doc.Blocks[i].Type == "garage" {
-			var garage items.Garage
-			if err := garage.FromProperties(doc.Blocks[i].Properties); err != nil {
-				continue
-			}
+	for _, block := range doc.BlocksByType("garage") {
+		var garage items.Garage
+		if err := garage.FromProperties(block.Properties); err != nil {
+			continue
+		}
+		slots := items.GarageSlots(garage.Status)
+		if slots == 0 {
+			continue
+		}
 
-			// Garage capacity is typically 5 slots
-			capacity := 5
-			currentCount := len(garage.Vehicles)
+		// Slot arrays always have the size of the garage
+		for len(garage.Vehicles) < slots {
+			garage.Vehicles = append(garage.Vehicles, "")
+		}
+		for len(garage.Drivers) < slots {
+			garage.Drivers = append(garage.Drivers, "")
+		}
 
-			// Add trucks up to capacity
-			for currentCount < capacity {
-				truck := trucks[rand.Intn(len(trucks))]
-				garage.Vehicles = append(garage.Vehicles, truck)
-				currentCount++
+		for i := 0; i < slots; i++ {
+			if garage.Vehicles[i] != "" && garage.Vehicles[i] != "null" {
+				continue
 			}
-
-			updateBlockProperties(&doc.Blocks[i], garage.ToProperties())
-			populated++
+			var truck string
+			var err error
+			if len(templates) > 0 {
+				truck, err = AddTruck(doc, templates[rand.Intn(len(templates))])
+			} else {
+				truck, err = NewTruck(doc, stock)
+			}
+			if err != nil {
+				return fmt.Errorf("garage %s: %w", block.Name, err)
+			}
+			garage.Vehicles[i] = truck
 		}
+
+		updateBlockProperties(block, garage.ToProperties())
+		populated++
 	}
 
 	if populated == 0 {
-		return fmt.Errorf("no garage blocks found")
+		return fmt.Errorf("no owned garage found")
 	}
 
 	return nil
 }
 
-// RecruitEmployeesAndPopulateTrucks creates drivers and assigns them to trucks.
-func RecruitEmployeesAndPopulateTrucks(doc *sii.Document, w *world.World) error {
+// RecruitEmployeesAndPopulateTrucks hires a driver for every truck parked in
+// a garage without one. Drivers are hired from the driver pool of the save,
+// then named from opts.Names; they live in a city of one of the player's
+// garages and get skills following opts.Skills, and each comes with its own
+// job and profit log.
+func RecruitEmployeesAndPopulateTrucks(doc *sii.Document, w *world.World, opts RecruitOptions) error {
 	rand.Seed(time.Now().UnixNano())
 
-	// Get player block to add drivers
-	playerBlock := findBlockByType(doc, "player")
-	if playerBlock == nil {
-		return fmt.Errorf("player block not found")
+	r, err := newRecruiter(doc, opts)
+	if err != nil {
+		return err
 	}
 
-	var player items.Player
-	if err := player.FromProperties(playerBlock.Properties); err != nil {
-		return fmt.Errorf("load player: %w", err)
-	}
-
-	// Get available trucks
-	trucks := getAvailableTrucks(w)
-	if len(trucks) == 0 {
-		trucks = commonTrucks
-	}
-
-	driverCounter := 1
 	driversCreated := 0
 
 	// Process all garages
-	for i := range doc.Blocks {
-		if doc.Blocks[i].Type == "garage" {
-			var garage items.Garage
-			if err := garage.FromProperties(doc.Blocks[i].Properties); err != nil {
+	for _, block := range doc.BlocksByType("garage") {
+		var garage items.Garage
+		if err := garage.FromProperties(block.Properties); err != nil {
+			continue
+		}
+		city := strings.TrimPrefix(block.Name, "garage.")
+
+		// Assign drivers to trucks in this garage
+		for j, truck := range garage.Vehicles {
+			if truck == "" || truck == "null" {
 				continue
 			}
 
-			// Assign drivers to trucks in this garage
-			for j, truck := range garage.Vehicles {
-				if truck == "" || truck == "null" {
-					continue
-				}
-
-				// Check if driver already assigned
-				if j < len(garage.Drivers) && garage.Drivers[j] != "" && garage.Drivers[j] != "null" {
-					continue
-				}
-
-				// Create new driver
-				driverName := fmt.Sprintf("driver.name_%04d", driverCounter)
-				driverCounter++
-
-				// Create driver_ai block
-				driverBlock := createDriverAIBlock(driverName, truck)
-				doc.Blocks = append(doc.Blocks, driverBlock)
-
-				// Ensure drivers array is large enough
-				for len(garage.Drivers) <= j {
-					garage.Drivers = append(garage.Drivers, "")
-				}
-				garage.Drivers[j] = driverName
+			// Check if driver already assigned
+			if j < len(garage.Drivers) && garage.Drivers[j] != "" && garage.Drivers[j] != "null" {
+				continue
+			}
 
-				// Add to player's drivers list
-				player.Drivers = append(player.Drivers, driverName)
-				player.DriverReadinessTimer = append(player.DriverReadinessTimer, 0)
-				player.DriverQuitWarned = append(player.DriverQuitWarned, false)
+			driverName, err := r.recruit(truck, city)
+			if err != nil {
+				return fmt.Errorf("garage %s: %w", block.Name, err)
+			}
 
-				driversCreated++
+			// Ensure drivers array is large enough
+			for len(garage.Drivers) <= j {
+				garage.Drivers = append(garage.Drivers, "")
 			}
+			garage.Drivers[j] = driverName
 
-			updateBlockProperties(&doc.Blocks[i], garage.ToProperties())
+			driversCreated++
 		}
-	}
 
-	// Update player block
-	updateBlockProperties(playerBlock, player.ToProperties())
+		updateBlockProperties(block, garage.ToProperties())
+	}
 
 	if driversCreated == 0 {
 		return fmt.Errorf("no trucks found to assign drivers")
@@ -274,21 +250,16 @@ func RecruitEmployeesAndPopulateTrucks(doc *sii.Document, w *world.World) error
 
 // Helper functions
 
-func findBlockByType(doc *sii.Document, blockType string) *sii.Block {
-	for i := range doc.Blocks {
-		if doc.Blocks[i].Type == blockType {
-			return &doc.Blocks[i]
-		}
-	}
-	return nil
-}
-
-func getAvailableTrucks(w *world.World) []string {
+// getAvailableTrucks returns the names of the player's vehicles, from the
+// world when available and from the player block otherwise.
+func getAvailableTrucks(doc *sii.Document, w *world.World) []string {
 	var trucks []string
 
 	if w != nil && w.Player != nil {
 		// Get trucks from player
 		trucks = append(trucks, w.Player.Trucks...)
+	} else if p := doc.FirstBlockByType("player"); p != nil {
+		trucks = sii.GetArray(p.Properties, "trucks")
 	}
 
 	// Also check world's player trucks map
@@ -312,7 +283,8 @@ func getAvailableTrucks(w *world.World) []string {
 }
 
 // updateBlockProperties updates block properties while preserving the original property order.
-// New properties are appended to the end of the order.
+// New properties are appended to the end of the order, except new elements of
+// arrays already in it, which the writer keeps next to their array.
 func updateBlockProperties(block *sii.Block, newProps map[string][]string) {
 	// Preserve existing property order
 	existingOrder := block.PropertyOrder
@@ -327,7 +299,10 @@ func updateBlockProperties(block *sii.Block, newProps map[string][]string) {
 	}
 
 	// Add new properties to order if they don't exist
-	for k := range newProps {
+	for _, k := range sii.SortedKeys(newProps) {
+		if i := strings.IndexByte(k, '['); i > 0 && seen[k[:i]] {
+			continue
+		}
 		if !seen[k] {
 			existingOrder = append(existingOrder, k)
 			seen[k] = true
@@ -339,22 +314,37 @@ func updateBlockProperties(block *sii.Block, newProps map[string][]string) {
 	block.PropertyOrder = existingOrder
 }
 
-func createDriverAIBlock(name, truck string) sii.Block {
+// patchBlockProperties sets the properties of block found in newProps, for
+// item types that do not know every property of their block. Properties
+// newProps lacks are kept and those block lacks are not added, so that a
+// save of an older game version keeps its format.
+func patchBlockProperties(block *sii.Block, newProps map[string][]string) {
+	for k, vals := range newProps {
+		if _, ok := block.Properties[k]; ok {
+			block.Properties[k] = vals
+		}
+	}
+}
+
+// createDriverAIBlock returns a driver_ai assigned to truck. levels holds
+// the skills as stored (ADR as a mask).
+func createDriverAIBlock(name, truck, hometown, city string, levels map[string]int, xp int) *sii.Block {
 	driver := items.DriverAI{
-		Adr:                     50, // Default skill values
-		LongDist:                50,
-		Heavy:                   50,
-		Fragile:                 50,
-		Urgent:                  50,
-		Mechanical:              50,
-		Hometown:                "berlin", // Default city
-		CurrentCity:             "berlin",
+		Adr:                     uint8(levels["adr"]),
+		LongDist:                uint8(levels["long_dist"]),
+		Heavy:                   uint8(levels["heavy"]),
+		Fragile:                 uint8(levels["fragile"]),
+		Urgent:                  uint8(levels["urgent"]),
+		Mechanical:              uint8(levels["mechanical"]),
+		Hometown:                hometown,
+		CurrentCity:             city,
 		State:                   0,
 		OnDutyTimer:             0,
 		ExtraMaintenance:        0,
-		DriverJob:               "",
-		ExperiencePoints:        1000,
+		DriverJob:               sii.Null,
+		ExperiencePoints:        xp,
 		TrainingPolicy:          0,
+		AdoptedTruck:            sii.Null,
 		AssignedTruck:           truck,
 		AssignedTruckEfficiency: 1.0,
 		AssignedTruckAxleCount:  2,
@@ -362,24 +352,16 @@ func createDriverAIBlock(name, truck string) sii.Block {
 		SlotTruckEfficiency:     1.0,
 		SlotTruckAxleCount:      2,
 		SlotTruckMass:           0.0,
-		AdoptedTrailer:          "",
-		AssignedTrailer:         "",
-		OldHometown:             "",
-		ProfitLog:               "",
-	}
-
-	props := driver.ToProperties()
-	// For new blocks, set PropertyOrder based on the order from ToProperties()
-	// (Go map iteration order is random, so we extract keys in order)
-	var order []string
-	for k := range props {
-		order = append(order, k)
+		AdoptedTrailer:          sii.Null,
+		AssignedTrailer:         sii.Null,
+		OldHometown:             `""`,
+		ProfitLog:               sii.Null,
 	}
 
-	return sii.Block{
+	return &sii.Block{
 		Type:          "driver_ai",
 		Name:          name,
-		Properties:    props,
-		PropertyOrder: order,
+		Properties:    driver.ToProperties(),
+		PropertyOrder: append([]string(nil), driverAIOrder...),
 	}
 }
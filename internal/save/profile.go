@@ -1,13 +1,11 @@
 package save
 
 import (
-	"encoding/binary"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/robebs/ts-se-tool-go/internal/sii"
-	"github.com/robebs/ts-se-tool-go/internal/siidecrypt"
 	"github.com/robebs/ts-se-tool-go/internal/util"
 )
 
@@ -30,7 +28,7 @@ func RenameProfile(oldProfileDir, newProfileName, profilesRoot string) (string,
 	}
 
 	// Step 4: Load and update profile.sii
-	profileDoc, err := LoadProfileDataFile(newProfileDir)
+	profileDoc, format, err := decodeSiiDocument(filepath.Join(newProfileDir, "profile.sii"))
 	if err != nil {
 		// Clean up on error
 		_ = os.RemoveAll(newProfileDir)
@@ -42,26 +40,9 @@ func RenameProfile(oldProfileDir, newProfileName, profilesRoot string) (string,
 	// We'll update all blocks that might contain the profile name
 	updated := updateProfileNameInDocument(profileDoc, newProfileName)
 
-	// Step 5: Write updated profile.sii
-	// Determine if original was encrypted by checking if it exists and trying to read it
-	encrypt := false
-	originalProfilePath := filepath.Join(oldProfileDir, "profile.sii")
-	if _, err := os.Stat(originalProfilePath); err == nil {
-		// Try to detect encryption by reading first 4 bytes
-		firstBytes := make([]byte, 4)
-		if f, err := os.Open(originalProfilePath); err == nil {
-			if n, _ := f.Read(firstBytes); n == 4 {
-				sig := binary.LittleEndian.Uint32(firstBytes)
-				if sig == uint32(siidecrypt.SignatureEncrypted) {
-					encrypt = true
-				}
-			}
-			f.Close()
-		}
-	}
-
+	// Step 5: Write updated profile.sii, keeping the original format
 	if updated {
-		if err := WriteProfileSII(newProfileDir, profileDoc, encrypt); err != nil {
+		if err := WriteProfileSII(newProfileDir, profileDoc, format); err != nil {
 			// Clean up on error
 			_ = os.RemoveAll(newProfileDir)
 			return "", fmt.Errorf("write updated profile.sii: %w", err)
@@ -95,7 +76,7 @@ func CloneProfile(sourceProfileDir, newProfileName, profilesRoot string) (string
 	}
 
 	// Step 4: Load and update profile.sii
-	profileDoc, err := LoadProfileDataFile(newProfileDir)
+	profileDoc, format, err := decodeSiiDocument(filepath.Join(newProfileDir, "profile.sii"))
 	if err != nil {
 		// Clean up on error
 		_ = os.RemoveAll(newProfileDir)
@@ -105,25 +86,9 @@ func CloneProfile(sourceProfileDir, newProfileName, profilesRoot string) (string
 	// Update profile name in the document
 	updated := updateProfileNameInDocument(profileDoc, newProfileName)
 
-	// Step 5: Write updated profile.sii
-	// Determine if original was encrypted
-	encrypt := false
-	originalProfilePath := filepath.Join(sourceProfileDir, "profile.sii")
-	if _, err := os.Stat(originalProfilePath); err == nil {
-		firstBytes := make([]byte, 4)
-		if f, err := os.Open(originalProfilePath); err == nil {
-			if n, _ := f.Read(firstBytes); n == 4 {
-				sig := binary.LittleEndian.Uint32(firstBytes)
-				if sig == uint32(siidecrypt.SignatureEncrypted) {
-					encrypt = true
-				}
-			}
-			f.Close()
-		}
-	}
-
+	// Step 5: Write updated profile.sii, keeping the original format
 	if updated {
-		if err := WriteProfileSII(newProfileDir, profileDoc, encrypt); err != nil {
+		if err := WriteProfileSII(newProfileDir, profileDoc, format); err != nil {
 			// Clean up on error
 			_ = os.RemoveAll(newProfileDir)
 			return "", fmt.Errorf("write updated profile.sii: %w", err)
@@ -138,8 +103,7 @@ func CloneProfile(sourceProfileDir, newProfileName, profilesRoot string) (string
 // Returns true if any updates were made.
 func updateProfileNameInDocument(doc *sii.Document, newName string) bool {
 	updated := false
-	for i := range doc.Blocks {
-		block := &doc.Blocks[i]
+	for _, block := range doc.Blocks {
 		// Common property names that might contain the profile name
 		if vals, ok := block.Properties["name"]; ok && len(vals) > 0 {
 			block.Properties["name"] = []string{newName}
@@ -25,20 +25,29 @@ type Documents struct {
 	Profile *sii.Document
 	Info    *sii.Document
 	Game    *sii.Document
+
+	// Formats recorded at load time (outer container + inner encoding).
+	// WriteSaveFile writes each file back in the same format.
+	ProfileFormat siidecrypt.FileFormat
+	InfoFormat    siidecrypt.FileFormat
+	GameFormat    siidecrypt.FileFormat
 }
 
 // decodeSiiDocument decrypts (if needed) and parses a single SII file into
-// a generic sii.Document.
-func decodeSiiDocument(path string) (*sii.Document, error) {
-	plain, err := siidecrypt.DecryptFile(path, true)
+// a generic sii.Document, and reports the format the file was stored in.
+// Text files are read losslessly so that writing them back only touches the
+// lines that changed; BSII files have no text layout worth keeping.
+func decodeSiiDocument(path string) (*sii.Document, siidecrypt.FileFormat, error) {
+	plain, format, err := siidecrypt.DecodeFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+		return nil, format, fmt.Errorf("decrypt %s: %w", path, err)
 	}
-	doc, err := sii.ReadDocument(plain)
+	lossless := format.Encoding != siidecrypt.SignatureBinary
+	doc, err := sii.ReadDocumentWithOptions(plain, sii.ReadOptions{Lossless: lossless})
 	if err != nil {
-		return nil, fmt.Errorf("parse %s: %w", path, err)
+		return nil, format, fmt.Errorf("parse %s: %w", path, err)
 	}
-	return doc, nil
+	return doc, format, nil
 }
 
 // LoadProfileDataFile is a Go analogue of the C# LoadProfileDataFile method,
@@ -46,59 +55,73 @@ func decodeSiiDocument(path string) (*sii.Document, error) {
 // profile.sii and returns a generic SII document.
 func LoadProfileDataFile(profileDir string) (*sii.Document, error) {
 	path := filepath.Join(profileDir, "profile.sii")
-	return decodeSiiDocument(path)
+	doc, _, err := decodeSiiDocument(path)
+	return doc, err
 }
 
 // LoadSaveFile is a data-only variant of the C# LoadSaveFile method. It
 // decodes profile.sii, info.sii and game.sii for a given profile directory
-// and save slot, and returns them as parsed SII documents.
+// and save slot, and returns them as parsed SII documents along with the
+// format of each file.
 func LoadSaveFile(profileDir, slot string) (*Documents, error) {
 	profilePath := filepath.Join(profileDir, "profile.sii")
 	saveDir := filepath.Join(profileDir, "save", slot)
 	infoPath := filepath.Join(saveDir, "info.sii")
 	gamePath := filepath.Join(saveDir, "game.sii")
 
-	profileDoc, err := decodeSiiDocument(profilePath)
-	if err != nil {
+	docs := &Documents{}
+	var err error
+	if docs.Profile, docs.ProfileFormat, err = decodeSiiDocument(profilePath); err != nil {
 		return nil, err
 	}
-	infoDoc, err := decodeSiiDocument(infoPath)
-	if err != nil {
+	if docs.Info, docs.InfoFormat, err = decodeSiiDocument(infoPath); err != nil {
 		return nil, err
 	}
-	gameDoc, err := decodeSiiDocument(gamePath)
-	if err != nil {
+	if docs.Game, docs.GameFormat, err = decodeSiiDocument(gamePath); err != nil {
 		return nil, err
 	}
-
-	return &Documents{
-		Profile: profileDoc,
-		Info:    infoDoc,
-		Game:    gameDoc,
-	}, nil
+	return docs, nil
 }
 
 // WriteSaveFile writes all three SII files (profile.sii, info.sii, game.sii) for a given
-// profile directory and save slot. This is a convenience function that calls the individual
-// write functions. If encrypt is true, files are written in encrypted format.
-func WriteSaveFile(profileDir, slot string, docs *Documents, encrypt bool) error {
+// profile directory and save slot, each in the format it was loaded from.
+func WriteSaveFile(profileDir, slot string, docs *Documents) error {
+	return writeSaveFile(profileDir, slot, docs, docs.ProfileFormat, docs.InfoFormat, docs.GameFormat)
+}
+
+// WriteSaveFileAs is WriteSaveFile with an explicit format for all three
+// files, e.g. to turn an encrypted binary save into plaintext. A binary
+// format without a schema reuses the schema recorded for each file, if any.
+func WriteSaveFileAs(profileDir, slot string, docs *Documents, format siidecrypt.FileFormat) error {
+	withSchema := func(recorded siidecrypt.FileFormat) siidecrypt.FileFormat {
+		f := format
+		if f.Encoding == siidecrypt.SignatureBinary && f.Schema == nil {
+			f.Schema = recorded.Schema
+		}
+		return f
+	}
+	return writeSaveFile(profileDir, slot, docs,
+		withSchema(docs.ProfileFormat), withSchema(docs.InfoFormat), withSchema(docs.GameFormat))
+}
+
+func writeSaveFile(profileDir, slot string, docs *Documents, profileFormat, infoFormat, gameFormat siidecrypt.FileFormat) error {
 	// Write profile.sii
 	if docs.Profile != nil {
-		if err := WriteProfileSII(profileDir, docs.Profile, encrypt); err != nil {
+		if err := WriteProfileSII(profileDir, docs.Profile, profileFormat); err != nil {
 			return fmt.Errorf("write profile.sii: %w", err)
 		}
 	}
 
 	// Write game.sii and info.sii
 	if docs.Game != nil {
-		if err := WriteGameSII(profileDir, slot, docs.Game, encrypt); err != nil {
+		if err := WriteGameSII(profileDir, slot, docs.Game, gameFormat); err != nil {
 			return fmt.Errorf("write game.sii: %w", err)
 		}
 	}
 
 	if docs.Info != nil {
 		saveDir := filepath.Join(profileDir, "save", slot)
-		if err := WriteInfoSII(saveDir, docs.Info, encrypt); err != nil {
+		if err := WriteInfoSII(saveDir, docs.Info, infoFormat); err != nil {
 			return fmt.Errorf("write info.sii: %w", err)
 		}
 	}
@@ -0,0 +1,339 @@
+package save
+
+import "strings"
+
+// StockTruck returns the settings of a stock truck of gameType ("ETS2" or
+// "ATS") for NewTruck, when the player has no truck to copy, or nil when no
+// stock truck is known for the game.
+func StockTruck(gameType string) []byte {
+	if text, ok := stockTrucks[strings.ToUpper(gameType)]; ok {
+		return []byte(text)
+	}
+	return nil
+}
+
+// stockTrucks hold, by game type, a truck as written by CopyTruckSettings
+// with TruckAll. The ETS2 one is a DAF XF 6x4 taken from a save of the game.
+var stockTrucks = map[string]string{
+	"ETS2": stockTruckETS2,
+}
+
+const stockTruckETS2 = `SiiNunit
+{
+vehicle : _nameless.1 {
+ engine_wear: 0
+ transmission_wear: 0
+ cabin_wear: 0
+ engine_wear_unfixable: 0
+ transmission_wear_unfixable: 0
+ cabin_wear_unfixable: 0
+ fuel_relative: 1
+ rheostat_factor: &3f400000
+ user_mirror_rot: 7
+ user_mirror_rot[0]: (1; 0, 0, 0)
+ user_mirror_rot[1]: (1; 0, 0, 0)
+ user_mirror_rot[2]: (1; 0, 0, 0)
+ user_mirror_rot[3]: (1; 0, 0, 0)
+ user_mirror_rot[4]: (1; 0, 0, 0)
+ user_mirror_rot[5]: (1; 0, 0, 0)
+ user_mirror_rot[6]: (1; 0, 0, 0)
+ user_head_offset: (0, 0, 0)
+ user_fov: 0
+ user_wheel_up_down: 0
+ user_wheel_front_back: 0
+ user_mouse_left_right_default: 0
+ user_mouse_up_down_default: 0
+ accessories: 37
+ accessories[0]: _nameless.10
+ accessories[1]: _nameless.11
+ accessories[2]: _nameless.12
+ accessories[3]: _nameless.13
+ accessories[4]: _nameless.14
+ accessories[5]: _nameless.15
+ accessories[6]: _nameless.16
+ accessories[7]: _nameless.17
+ accessories[8]: _nameless.18
+ accessories[9]: _nameless.19
+ accessories[10]: _nameless.1a
+ accessories[11]: _nameless.1b
+ accessories[12]: _nameless.1c
+ accessories[13]: _nameless.1d
+ accessories[14]: _nameless.1e
+ accessories[15]: _nameless.1f
+ accessories[16]: _nameless.20
+ accessories[17]: _nameless.21
+ accessories[18]: _nameless.22
+ accessories[19]: _nameless.23
+ accessories[20]: _nameless.24
+ accessories[21]: _nameless.25
+ accessories[22]: _nameless.26
+ accessories[23]: _nameless.27
+ accessories[24]: _nameless.28
+ accessories[25]: _nameless.29
+ accessories[26]: _nameless.2a
+ accessories[27]: _nameless.2b
+ accessories[28]: _nameless.2c
+ accessories[29]: _nameless.2d
+ accessories[30]: _nameless.2e
+ accessories[31]: _nameless.2f
+ accessories[32]: _nameless.30
+ accessories[33]: _nameless.31
+ accessories[34]: _nameless.32
+ accessories[35]: _nameless.33
+ accessories[36]: _nameless.34
+ odometer: 0
+ odometer_float_part: &38a07cdf
+ integrity_odometer: 0
+ integrity_odometer_float_part: &36909dd9
+ trip_fuel_l: 0
+ trip_fuel: 0
+ trip_recuperation_kwh: 0
+ trip_recuperation: 0
+ trip_distance_km: 0
+ trip_distance: 0
+ trip_time_min: 0
+ trip_time: 0
+ license_plate: "XW 625|germany"
+ chassis_wear: 0
+ chassis_wear_unfixable: 0
+ wheels_wear: 3
+ wheels_wear[0]: 0
+ wheels_wear[1]: 0
+ wheels_wear[2]: 0
+ wheels_wear_unfixable: 3
+ wheels_wear_unfixable[0]: 0
+ wheels_wear_unfixable[1]: 0
+ wheels_wear_unfixable[2]: 0
+ sliding_axle_offset: 0
+}
+vehicle_accessory : _nameless.10 {
+ data_path: "/def/vehicle/truck/daf.xf/data.sii"
+ refund: 0
+}
+vehicle_accessory : _nameless.11 {
+ data_path: "/def/vehicle/truck/daf.xf/head_light/standard.sii"
+ refund: 180
+}
+vehicle_wheel_accessory : _nameless.12 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/f_disc/front_disc_02_matt_gray.sii"
+ refund: 1200
+}
+vehicle_wheel_accessory : _nameless.13 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/f_hub/front_hub_01.sii"
+ refund: 600
+}
+vehicle_wheel_accessory : _nameless.14 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/f_nuts/front_nuts_01_steel.sii"
+ refund: 450
+}
+vehicle_wheel_accessory : _nameless.15 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_tire/1.sii"
+ refund: 3640
+}
+vehicle_wheel_accessory : _nameless.16 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_disc/rear_disc_01_matt_gray.sii"
+ refund: 4800
+}
+vehicle_wheel_accessory : _nameless.17 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_hub/rear_hub_01.sii"
+ refund: 675
+}
+vehicle_wheel_accessory : _nameless.18 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_nuts/rear_nuts_01_steel.sii"
+ refund: 450
+}
+vehicle_addon_accessory : _nameless.19 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/doorstep/shape1.sii"
+ refund: 1860
+}
+vehicle_addon_accessory : _nameless.1a {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/doorhndl/shape1.sii"
+ refund: 480
+}
+vehicle_addon_accessory : _nameless.1b {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/mirror/shape1.sii"
+ refund: 3360
+}
+vehicle_addon_accessory : _nameless.1c {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/s_mirror/shape1.sii"
+ refund: 570
+}
+vehicle_addon_accessory : _nameless.1d {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/exhaust_l/shape14.sii"
+ refund: 1230
+}
+vehicle_addon_accessory : _nameless.1e {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/f_intake_cab/stock.sii"
+ refund: 6450
+}
+vehicle_addon_accessory : _nameless.1f {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/f_intake_chs/stock.sii"
+ refund: 0
+}
+vehicle_addon_accessory : _nameless.20 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/r_mudflap/stock.sii"
+ refund: 225
+}
+vehicle_addon_accessory : _nameless.21 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/steering_w/standard.sii"
+ refund: 1200
+}
+vehicle_addon_accessory : _nameless.22 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/f_badge/stock.sii"
+ refund: 150
+}
+vehicle_addon_accessory : _nameless.23 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/f_fender/stock.sii"
+ refund: 6400
+}
+vehicle_addon_accessory : _nameless.24 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/f_wnd_frame/stock.sii"
+ refund: 720
+}
+vehicle_addon_accessory : _nameless.25 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/trlr_cables/stock.sii"
+ refund: 0
+}
+vehicle_addon_accessory : _nameless.26 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/r_chs_cover/stock.sii"
+ refund: 3200
+}
+vehicle_addon_accessory : _nameless.27 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/s_equip/sidesteps.sii"
+ refund: 0
+}
+vehicle_accessory : _nameless.28 {
+ data_path: "/def/vehicle/truck/daf.xf/cabin/super_space_cab.sii"
+ refund: 60600
+}
+vehicle_accessory : _nameless.29 {
+ data_path: "/def/vehicle/truck/daf.xf/chassis/6x4.sii"
+ refund: 53880
+}
+vehicle_addon_accessory : _nameless.2a {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/r_bumper/stock_6x_lng.sii"
+ refund: 3363
+}
+vehicle_wheel_accessory : _nameless.2b {
+ offset: 2
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_tire/1.sii"
+ refund: 3640
+}
+vehicle_wheel_accessory : _nameless.2c {
+ offset: 2
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_disc/rear_disc_01_matt_gray.sii"
+ refund: 4800
+}
+vehicle_wheel_accessory : _nameless.2d {
+ offset: 2
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_hub/rear_hub_01.sii"
+ refund: 675
+}
+vehicle_wheel_accessory : _nameless.2e {
+ offset: 2
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/r_nuts/rear_nuts_01_steel.sii"
+ refund: 450
+}
+vehicle_accessory : _nameless.2f {
+ data_path: "/def/vehicle/truck/daf.xf/engine/mx375.sii"
+ refund: 19200
+}
+vehicle_addon_accessory : _nameless.30 {
+ slot_name: 0
+ slot_hookup: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/accessory/badge/bdg_510.sii"
+ refund: 0
+}
+vehicle_accessory : _nameless.31 {
+ data_path: "/def/vehicle/truck/daf.xf/transmission/12_speed_ret_over.sii"
+ refund: 17000
+}
+vehicle_accessory : _nameless.32 {
+ data_path: "/def/vehicle/truck/daf.xf/interior/exclusive.sii"
+ refund: 33900
+}
+vehicle_wheel_accessory : _nameless.33 {
+ offset: 0
+ paint_color: (1, 1, 1)
+ data_path: "/def/vehicle/f_tire/1.sii"
+ refund: 1430
+}
+vehicle_paint_job_accessory : _nameless.34 {
+ mask_r_color: (1, 0, 0)
+ mask_g_color: (0, 1, 0)
+ mask_b_color: (0, 0, 1)
+ flake_color: (0, 1, 0)
+ flip_color: (1, 0, 0)
+ base_color: (1, 1, 1)
+ data_path: "/def/vehicle/truck/daf.xf/paint_job/color0.sii"
+ refund: 5200
+}
+}
+`
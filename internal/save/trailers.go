@@ -0,0 +1,202 @@
+package save
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// Trailer is a trailer of the save with the name of its trailer unit.
+// Trailers pulled by another one (slave_trailer, as in B-doubles) are listed
+// in the Slaves of the first trailer of the combination.
+type Trailer struct {
+	Name string
+	items.Trailer
+
+	// Definition is the trailer definition, such as
+	// "trailer_def.scs.box.single_3.dryvan", also when trailer_definition
+	// points at a trailer_def unit of the save.
+	Definition string
+	Slaves     []string
+
+	// Owned tells trailers of the player (player.trailers) from company
+	// trailers, such as the one of the current job.
+	Owned bool
+}
+
+// ListTrailers returns every trailer of the save, those of the player
+// first in the order of player.trailers.
+func ListTrailers(doc *sii.Document) ([]Trailer, error) {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return nil, fmt.Errorf("player block not found")
+	}
+	owned := sii.GetArray(playerBlock.Properties, "trailers")
+	isOwned := make(map[string]bool, len(owned))
+	for _, name := range owned {
+		isOwned[name] = true
+	}
+
+	names := owned
+	pulled := make(map[string]bool)
+	for _, b := range doc.BlocksByType("trailer") {
+		if slave := firstProp(b.Properties, "slave_trailer"); slave != "" && slave != sii.Null {
+			pulled[slave] = true
+		}
+	}
+	for _, b := range doc.BlocksByType("trailer") {
+		if !isOwned[b.Name] && !pulled[b.Name] {
+			names = append(names, b.Name)
+		}
+	}
+
+	var trailers []Trailer
+	for _, name := range names {
+		block := doc.Block(name)
+		if block == nil || block.Type != "trailer" {
+			return nil, fmt.Errorf("trailer %s not found", name)
+		}
+		t := Trailer{Name: name, Owned: isOwned[name]}
+		if err := t.FromProperties(block.Properties); err != nil {
+			return nil, fmt.Errorf("load trailer %s: %w", name, err)
+		}
+		t.Definition = trailerDefinition(doc, t.TrailerDefinition)
+		chain, err := trailerChain(doc, name)
+		if err != nil {
+			return nil, err
+		}
+		t.Slaves = chain[1:]
+		trailers = append(trailers, t)
+	}
+	return trailers, nil
+}
+
+// trailerDefinition returns the definition name of a trailer_definition
+// value: the source_name of a trailer_def unit, or the value itself.
+func trailerDefinition(doc *sii.Document, def string) string {
+	if b := doc.Block(def); b != nil && b.Type == "trailer_def" {
+		if source := sii.Unquote(firstProp(b.Properties, "source_name")); source != "" {
+			return source
+		}
+	}
+	return def
+}
+
+// trailerChain returns the trailer named name followed by the trailers it
+// pulls, through slave_trailer.
+func trailerChain(doc *sii.Document, name string) ([]string, error) {
+	var chain []string
+	seen := make(map[string]bool)
+	for name != "" && name != sii.Null {
+		block := doc.Block(name)
+		if block == nil || block.Type != "trailer" {
+			return nil, fmt.Errorf("trailer %s not found", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("trailer %s pulls itself", name)
+		}
+		seen[name] = true
+		chain = append(chain, name)
+		name = firstProp(block.Properties, "slave_trailer")
+	}
+	return chain, nil
+}
+
+// RepairTrailer repairs the trailer named name and the trailers it pulls,
+// clearing the wear of their body, chassis and wheels. With unfixable, the
+// wear a service shop cannot repair is cleared too.
+func RepairTrailer(doc *sii.Document, name string, unfixable bool) error {
+	return editTrailers(doc, []string{name}, func(t *items.Trailer) {
+		repairTrailer(t, unfixable)
+	})
+}
+
+// RepairAllTrailers repairs every trailer of the player, see RepairTrailer.
+func RepairAllTrailers(doc *sii.Document, unfixable bool) error {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return fmt.Errorf("player block not found")
+	}
+	owned := sii.GetArray(playerBlock.Properties, "trailers")
+	if len(owned) == 0 {
+		return fmt.Errorf("the player has no trailer")
+	}
+	return editTrailers(doc, owned, func(t *items.Trailer) {
+		repairTrailer(t, unfixable)
+	})
+}
+
+func repairTrailer(t *items.Trailer, unfixable bool) {
+	t.TrailerBodyWear, t.ChassisWear = 0, 0
+	clear(t.WheelsWear)
+	if unfixable {
+		t.TrailerBodyWearUnfixable, t.ChassisWearUnfixable = 0, 0
+		clear(t.WheelsWearUnfixable)
+	}
+}
+
+// ClearCargoDamage clears the cargo damage of the trailers of the current
+// job, be it a company trailer or one of the player.
+func ClearCargoDamage(doc *sii.Document) error {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return fmt.Errorf("player block not found")
+	}
+	jobBlock := doc.Block(firstProp(playerBlock.Properties, "current_job"))
+	if jobBlock == nil {
+		return fmt.Errorf("no current job")
+	}
+	trailer := firstProp(jobBlock.Properties, "company_trailer")
+	if trailer == "" || trailer == sii.Null {
+		trailer = firstProp(playerBlock.Properties, "assigned_trailer")
+	}
+	if trailer == "" || trailer == sii.Null {
+		return fmt.Errorf("the current job has no trailer")
+	}
+	return editTrailers(doc, []string{trailer}, func(t *items.Trailer) {
+		t.CargoDamage = 0
+	})
+}
+
+// SetTrailerLicensePlate changes the license plate of the trailer named
+// name, and of the trailers it pulls, to text on a plate of country. An
+// empty country keeps the current one. See FormatLicensePlate.
+func SetTrailerLicensePlate(doc *sii.Document, name, text, country string) error {
+	if text == "" {
+		return fmt.Errorf("empty license plate")
+	}
+	if strings.Contains(text, "|") {
+		return fmt.Errorf("license plate %q: '|' is not allowed", text)
+	}
+	return editTrailers(doc, []string{name}, func(t *items.Trailer) {
+		plateCountry := country
+		if plateCountry == "" {
+			_, plateCountry = ParseLicensePlate(string(t.LicensePlate))
+		}
+		t.LicensePlate = dataformat.String(FormatLicensePlate(text, plateCountry))
+	})
+}
+
+// editTrailers applies edit to the trailers named names and to the
+// trailers they pull.
+func editTrailers(doc *sii.Document, names []string, edit func(t *items.Trailer)) error {
+	for _, name := range names {
+		chain, err := trailerChain(doc, name)
+		if err != nil {
+			return err
+		}
+		for _, trailer := range chain {
+			block := doc.Block(trailer)
+			var t items.Trailer
+			if err := t.FromProperties(block.Properties); err != nil {
+				return fmt.Errorf("load trailer %s: %w", trailer, err)
+			}
+			edit(&t)
+			patchBlockProperties(block, t.ToProperties())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package save
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// AddTruck creates a new truck for the player by copying the vehicle named
+// template with all its accessories (chassis, cabin, engine, transmission,
+// wheels, paint, plate...), see NewTruck.
+func AddTruck(doc *sii.Document, template string) (string, error) {
+	settings, err := CopyTruckSettings(doc, template, TruckAll)
+	if err != nil {
+		return "", fmt.Errorf("template truck: %w", err)
+	}
+	return NewTruck(doc, settings)
+}
+
+// NewTruck creates a new truck for the player from truck settings, as
+// returned by CopyTruckSettings with TruckAll or StockTruck. The truck is
+// brand new: no wear, a full tank and zeroed odometer and trip counters. Its
+// license plate is changed to one no other truck or trailer of the save
+// has. It is registered in player.trucks with a new profit_log in
+// player.truck_profit_logs, and its name is returned. Placing it in a garage
+// slot is left to the caller.
+func NewTruck(doc *sii.Document, settings []byte) (string, error) {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return "", fmt.Errorf("player block not found")
+	}
+	in, err := sii.ReadDocument(settings)
+	if err != nil {
+		return "", fmt.Errorf("read truck settings: %w", err)
+	}
+	source := in.FirstBlockByType("vehicle")
+	if source == nil {
+		return "", fmt.Errorf("truck settings hold no truck")
+	}
+	accessories, err := pastedAccessories(doc, in, sii.GetArray(source.Properties, "accessories"))
+	if err != nil {
+		return "", err
+	}
+
+	vehicle := copyBlock(source, doc.NewNameless())
+	sii.PutArray(vehicle.Properties, "accessories", nil)
+	resetVehicleState(vehicle.Properties)
+	vehicle.Properties["license_plate"] = []string{uniqueLicensePlate(doc, firstProp(vehicle.Properties, "license_plate"))}
+	if err := doc.AddOwned(items.Pointers, playerBlock.Name, "trucks", vehicle); err != nil {
+		return "", fmt.Errorf("add truck: %w", err)
+	}
+	if err := doc.AddOwned(items.Pointers, vehicle.Name, "accessories", accessories...); err != nil {
+		return "", fmt.Errorf("add accessories: %w", err)
+	}
+
+	if err := doc.AddOwned(items.Pointers, playerBlock.Name, "truck_profit_logs", newProfitLogBlock(doc.NewNameless())); err != nil {
+		return "", fmt.Errorf("add truck profit log: %w", err)
+	}
+	return vehicle.Name, nil
+}
+
+// uniqueLicensePlate returns plate, or plate with other digits when a truck
+// or trailer of doc already has it. The country and the formatting tags of
+// plate are kept; its last number is replaced by a random one of the same
+// length, or one is added when it has none.
+func uniqueLicensePlate(doc *sii.Document, plate string) string {
+	used := make(map[string]bool)
+	for _, typ := range []string{"vehicle", "trailer"} {
+		for _, b := range doc.BlocksByType(typ) {
+			used[sii.Unquote(firstProp(b.Properties, "license_plate"))] = true
+		}
+	}
+	if plate != "" && !used[sii.Unquote(plate)] {
+		return plate
+	}
+
+	text, country := ParseLicensePlate(plate)
+	end := strings.LastIndexFunc(text, isDigit) + 1
+	start := end
+	for start > 0 && isDigit(rune(text[start-1])) {
+		start--
+	}
+	prefix, suffix, digits := text[:start], text[end:], end-start
+	if digits == 0 {
+		prefix, suffix, digits = text, "", 3
+		if text != "" {
+			prefix = strings.TrimRight(text, " ") + " "
+		}
+	}
+	for tries := 0; ; tries++ {
+		// After as many tries as there are numbers, they may all be taken
+		if tries == pow10(digits) {
+			digits, tries = digits+1, 0
+		}
+		number := fmt.Sprintf("%0*d", digits, rand.Intn(pow10(digits)))
+		candidate := FormatLicensePlate(prefix+number+suffix, country)
+		if !used[sii.Unquote(candidate)] {
+			return candidate
+		}
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func pow10(n int) int {
+	p := 1
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
+
+// copyBlock returns a copy of b named name.
+func copyBlock(b *sii.Block, name string) *sii.Block {
+	props := make(map[string][]string, len(b.Properties))
+	for k, vals := range b.Properties {
+		props[k] = append([]string(nil), vals...)
+	}
+	return &sii.Block{
+		Type:          b.Type,
+		Name:          name,
+		Properties:    props,
+		PropertyOrder: append([]string(nil), b.PropertyOrder...),
+	}
+}
+
+// resetVehicleState makes vehicle properties those of a new truck: wear
+// (including the unfixable part), odometers and trip counters are zeroed and
+// the tank is filled.
+func resetVehicleState(props map[string][]string) {
+	for key, vals := range props {
+		base := key
+		if i := strings.IndexByte(key, '['); i > 0 {
+			base = key[:i]
+		} else if _, isArray := props[key+"[0]"]; isArray {
+			continue // element count
+		}
+		if strings.HasSuffix(base, "_wear") || strings.HasSuffix(base, "_wear_unfixable") ||
+			strings.Contains(base, "odometer") || strings.HasPrefix(base, "trip_") {
+			for i := range vals {
+				vals[i] = "0"
+			}
+		}
+	}
+	if _, ok := props["fuel_relative"]; ok {
+		props["fuel_relative"] = []string{"1"}
+	}
+}
+
+// newProfitLogBlock returns an empty profit_log block.
+func newProfitLogBlock(name string) *sii.Block {
+	log := items.ProfitLog{}
+	props := log.ToProperties()
+	return &sii.Block{
+		Type:          "profit_log",
+		Name:          name,
+		Properties:    props,
+		PropertyOrder: []string{"stats_data", "acc_distance_free", "acc_distance_on_job", "history_age"},
+	}
+}
+
+// Truck is a truck of the player with the name of its vehicle unit.
+type Truck struct {
+	Name string
+	items.Vehicle
+}
+
+// ListTrucks returns the trucks of the player, in the order of
+// player.trucks.
+func ListTrucks(doc *sii.Document) ([]Truck, error) {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return nil, fmt.Errorf("player block not found")
+	}
+	var trucks []Truck
+	for _, name := range sii.GetArray(playerBlock.Properties, "trucks") {
+		block := doc.Block(name)
+		if block == nil {
+			return nil, fmt.Errorf("truck %s not found", name)
+		}
+		truck := Truck{Name: name}
+		if err := truck.FromProperties(block.Properties); err != nil {
+			return nil, fmt.Errorf("load truck %s: %w", name, err)
+		}
+		trucks = append(trucks, truck)
+	}
+	return trucks, nil
+}
+
+// RepairTruck repairs the truck named name like a service shop does,
+// clearing the wear of its engine, transmission, cabin, chassis and wheels.
+// With unfixable, the wear a service shop cannot repair is cleared too.
+func RepairTruck(doc *sii.Document, name string, unfixable bool) error {
+	return editTrucks(doc, []string{name}, func(v *items.Vehicle) {
+		repairVehicle(v, unfixable)
+	})
+}
+
+// RepairAllTrucks repairs every truck of the player, see RepairTruck.
+func RepairAllTrucks(doc *sii.Document, unfixable bool) error {
+	return editTrucks(doc, nil, func(v *items.Vehicle) {
+		repairVehicle(v, unfixable)
+	})
+}
+
+func repairVehicle(v *items.Vehicle, unfixable bool) {
+	v.EngineWear, v.TransmissionWear, v.CabinWear, v.ChassisWear = 0, 0, 0, 0
+	clear(v.WheelsWear)
+	if unfixable {
+		v.EngineWearUnfixable, v.TransmissionWearUnfixable = 0, 0
+		v.CabinWearUnfixable, v.ChassisWearUnfixable = 0, 0
+		clear(v.WheelsWearUnfixable)
+	}
+}
+
+// RefuelTruck fills the tank of the truck named name to level, from 0
+// (empty) to 1 (full).
+func RefuelTruck(doc *sii.Document, name string, level float32) error {
+	if level < 0 || level > 1 {
+		return fmt.Errorf("invalid fuel level %g", level)
+	}
+	return editTrucks(doc, []string{name}, func(v *items.Vehicle) {
+		v.FuelRelative = dataformat.Float(level)
+	})
+}
+
+// RefuelAllTrucks fills the tank of every truck of the player to level, see
+// RefuelTruck.
+func RefuelAllTrucks(doc *sii.Document, level float32) error {
+	if level < 0 || level > 1 {
+		return fmt.Errorf("invalid fuel level %g", level)
+	}
+	return editTrucks(doc, nil, func(v *items.Vehicle) {
+		v.FuelRelative = dataformat.Float(level)
+	})
+}
+
+// SetOdometer sets the odometer of the truck named name to km, 0 to clear
+// it. The distance driven since the last repair, which the wear depends on,
+// is capped to it.
+func SetOdometer(doc *sii.Document, name string, km uint32) error {
+	return editTrucks(doc, []string{name}, func(v *items.Vehicle) {
+		v.Odometer, v.OdometerFloatPart = km, 0
+		if v.IntegrityOdometer >= km {
+			v.IntegrityOdometer, v.IntegrityOdometerFloatPart = km, 0
+		}
+	})
+}
+
+// ResetTripStats clears the trip computer of the truck named name: fuel,
+// energy recuperation, distance and time.
+func ResetTripStats(doc *sii.Document, name string) error {
+	return editTrucks(doc, []string{name}, func(v *items.Vehicle) {
+		v.TripFuelL, v.TripFuel = 0, 0
+		v.TripRecuperationKwh, v.TripRecuperation = 0, 0
+		v.TripDistanceKm, v.TripDistance = 0, 0
+		v.TripTimeMin, v.TripTime = 0, 0
+	})
+}
+
+// SetLicensePlate changes the license plate of the truck named name to
+// text, on a plate of country (e.g. "germany"). An empty country keeps the
+// current one. See FormatLicensePlate.
+func SetLicensePlate(doc *sii.Document, name, text, country string) error {
+	if text == "" {
+		return fmt.Errorf("empty license plate")
+	}
+	if strings.Contains(text, "|") {
+		return fmt.Errorf("license plate %q: '|' is not allowed", text)
+	}
+	return editTrucks(doc, []string{name}, func(v *items.Vehicle) {
+		if country == "" {
+			_, country = ParseLicensePlate(string(v.LicensePlate))
+		}
+		v.LicensePlate = dataformat.String(FormatLicensePlate(text, country))
+	})
+}
+
+// FormatLicensePlate returns the license_plate value of a plate showing
+// text on the plate of country: "\"text|country\"". text may hold the
+// formatting tags the game uses, such as <offset> and <img> for the flag
+// and the region of German plates.
+func FormatLicensePlate(text, country string) string {
+	if country == "" {
+		return sii.Quote(text)
+	}
+	return sii.Quote(text + "|" + country)
+}
+
+// ParseLicensePlate splits a license_plate value into the text of the
+// plate, formatting tags included, and its country.
+func ParseLicensePlate(plate string) (text, country string) {
+	plate = sii.Unquote(plate)
+	if i := strings.LastIndexByte(plate, '|'); i >= 0 {
+		return plate[:i], plate[i+1:]
+	}
+	return plate, ""
+}
+
+// LicensePlateText returns the text of a license_plate value as it reads on
+// the plate, without country. Formatting tags, which usually separate parts
+// of the plate, are shown as a space.
+func LicensePlateText(plate string) string {
+	text, _ := ParseLicensePlate(plate)
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '>')
+		if end < 0 {
+			break
+		}
+		b.WriteString(text[:start])
+		b.WriteByte(' ')
+		text = text[start+end+1:]
+	}
+	b.WriteString(text)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// editTrucks applies edit to the trucks named names, or to every truck of
+// the player when names is empty.
+func editTrucks(doc *sii.Document, names []string, edit func(v *items.Vehicle)) error {
+	playerBlock := doc.FirstBlockByType("player")
+	if playerBlock == nil {
+		return fmt.Errorf("player block not found")
+	}
+	owned := sii.GetArray(playerBlock.Properties, "trucks")
+	if len(names) == 0 {
+		if len(owned) == 0 {
+			return fmt.Errorf("the player has no truck")
+		}
+		names = owned
+	}
+	for _, name := range names {
+		block := doc.Block(name)
+		if block == nil || block.Type != "vehicle" || !slices.Contains(owned, name) {
+			return fmt.Errorf("truck %s not found", name)
+		}
+		var v items.Vehicle
+		if err := v.FromProperties(block.Properties); err != nil {
+			return fmt.Errorf("load truck %s: %w", name, err)
+		}
+		edit(&v)
+		patchBlockProperties(block, v.ToProperties())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package save
+
+import (
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// plates returns the license plates of the trucks of the player.
+func plates(t *testing.T, doc *sii.Document) []string {
+	t.Helper()
+	trucks, err := ListTrucks(doc)
+	if err != nil {
+		t.Fatalf("ListTrucks: %v", err)
+	}
+	var out []string
+	for _, truck := range trucks {
+		out = append(out, string(truck.LicensePlate))
+	}
+	return out
+}
+
+func checkUnique(t *testing.T, values []string) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if seen[v] {
+			t.Errorf("%s is used twice in %v", v, values)
+		}
+		seen[v] = true
+	}
+}
+
+func TestAddTruck(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(`SiiNunit
+{
+player : _nameless.3 {
+ trucks: 1
+ trucks[0]: _nameless.1
+ truck_profit_logs: 1
+ truck_profit_logs[0]: _nameless.5
+}
+profit_log : _nameless.5 {
+}
+vehicle : _nameless.1 {
+ accessories: 2
+ accessories[0]: _nameless.10
+ accessories[1]: _nameless.11
+ engine_wear: &3e800000
+ odometer: 1000
+ fuel_relative: &3f000000
+ license_plate: "AB 123|france"
+}
+vehicle_accessory : _nameless.10 {
+ data_path: "/def/vehicle/truck/scania.s_2016/data.sii"
+ refund: 0
+}
+vehicle_paint_job_accessory : _nameless.11 {
+ data_path: "/def/vehicle/truck/scania.s_2016/paint_job/default.sii"
+ refund: 0
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	name, err := AddTruck(doc, "_nameless.1")
+	if err != nil {
+		t.Fatalf("AddTruck: %v", err)
+	}
+	truck := doc.Block(name)
+	for key, want := range map[string]string{"engine_wear": "0", "odometer": "0", "fuel_relative": "1"} {
+		if got := firstProp(truck.Properties, key); got != want {
+			t.Errorf("%s = %s, want %s", key, got, want)
+		}
+	}
+	accessories := sii.GetArray(truck.Properties, "accessories")
+	if len(accessories) != 2 || accessories[0] == "_nameless.10" || accessories[1] == "_nameless.11" {
+		t.Errorf("accessories = %v, want 2 copies", accessories)
+	}
+	got := plates(t, doc)
+	if len(got) != 2 {
+		t.Fatalf("player has %d trucks, want 2", len(got))
+	}
+	if text, country := ParseLicensePlate(got[1]); text == "AB 123" || country != "france" {
+		t.Errorf("license plate of the copy = %s, want another french plate", got[1])
+	}
+	player := doc.FirstBlockByType("player")
+	if logs := sii.GetArray(player.Properties, "truck_profit_logs"); len(logs) != 2 {
+		t.Errorf("truck_profit_logs = %v, want one for each truck", logs)
+	}
+	if refs := doc.DanglingReferences(items.Pointers); len(refs) != 0 {
+		t.Errorf("dangling references: %v", refs)
+	}
+}
+
+func TestPopulateGaragesWithTrucks_Stock(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(`SiiNunit
+{
+player : _nameless.3 {
+ trucks: 0
+ truck_profit_logs: 0
+}
+garage : garage.paris {
+ vehicles: 0
+ drivers: 0
+ trailers: 0
+ status: 2
+ profit_log: null
+ productivity: 0
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if err := PopulateGaragesWithTrucks(doc, nil, nil); err == nil {
+		t.Errorf("PopulateGaragesWithTrucks without truck nor stock succeeded")
+	}
+	if err := PopulateGaragesWithTrucks(doc, nil, StockTruck("ets2")); err != nil {
+		t.Fatalf("PopulateGaragesWithTrucks: %v", err)
+	}
+	garage := loadGarage(t, doc, "paris")
+	if len(garage.Vehicles) != 3 {
+		t.Fatalf("paris has %d truck slots, want 3", len(garage.Vehicles))
+	}
+	for _, v := range garage.Vehicles {
+		if b := doc.Block(v); b == nil || b.Type != "vehicle" {
+			t.Errorf("slot holds %q, want a new truck", v)
+		}
+	}
+	got := plates(t, doc)
+	if len(got) != 3 {
+		t.Fatalf("player has %d trucks, want 3", len(got))
+	}
+	checkUnique(t, got)
+	if refs := doc.DanglingReferences(items.Pointers); len(refs) != 0 {
+		t.Errorf("dangling references: %v", refs)
+	}
+}
+
+func TestUniqueLicensePlate(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(`SiiNunit
+{
+vehicle : _nameless.1 {
+ license_plate: "B<offset hshift=4>XW 625|germany"
+}
+trailer : _nameless.2 {
+ license_plate: "TRAILER|germany"
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if got := uniqueLicensePlate(doc, `"XY 1|germany"`); got != `"XY 1|germany"` {
+		t.Errorf("free plate changed to %s", got)
+	}
+	got := uniqueLicensePlate(doc, `"B<offset hshift=4>XW 625|germany"`)
+	if text, country := ParseLicensePlate(got); len(text) != len("B<offset hshift=4>XW 625") ||
+		text[:len(text)-3] != "B<offset hshift=4>XW " || text == "B<offset hshift=4>XW 625" || country != "germany" {
+		t.Errorf("plate of the copy = %s, want another number", got)
+	}
+	got = uniqueLicensePlate(doc, `"TRAILER|germany"`)
+	if text, _ := ParseLicensePlate(got); len(text) != len("TRAILER 000") || text[:8] != "TRAILER " {
+		t.Errorf("plate without number = %s, want one added", got)
+	}
+}
@@ -0,0 +1,279 @@
+package save
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// TruckSettings selects what CopyTruckSettings and PasteTruckSettings
+// share between trucks.
+type TruckSettings int
+
+const (
+	// TruckAll is the whole truck: its details and all its accessories
+	// (chassis, cabin, engine, transmission, wheels, paint, addons...).
+	TruckAll TruckSettings = iota
+	// TruckPaint is the paint job accessory.
+	TruckPaint
+	// TruckDetails are the properties of the vehicle unit itself: wear,
+	// fuel, odometer, trip counters and driving position. TruckAll and
+	// TruckDetails keep the license plate of the truck they are pasted on.
+	TruckDetails
+	// TruckLicensePlate is the license plate of the vehicle unit.
+	TruckLicensePlate
+)
+
+// truckAccessoryTypes are the unit types a vehicle owns in accessories.
+var truckAccessoryTypes = []string{
+	"vehicle_accessory",
+	"vehicle_paint_job_accessory",
+	"vehicle_wheel_accessory",
+	"vehicle_addon_accessory",
+	"vehicle_sound_accessory",
+	"vehicle_drv_plate_accessory",
+}
+
+// CopyTruckSettings returns what of the truck named name to share, as SII
+// text holding the vehicle unit and the accessory units it owns. The text
+// can be pasted with PasteTruckSettings onto any truck, in any profile.
+func CopyTruckSettings(doc *sii.Document, name string, what TruckSettings) ([]byte, error) {
+	vehicle := doc.Block(name)
+	if vehicle == nil || vehicle.Type != "vehicle" {
+		return nil, fmt.Errorf("truck %s not found", name)
+	}
+	accessories, err := truckAccessories(doc, vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*sii.Block
+	switch what {
+	case TruckAll:
+		blocks = append(blocks, copyBlock(vehicle, vehicle.Name))
+		for _, acc := range accessories {
+			blocks = append(blocks, copyBlock(acc, acc.Name))
+		}
+	case TruckPaint:
+		for _, acc := range accessories {
+			if acc.Type == "vehicle_paint_job_accessory" {
+				blocks = append(blocks, copyBlock(acc, acc.Name))
+			}
+		}
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf("truck %s has no paint job", name)
+		}
+	case TruckDetails, TruckLicensePlate:
+		details := copyBlock(vehicle, vehicle.Name)
+		sii.PutArray(details.Properties, "accessories", nil)
+		blocks = append(blocks, details)
+	default:
+		return nil, fmt.Errorf("invalid truck settings %d", what)
+	}
+
+	out := &sii.Document{}
+	if err := out.Append(blocks...); err != nil {
+		return nil, fmt.Errorf("copy truck %s: %w", name, err)
+	}
+	return sii.WriteDocument(out)
+}
+
+// PasteTruckSettings applies what of settings, as returned by
+// CopyTruckSettings, to the truck named name. Pasted accessories get new
+// nameless names, and references between pasted units follow them; the
+// accessories they replace are removed.
+//
+// A paint job of another truck model is moved to the paint job folder of
+// the model of the truck, which works for the paint jobs every model has,
+// such as the plain colors.
+func PasteTruckSettings(doc *sii.Document, name string, settings []byte, what TruckSettings) error {
+	vehicle := doc.Block(name)
+	if vehicle == nil || vehicle.Type != "vehicle" {
+		return fmt.Errorf("truck %s not found", name)
+	}
+	in, err := sii.ReadDocument(settings)
+	if err != nil {
+		return fmt.Errorf("read truck settings: %w", err)
+	}
+	for _, b := range in.Blocks {
+		if b.Type != "vehicle" && !isTruckAccessory(b.Type) {
+			return fmt.Errorf("truck settings: unexpected %s unit %s", b.Type, b.Name)
+		}
+	}
+	source := in.FirstBlockByType("vehicle")
+
+	switch what {
+	case TruckAll:
+		if source == nil {
+			return fmt.Errorf("truck settings hold no truck")
+		}
+		pasted, err := pastedAccessories(doc, in, sii.GetArray(source.Properties, "accessories"))
+		if err != nil {
+			return err
+		}
+		if len(pasted) == 0 {
+			return fmt.Errorf("truck settings hold no accessory")
+		}
+		doc.RemoveCascade(items.Pointers, sii.GetArray(vehicle.Properties, "accessories")...)
+		pasteVehicleDetails(vehicle, source)
+		sii.PutArray(vehicle.Properties, "accessories", nil)
+		if err := doc.AddOwned(items.Pointers, vehicle.Name, "accessories", pasted...); err != nil {
+			return fmt.Errorf("paste truck: %w", err)
+		}
+	case TruckPaint:
+		var paints []string
+		for _, b := range in.BlocksByType("vehicle_paint_job_accessory") {
+			paints = append(paints, b.Name)
+		}
+		if len(paints) == 0 {
+			return fmt.Errorf("truck settings hold no paint job")
+		}
+		pasted, err := pastedAccessories(doc, in, paints)
+		if err != nil {
+			return err
+		}
+		accessories, err := truckAccessories(doc, vehicle)
+		if err != nil {
+			return err
+		}
+		var old []string
+		for _, acc := range accessories {
+			if acc.Type == "vehicle_paint_job_accessory" {
+				old = append(old, acc.Name)
+			}
+		}
+		if model := truckModelPath(accessories); model != "" {
+			for _, b := range pasted {
+				path := sii.Unquote(firstProp(b.Properties, "data_path"))
+				if rest, ok := cutTruckModelPath(path); ok {
+					b.Properties["data_path"] = []string{sii.Quote(model + rest)}
+				}
+			}
+		}
+		doc.RemoveCascade(items.Pointers, old...)
+		if err := doc.AddOwned(items.Pointers, vehicle.Name, "accessories", pasted...); err != nil {
+			return fmt.Errorf("paste paint job: %w", err)
+		}
+	case TruckDetails:
+		if source == nil {
+			return fmt.Errorf("truck settings hold no truck")
+		}
+		pasteVehicleDetails(vehicle, source)
+	case TruckLicensePlate:
+		if source == nil {
+			return fmt.Errorf("truck settings hold no truck")
+		}
+		plate := firstProp(source.Properties, "license_plate")
+		if plate == "" {
+			return fmt.Errorf("truck settings hold no license plate")
+		}
+		vehicle.Properties["license_plate"] = []string{plate}
+	default:
+		return fmt.Errorf("invalid truck settings %d", what)
+	}
+	return nil
+}
+
+func isTruckAccessory(typ string) bool {
+	return slices.Contains(truckAccessoryTypes, typ)
+}
+
+// truckAccessories returns the accessory units of vehicle.
+func truckAccessories(doc *sii.Document, vehicle *sii.Block) ([]*sii.Block, error) {
+	var accessories []*sii.Block
+	for _, name := range sii.GetArray(vehicle.Properties, "accessories") {
+		acc := doc.Block(name)
+		if acc == nil {
+			return nil, fmt.Errorf("accessory %s of truck %s not found", name, vehicle.Name)
+		}
+		accessories = append(accessories, acc)
+	}
+	return accessories, nil
+}
+
+// pastedAccessories returns copies of the units of in named names, under
+// new nameless names of doc. Values naming a unit of in are renamed along.
+func pastedAccessories(doc *sii.Document, in *sii.Document, names []string) ([]*sii.Block, error) {
+	renamed := make(map[string]string)
+	for _, b := range in.Blocks {
+		if b.Type != "vehicle" {
+			renamed[b.Name] = doc.NewNameless()
+		}
+	}
+	var pasted []*sii.Block
+	for _, name := range names {
+		b := in.Block(name)
+		if b == nil || !isTruckAccessory(b.Type) {
+			return nil, fmt.Errorf("truck settings: accessory %s not found", name)
+		}
+		acc := copyBlock(b, renamed[name])
+		for _, vals := range acc.Properties {
+			for i, v := range vals {
+				if to, ok := renamed[v]; ok {
+					vals[i] = to
+				}
+			}
+		}
+		pasted = append(pasted, acc)
+	}
+	return pasted, nil
+}
+
+// pasteVehicleDetails sets the properties of vehicle, but its accessories
+// and license plate, to those of source.
+func pasteVehicleDetails(vehicle, source *sii.Block) {
+	props := make(map[string][]string, len(source.Properties))
+	for k, vals := range source.Properties {
+		if !keptVehicleProperty(k) {
+			props[k] = append([]string(nil), vals...)
+		}
+	}
+	for k, vals := range vehicle.Properties {
+		if keptVehicleProperty(k) {
+			props[k] = vals
+		}
+	}
+	updateBlockProperties(vehicle, props)
+}
+
+// keptVehicleProperty reports whether key of a vehicle unit is left alone by
+// pasteVehicleDetails.
+func keptVehicleProperty(key string) bool {
+	return arrayBase(key) == "accessories" || key == "license_plate"
+}
+
+// arrayBase returns the array name of an element key ("wheels_wear[2]"),
+// or key itself.
+func arrayBase(key string) string {
+	if i := strings.IndexByte(key, '['); i > 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// truckModelPath returns the definition folder of the truck model, such as
+// "/def/vehicle/truck/scania.s_2016/", from the data_path of its
+// accessories.
+func truckModelPath(accessories []*sii.Block) string {
+	for _, acc := range accessories {
+		path := sii.Unquote(firstProp(acc.Properties, "data_path"))
+		if rest, ok := cutTruckModelPath(path); ok && rest == "data.sii" {
+			return strings.TrimSuffix(path, rest)
+		}
+	}
+	return ""
+}
+
+// cutTruckModelPath splits a data_path under the definition folder of a
+// truck model, returning what follows the folder.
+func cutTruckModelPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/def/vehicle/truck/")
+	if !ok {
+		return "", false
+	}
+	_, rest, ok = strings.Cut(rest, "/")
+	return rest, ok
+}
@@ -0,0 +1,159 @@
+package save
+
+import (
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/save/items"
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const truckDoc = `SiiNunit
+{
+vehicle : _nameless.1 {
+ accessories: 2
+ accessories[0]: _nameless.10
+ accessories[1]: _nameless.11
+ odometer: 1000
+ license_plate: "AB 123|france"
+}
+vehicle_accessory : _nameless.10 {
+ data_path: "/def/vehicle/truck/scania.s_2016/data.sii"
+ refund: 0
+}
+vehicle_paint_job_accessory : _nameless.11 {
+ base_color: (1, 0, 0)
+ data_path: "/def/vehicle/truck/scania.s_2016/paint_job/default.sii"
+ refund: 0
+}
+vehicle : _nameless.2 {
+ accessories: 3
+ accessories[0]: _nameless.20
+ accessories[1]: _nameless.21
+ accessories[2]: _nameless.22
+ odometer: 50
+ license_plate: "CD 456|france"
+}
+vehicle_accessory : _nameless.20 {
+ data_path: "/def/vehicle/truck/volvo.fh16_2012/data.sii"
+ refund: 0
+}
+vehicle_paint_job_accessory : _nameless.21 {
+ base_color: (0, 0, 1)
+ data_path: "/def/vehicle/truck/volvo.fh16_2012/paint_job/default.sii"
+ refund: 0
+}
+vehicle_addon_accessory : _nameless.22 {
+ slot_name: 0
+ data_path: "/def/vehicle/truck/volvo.fh16_2012/accessory/beacon.sii"
+ refund: 0
+}
+}
+`
+
+func TestPasteTruckSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		what  TruckSettings
+		check func(t *testing.T, doc *sii.Document, accessories []*sii.Block)
+	}{
+		{
+			name: "all",
+			what: TruckAll,
+			check: func(t *testing.T, doc *sii.Document, accessories []*sii.Block) {
+				if len(accessories) != 2 {
+					t.Fatalf("got %d accessories, want 2", len(accessories))
+				}
+				for _, acc := range accessories {
+					if acc.Name == "_nameless.10" || acc.Name == "_nameless.11" {
+						t.Errorf("accessory %s of the copied truck is shared", acc.Name)
+					}
+				}
+				for _, old := range []string{"_nameless.20", "_nameless.21", "_nameless.22"} {
+					if doc.Block(old) != nil {
+						t.Errorf("replaced accessory %s is still in the save", old)
+					}
+				}
+				if odo := firstProp(doc.Block("_nameless.2").Properties, "odometer"); odo != "1000" {
+					t.Errorf("odometer = %s, want 1000", odo)
+				}
+				if plate := firstProp(doc.Block("_nameless.2").Properties, "license_plate"); plate != `"CD 456|france"` {
+					t.Errorf("license_plate = %s, want the plate of the truck kept", plate)
+				}
+			},
+		},
+		{
+			name: "paint",
+			what: TruckPaint,
+			check: func(t *testing.T, doc *sii.Document, accessories []*sii.Block) {
+				if len(accessories) != 3 || doc.Block("_nameless.21") != nil {
+					t.Fatalf("accessories = %d, want the paint job replaced", len(accessories))
+				}
+				paint := accessories[2]
+				if paint.Type != "vehicle_paint_job_accessory" {
+					t.Fatalf("last accessory is a %s, want the pasted paint job", paint.Type)
+				}
+				want := `"/def/vehicle/truck/volvo.fh16_2012/paint_job/default.sii"`
+				if path := firstProp(paint.Properties, "data_path"); path != want {
+					t.Errorf("data_path = %s, want %s", path, want)
+				}
+				if odo := firstProp(doc.Block("_nameless.2").Properties, "odometer"); odo != "50" {
+					t.Errorf("odometer = %s, want 50", odo)
+				}
+			},
+		},
+		{
+			name: "details",
+			what: TruckDetails,
+			check: func(t *testing.T, doc *sii.Document, accessories []*sii.Block) {
+				if len(accessories) != 3 || accessories[0].Name != "_nameless.20" {
+					t.Errorf("accessories changed with the details")
+				}
+				if odo := firstProp(doc.Block("_nameless.2").Properties, "odometer"); odo != "1000" {
+					t.Errorf("odometer = %s, want 1000", odo)
+				}
+				if plate := firstProp(doc.Block("_nameless.2").Properties, "license_plate"); plate != `"CD 456|france"` {
+					t.Errorf("license_plate = %s, want the plate of the truck kept", plate)
+				}
+			},
+		},
+		{
+			name: "license plate",
+			what: TruckLicensePlate,
+			check: func(t *testing.T, doc *sii.Document, accessories []*sii.Block) {
+				if len(accessories) != 3 || accessories[0].Name != "_nameless.20" {
+					t.Errorf("accessories changed with the license plate")
+				}
+				if plate := firstProp(doc.Block("_nameless.2").Properties, "license_plate"); plate != `"AB 123|france"` {
+					t.Errorf("license_plate = %s, want the copied plate", plate)
+				}
+				if odo := firstProp(doc.Block("_nameless.2").Properties, "odometer"); odo != "50" {
+					t.Errorf("odometer = %s, want 50", odo)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := sii.ReadDocument([]byte(truckDoc))
+			if err != nil {
+				t.Fatalf("ReadDocument: %v", err)
+			}
+			settings, err := CopyTruckSettings(doc, "_nameless.1", tt.what)
+			if err != nil {
+				t.Fatalf("CopyTruckSettings: %v", err)
+			}
+			if err := PasteTruckSettings(doc, "_nameless.2", settings, tt.what); err != nil {
+				t.Fatalf("PasteTruckSettings: %v", err)
+			}
+			if refs := doc.DanglingReferences(items.Pointers); len(refs) != 0 {
+				t.Errorf("dangling references after paste: %v", refs)
+			}
+			accessories, err := truckAccessories(doc, doc.Block("_nameless.2"))
+			if err != nil {
+				t.Fatalf("accessories of the pasted truck: %v", err)
+			}
+			tt.check(t, doc, accessories)
+		})
+	}
+}
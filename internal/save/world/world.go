@@ -13,10 +13,9 @@ import (
 // object graph but exposes the core entities needed by the CLI.
 type World struct {
 	// Raw document and typed core blocks
-	Document   *sii.Document
-	Economy    *items.Economy
-	Player     *items.Player
-	BlocksByID map[string]sii.Block
+	Document *sii.Document
+	Economy  *items.Economy
+	Player   *items.Player
 
 	// Aggregated entities from ItemsExtra
 	Cities        []*itemsextra.City
@@ -40,7 +39,6 @@ type World struct {
 func NewWorld(doc *sii.Document) *World {
 	return &World{
 		Document:       doc,
-		BlocksByID:     make(map[string]sii.Block),
 		PlayerTrucks:   make(map[string]*itemsextra.UserCompanyTruckData),
 		PlayerTrailers: make(map[string]*itemsextra.UserCompanyTrailerData),
 	}
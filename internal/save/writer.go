@@ -11,10 +11,11 @@ import (
 	"github.com/robebs/ts-se-tool-go/internal/siidecrypt"
 )
 
-// WriteGameSII writes a game.sii document to the specified profile and save slot.
-// It creates a backup (game_backup.sii) before writing. The save directory must already exist.
-// game.sii is always written as plaintext, never encrypted, regardless of the encrypt parameter.
-func WriteGameSII(profileDir, slot string, doc *sii.Document, encrypt bool) error {
+// WriteGameSII writes a game.sii document to the specified profile and save slot
+// in the given format (normally the one recorded by LoadSaveFile).
+// It creates a backup (game_backup.sii) before writing if the file exists.
+// If the save directory doesn't exist, it will be created (useful for convoy tools).
+func WriteGameSII(profileDir, slot string, doc *sii.Document, format siidecrypt.FileFormat) error {
 	saveDir := filepath.Join(profileDir, "save", slot)
 	gamePath := filepath.Join(saveDir, "game.sii")
 
@@ -24,29 +25,24 @@ func WriteGameSII(profileDir, slot string, doc *sii.Document, encrypt bool) erro
 	}
 
 	// Backup existing file if it exists
-	if err := BackupGameSII(profileDir, slot); err != nil {
-		return fmt.Errorf("backup game.sii: %w", err)
+	if _, err := os.Stat(gamePath); err == nil {
+		if err := BackupGameSII(profileDir, slot); err != nil {
+			return fmt.Errorf("backup game.sii: %w", err)
+		}
 	}
 
-	// Serialize document to SII text
-	plaintext, err := sii.WriteDocument(doc)
-	if err != nil {
-		return fmt.Errorf("serialize SII document: %w", err)
-	}
-
-	// game.sii is always written as plaintext
-	if err := os.WriteFile(gamePath, plaintext, 0o644); err != nil {
-		return fmt.Errorf("write game.sii: %w", err)
+	if err := siidecrypt.WriteFile(gamePath, doc, format); err != nil {
+		return fmt.Errorf("write game.sii as %s: %w", format, err)
 	}
 
 	return nil
 }
 
-// WriteInfoSII writes an info.sii document to the specified save directory.
+// WriteInfoSII writes an info.sii document to the specified save directory
+// in the given format.
 // It creates a backup (info_backup.sii) before writing if the file exists.
 // If the save directory doesn't exist, it will be created (useful for convoy tools).
-// info.sii is always written as plaintext, never encrypted, regardless of the encrypt parameter.
-func WriteInfoSII(saveDir string, doc *sii.Document, encrypt bool) error {
+func WriteInfoSII(saveDir string, doc *sii.Document, format siidecrypt.FileFormat) error {
 	infoPath := filepath.Join(saveDir, "info.sii")
 
 	// Ensure save directory exists
@@ -59,24 +55,17 @@ func WriteInfoSII(saveDir string, doc *sii.Document, encrypt bool) error {
 		return fmt.Errorf("backup info.sii: %w", err)
 	}
 
-	// Serialize document to SII text
-	plaintext, err := sii.WriteDocument(doc)
-	if err != nil {
-		return fmt.Errorf("serialize SII document: %w", err)
-	}
-
-	// info.sii is always written as plaintext
-	if err := os.WriteFile(infoPath, plaintext, 0o644); err != nil {
-		return fmt.Errorf("write info.sii: %w", err)
+	if err := siidecrypt.WriteFile(infoPath, doc, format); err != nil {
+		return fmt.Errorf("write info.sii as %s: %w", format, err)
 	}
 
 	return nil
 }
 
-// WriteProfileSII writes a profile.sii document to the specified profile directory.
+// WriteProfileSII writes a profile.sii document to the specified profile directory
+// in the given format.
 // It creates a backup (profile_backup.sii) before writing. The profile directory must already exist.
-// profile.sii is always written in encrypted format, regardless of the encrypt parameter.
-func WriteProfileSII(profileDir string, doc *sii.Document, encrypt bool) error {
+func WriteProfileSII(profileDir string, doc *sii.Document, format siidecrypt.FileFormat) error {
 	profilePath := filepath.Join(profileDir, "profile.sii")
 
 	// Backup existing file if it exists
@@ -84,15 +73,8 @@ func WriteProfileSII(profileDir string, doc *sii.Document, encrypt bool) error {
 		return fmt.Errorf("backup profile.sii: %w", err)
 	}
 
-	// Serialize document to SII text
-	plaintext, err := sii.WriteDocument(doc)
-	if err != nil {
-		return fmt.Errorf("serialize SII document: %w", err)
-	}
-
-	// profile.sii is always encrypted
-	if err := siidecrypt.EncryptFile(profilePath, plaintext); err != nil {
-		return fmt.Errorf("write encrypted profile.sii: %w", err)
+	if err := siidecrypt.WriteFile(profilePath, doc, format); err != nil {
+		return fmt.Errorf("write profile.sii as %s: %w", format, err)
 	}
 
 	return nil
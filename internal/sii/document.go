@@ -1,16 +1,21 @@
 package sii
 
-import (
-	"strconv"
-
-	"github.com/robebs/ts-se-tool-go/internal/save/dataformat"
-	"github.com/robebs/ts-se-tool-go/internal/save/items"
-)
+import "fmt"
 
 // Document is a very generic representation of an SII file.
 // It will be progressively specialized to mirror the original C# CustomClasses.
+//
+// Blocks should be added, removed and renamed through Append, Insert, Remove
+// and Rename so that the lookup indexes stay current.
 type Document struct {
-	Blocks []Block
+	Blocks []*Block
+	// Includes lists the paths of top-level @include directives.
+	Includes []string
+
+	src      *docSource // set by ReadOptions.Lossless
+	idx      *index
+	ids      *namelessAllocator // built by NewNameless
+	numbered map[string]int     // next numbers of NewNumberedName
 }
 
 // Block represents a single SiiNunit block, such as:
@@ -21,96 +26,196 @@ type Block struct {
 	Properties map[string][]string
 	// PropertyOrder stores the order in which properties were read from the file
 	PropertyOrder []string
+	// Includes lists the paths of @include directives found in the block body.
+	Includes []string
+
+	src *blockSource // set by ReadOptions.Lossless
 }
 
-// IndexByName builds a simple name -> Block map over all blocks in the
-// document. It is the Go equivalent of the C# SiiNunit.SiiNitems dictionary.
-func (d *Document) IndexByName() map[string]Block {
-	out := make(map[string]Block, len(d.Blocks))
-	for _, b := range d.Blocks {
-		out[b.Name] = b
+// index maps block names and types to blocks. It is built on first use and
+// kept up to date by Append, Insert, Remove and Rename.
+type index struct {
+	byName map[string]*Block
+	byType map[string][]*Block
+}
+
+// index returns the document index, building it if needed. Documents read
+// by ReadDocument have unique block names; blocks put in Blocks directly are
+// expected to have too, see Reindex.
+func (d *Document) index() *index {
+	if d.idx == nil {
+		d.idx, _ = buildIndex(d.Blocks)
 	}
-	return out
+	return d.idx
 }
 
-// ToBankLoans parcourt les blocs et construit des BankLoan typés à partir des
-// blocs de type "bank_loan".
-func (d *Document) ToBankLoans() []items.BankLoan {
-	var out []items.BankLoan
+// Reindex rebuilds the name and type indexes. It is only needed after
+// Blocks, or the Name or Type of a block, were modified directly instead of
+// through the Document methods. Two blocks with the same name are an error,
+// which leaves the indexes as they were.
+func (d *Document) Reindex() error {
+	idx, err := buildIndex(d.Blocks)
+	if err != nil {
+		return err
+	}
+	d.idx = idx
+	return nil
+}
 
-	for _, b := range d.Blocks {
-		if b.Type != "bank_loan" {
+// buildIndex indexes blocks. On duplicate names, the index keeps the first
+// block of each name and an error is returned.
+func buildIndex(blocks []*Block) (*index, error) {
+	idx := &index{
+		byName: make(map[string]*Block, len(blocks)),
+		byType: make(map[string][]*Block),
+	}
+	var err error
+	for _, b := range blocks {
+		if _, dup := idx.byName[b.Name]; dup {
+			if err == nil {
+				err = fmt.Errorf("sii: duplicate block name %s", b.Name)
+			}
 			continue
 		}
+		idx.byName[b.Name] = b
+		idx.byType[b.Type] = append(idx.byType[b.Type], b)
+	}
+	return idx, err
+}
 
-		var loan items.BankLoan
+// Block returns the block with the given name, or nil. It is the Go
+// equivalent of a lookup in the C# SiiNunit.SiiNitems dictionary.
+func (d *Document) Block(name string) *Block {
+	return d.index().byName[name]
+}
 
-		if vals, ok := b.Properties["amount"]; ok && len(vals) > 0 {
-			if v, err := strconv.Atoi(vals[0]); err == nil {
-				loan.Amount = v
-			}
-		}
+// BlocksByType returns the blocks of the given type in document order. The
+// slice is shared with the index and must not be modified, but it is not
+// changed by later additions and removals, so the document can be edited
+// while ranging over it.
+func (d *Document) BlocksByType(typ string) []*Block {
+	return d.index().byType[typ]
+}
 
-		if vals, ok := b.Properties["original_amount"]; ok && len(vals) > 0 {
-			if v, err := strconv.Atoi(vals[0]); err == nil {
-				loan.OriginalAmount = v
-			}
-		}
+// FirstBlockByType returns the first block of the given type, or nil.
+func (d *Document) FirstBlockByType(typ string) *Block {
+	if blocks := d.index().byType[typ]; len(blocks) > 0 {
+		return blocks[0]
+	}
+	return nil
+}
 
-		if vals, ok := b.Properties["time_stamp"]; ok && len(vals) > 0 {
-			if v, err := strconv.Atoi(vals[0]); err == nil {
-				loan.TimeStamp = v
-			}
+// Append adds blocks at the end of the document.
+func (d *Document) Append(blocks ...*Block) error {
+	for _, b := range blocks {
+		if err := d.Insert(len(d.Blocks), b); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if vals, ok := b.Properties["interest_rate"]; ok && len(vals) > 0 {
-			if fv, err := strconv.ParseFloat(vals[0], 32); err == nil {
-				loan.InterestRate = dataformat.Float(fv)
-			}
-		}
+// Insert adds b at position at (0 <= at <= len(Blocks)). Block names must be
+// unique.
+func (d *Document) Insert(at int, b *Block) error {
+	idx := d.index()
+	if at < 0 || at > len(d.Blocks) {
+		return fmt.Errorf("sii: insert %s at %d: out of range", b.Name, at)
+	}
+	if _, dup := idx.byName[b.Name]; dup {
+		return fmt.Errorf("sii: duplicate block name %s", b.Name)
+	}
+	if b.Properties == nil {
+		b.Properties = make(map[string][]string)
+	}
 
-		if vals, ok := b.Properties["duration"]; ok && len(vals) > 0 {
-			if v, err := strconv.Atoi(vals[0]); err == nil {
-				loan.Duration = v
+	d.Blocks = append(d.Blocks, nil)
+	copy(d.Blocks[at+1:], d.Blocks[at:])
+	d.Blocks[at] = b
+
+	idx.byName[b.Name] = b
+	// Keep the type list in document order: b goes before the next block of
+	// the same type.
+	sameType := idx.byType[b.Type]
+	pos := len(sameType)
+	for _, other := range d.Blocks[at+1:] {
+		if other.Type == b.Type {
+			for i := range sameType {
+				if sameType[i] == other {
+					pos = i
+					break
+				}
 			}
+			break
 		}
-
-		out = append(out, loan)
 	}
-
-	return out
+	grown := make([]*Block, 0, len(sameType)+1)
+	grown = append(grown, sameType[:pos]...)
+	grown = append(grown, b)
+	idx.byType[b.Type] = append(grown, sameType[pos:]...)
+	return nil
 }
 
-// ToEconomy searches for the first block of type "economy" and converts it to
-// a typed items.Economy using the FromProperties helper.
-func (d *Document) ToEconomy() (*items.Economy, error) {
-	for _, b := range d.Blocks {
-		if b.Type != "economy" {
-			continue
-		}
-		var e items.Economy
-		if err := e.FromProperties(b.Properties); err != nil {
-			return nil, err
+// Remove deletes the named blocks in one pass and returns how many were
+// found.
+func (d *Document) Remove(names ...string) int {
+	idx := d.index()
+	doomed := make(map[*Block]bool, len(names))
+	for _, name := range names {
+		if b, ok := idx.byName[name]; ok {
+			doomed[b] = true
 		}
-		return &e, nil
 	}
-	return nil, nil
-}
+	if len(doomed) == 0 {
+		return 0
+	}
 
-// ToPlayer searches for the first block of type "player" and converts it to a
-// typed items.Player using the FromProperties helper.
-func (d *Document) ToPlayer() (*items.Player, error) {
+	kept := d.Blocks[:0]
 	for _, b := range d.Blocks {
-		if b.Type != "player" {
-			continue
+		if !doomed[b] {
+			kept = append(kept, b)
+		}
+	}
+	for i := len(kept); i < len(d.Blocks); i++ {
+		d.Blocks[i] = nil
+	}
+	d.Blocks = kept
+
+	for b := range doomed {
+		delete(idx.byName, b.Name)
+		var sameType []*Block
+		for _, other := range idx.byType[b.Type] {
+			if !doomed[other] {
+				sameType = append(sameType, other)
+			}
 		}
-		var p items.Player
-		if err := p.FromProperties(b.Properties); err != nil {
-			return nil, err
+		if len(sameType) == 0 {
+			delete(idx.byType, b.Type)
+		} else {
+			idx.byType[b.Type] = sameType
 		}
-		return &p, nil
 	}
-	return nil, nil
+	return len(doomed)
+}
+
+// Rename changes the name of the block called oldName. References to the
+// block in other blocks are not updated.
+func (d *Document) Rename(oldName, newName string) error {
+	idx := d.index()
+	b, ok := idx.byName[oldName]
+	if !ok {
+		return fmt.Errorf("sii: rename %s: no such block", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, dup := idx.byName[newName]; dup {
+		return fmt.Errorf("sii: rename %s: duplicate block name %s", oldName, newName)
+	}
+	delete(idx.byName, oldName)
+	b.Name = newName
+	idx.byName[newName] = b
+	return nil
 }
 
 // DebugString returns a human-friendly dump of the document.
@@ -0,0 +1,241 @@
+package sii
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDocument_Index(t *testing.T) {
+	doc, err := ReadDocument([]byte("SiiNunit\n{\na : x.1 {\n}\nb : y.1 {\n}\na : x.2 {\n}\n}\n"))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	doc.Block("x.1").Properties["k"] = []string{"v"}
+	if got := doc.Blocks[0].Properties["k"]; len(got) != 1 {
+		t.Errorf("edit through Block() was lost")
+	}
+
+	if err := doc.Insert(1, &Block{Type: "a", Name: "x.0"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := doc.Append(&Block{Type: "a", Name: "x.1"}); err == nil {
+		t.Errorf("Append accepted a duplicate name")
+	}
+	if err := doc.Rename("x.2", "x.3"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if n := doc.Remove("x.1", "missing"); n != 1 {
+		t.Errorf("Remove = %d, want 1", n)
+	}
+
+	var names []string
+	for _, b := range doc.BlocksByType("a") {
+		names = append(names, b.Name)
+	}
+	if len(names) != 2 || names[0] != "x.0" || names[1] != "x.3" {
+		t.Errorf("BlocksByType(a) = %v, want [x.0 x.3]", names)
+	}
+	if doc.Block("x.2") != nil || doc.Block("x.3") == nil || doc.Block("x.1") != nil {
+		t.Errorf("name index is stale")
+	}
+	if b := doc.FirstBlockByType("b"); b == nil || b.Name != "y.1" {
+		t.Errorf("FirstBlockByType(b) = %v", b)
+	}
+
+	// Removing while ranging over BlocksByType visits every block once
+	var visited []string
+	for _, b := range doc.BlocksByType("a") {
+		visited = append(visited, b.Name)
+		doc.Remove(b.Name)
+	}
+	if len(visited) != 2 || visited[0] != "x.0" || visited[1] != "x.3" {
+		t.Errorf("visited %v while removing, want [x.0 x.3]", visited)
+	}
+	if n := len(doc.BlocksByType("a")); n != 0 {
+		t.Errorf("%d blocks of type a left", n)
+	}
+
+	if _, err := ReadDocument([]byte("SiiNunit\n{\na : x.1 {\n}\nb : x.1 {\n}\n}\n")); err == nil {
+		t.Errorf("ReadDocument accepted a duplicate name")
+	}
+	doc.Blocks = append(doc.Blocks, &Block{Type: "b", Name: "y.1"})
+	if err := doc.Reindex(); err == nil {
+		t.Errorf("Reindex accepted a duplicate name")
+	}
+}
+
+func TestDocument_RemoveCascade(t *testing.T) {
+	schema := PointerSchema{Kinds: map[string]map[string]PointerKind{
+		"player":  {"trucks[]": PointerOwner, "assigned_truck": PointerLink},
+		"garage":  {"vehicles[]": PointerLink},
+		"vehicle": {"accessories[]": PointerOwner},
+	}}
+	doc, err := ReadDocument([]byte(`SiiNunit
+{
+player : _nameless.1 {
+ trucks: 2
+ trucks[0]: _nameless.10
+ trucks[1]: _nameless.20
+ assigned_truck: _nameless.10
+}
+vehicle : _nameless.10 {
+ accessories: 1
+ accessories[0]: _nameless.11
+}
+vehicle_accessory : _nameless.11 {
+}
+vehicle : _nameless.20 {
+ accessories: 0
+}
+garage : garage.paris {
+ vehicles: 2
+ vehicles[0]: _nameless.10
+ vehicles[1]: _nameless.20
+ profit_log: _nameless.99
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if err := doc.AddOwned(schema, "_nameless.20", "accessories", &Block{Type: "vehicle_accessory", Name: "_nameless.21"}); err != nil {
+		t.Fatalf("AddOwned: %v", err)
+	}
+	if doc.Blocks[4].Name != "_nameless.21" || GetArray(doc.Block("_nameless.20").Properties, "accessories")[0] != "_nameless.21" {
+		t.Errorf("owned block not added after its owner")
+	}
+	if err := doc.AddOwned(schema, "garage.paris", "vehicles", &Block{Type: "vehicle", Name: "_nameless.30"}); err == nil {
+		t.Errorf("AddOwned accepted a link pointer")
+	}
+
+	if n := doc.RemoveCascade(schema, "_nameless.10"); n != 2 {
+		t.Errorf("RemoveCascade = %d, want 2", n)
+	}
+	player := doc.Block("_nameless.1").Properties
+	if got := GetArray(player, "trucks"); len(got) != 1 || got[0] != "_nameless.20" || player["assigned_truck"][0] != Null {
+		t.Errorf("player not detached: trucks %v, assigned_truck %v", got, player["assigned_truck"])
+	}
+	if got := GetArray(doc.Block("garage.paris").Properties, "vehicles"); len(got) != 2 || got[0] != Null {
+		t.Errorf("garage slot not kept: %v", got)
+	}
+
+	dangling := doc.DanglingReferences(schema)
+	if len(dangling) != 1 || dangling[0].Target != "_nameless.99" {
+		t.Errorf("DanglingReferences = %v, want the garage profit_log", dangling)
+	}
+}
+
+func TestDocument_RemoveCascadeParallel(t *testing.T) {
+	schema := PointerSchema{
+		Kinds: map[string]map[string]PointerKind{
+			"player":  {"trucks[]": PointerOwner, "truck_profit_logs[]": PointerOwner, "drivers[]": PointerOwner},
+			"vehicle": {"accessories[]": PointerOwner},
+		},
+		Parallel: map[string][][]string{
+			"player": {{"trucks", "truck_profit_logs"}, {"drivers", "driver_flags"}},
+		},
+	}
+	doc, err := ReadDocument([]byte(`SiiNunit
+{
+player : _nameless.1 {
+ trucks: 3
+ trucks[0]: _nameless.10
+ trucks[1]: _nameless.20
+ trucks[2]: _nameless.30
+ truck_profit_logs: 3
+ truck_profit_logs[0]: _nameless.11
+ truck_profit_logs[1]: _nameless.21
+ truck_profit_logs[2]: _nameless.31
+ drivers: 2
+ drivers[0]: driver.anna
+ drivers[1]: driver.bob
+ driver_flags: 2
+ driver_flags[0]: 1
+ driver_flags[1]: 2
+}
+vehicle : _nameless.10 {
+ accessories: 0
+}
+vehicle : _nameless.20 {
+ accessories: 1
+ accessories[0]: _nameless.22
+}
+vehicle_accessory : _nameless.22 {
+}
+vehicle : _nameless.30 {
+ accessories: 0
+}
+profit_log : _nameless.11 {
+}
+profit_log : _nameless.21 {
+}
+profit_log : _nameless.31 {
+}
+driver_ai : driver.anna {
+}
+driver_ai : driver.bob {
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if n := doc.RemoveCascade(schema, "_nameless.20"); n != 3 {
+		t.Errorf("RemoveCascade = %d, want the truck, its accessory and its profit log", n)
+	}
+	player := doc.Block("_nameless.1").Properties
+	if got := GetArray(player, "trucks"); !slices.Equal(got, []string{"_nameless.10", "_nameless.30"}) {
+		t.Errorf("trucks = %v", got)
+	}
+	if got := GetArray(player, "truck_profit_logs"); !slices.Equal(got, []string{"_nameless.11", "_nameless.31"}) {
+		t.Errorf("truck_profit_logs = %v", got)
+	}
+	if doc.Block("_nameless.21") != nil {
+		t.Errorf("the profit log of the removed truck is still there")
+	}
+
+	doc.RemoveCascade(schema, "driver.anna")
+	if got := GetArray(player, "driver_flags"); !slices.Equal(got, []string{"2"}) {
+		t.Errorf("driver_flags = %v, want the flags of bob", got)
+	}
+	if dangling := doc.DanglingReferences(schema); len(dangling) != 0 {
+		t.Errorf("DanglingReferences = %v", dangling)
+	}
+}
+
+func TestDocument_NewNameless(t *testing.T) {
+	for _, name := range []string{"_nameless.231.8a30.0a00", "_nameless.1.2.0000.0010", "_nameless.0000.0010"} {
+		id, ok := ParseNameless(name)
+		if got := FormatNameless(id); !ok || got != name {
+			t.Errorf("FormatNameless(ParseNameless(%s)) = %s", name, got)
+		}
+	}
+
+	doc, err := ReadDocument([]byte(`SiiNunit
+{
+player : _nameless.231.8b17.be00 {
+ trucks: 1
+ trucks[0]: _nameless.231.ffff.fff0
+}
+driver_ai : driver.7 {
+}
+bank : _nameless.234.0000.0010 {
+}
+}
+`))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if got := doc.NewNameless(); got != "_nameless.232.0000.0000" {
+		t.Errorf("NewNameless = %s, want the first ID after the highest 231 one", got)
+	}
+	if got := doc.NewNameless(); got != "_nameless.232.0000.0010" {
+		t.Errorf("second NewNameless = %s", got)
+	}
+	if a, b := doc.NewNumberedName("driver"), doc.NewNumberedName("driver"); a != "driver.8" || b != "driver.9" {
+		t.Errorf("NewNumberedName = %s, %s", a, b)
+	}
+}
@@ -1,173 +1,43 @@
 package sii
 
-import (
-	"bufio"
-	"bytes"
-	"errors"
-	"fmt"
-	"strings"
-)
+import "bytes"
 
-// ReadDocument handles plaintext SII content:
-//   - data doit déjà être déchiffré (TODO: appel à un outil externe pour les fichiers chiffrés)
-//   - parse le texte en structure Document.
+// ReadDocument parses plaintext SII content (already decrypted / decoded,
+// see siidecrypt) into a Document. @include directives are recorded but not
+// resolved; use ReadDocumentWithOptions for that.
 func ReadDocument(data []byte) (*Document, error) {
-	// Si le fichier commence directement par "SiiNunit", on considère que c'est du texte clair.
-	if !bytes.Contains(data, []byte("SiiNunit")) {
-		// Ici il faudrait appeler un outil natif (sii_decrypt / SII_Decrypt.dll).
-		return nil, errors.New("encrypted or unknown SII format: decryption support not yet implemented")
-	}
-
-	lines := splitLines(data)
-
-	if len(lines) < 2 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "SiiNunit") {
-		return nil, errors.New("invalid SII: missing SiiNunit header")
-	}
-
-	// Chercher la première accolade ouvrante suivant SiiNunit
-	i := 1
-	for i < len(lines) && strings.TrimSpace(lines[i]) != "{" {
-		i++
-	}
-	if i == len(lines) {
-		return nil, errors.New("invalid SII: missing opening brace")
-	}
-	i++ // première ligne après "{"
-
-	var blocks []Block
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-		if line == "" || line == "}" {
-			i++
-			continue
-		}
-
-		// Ligne de bloc: "type : name {"
-		if strings.Contains(line, ":") && strings.Contains(line, "{") {
-			parts := strings.SplitN(line, ":", 2)
-			typ := strings.TrimSpace(parts[0])
-
-			right := parts[1]
-			right = strings.SplitN(right, "{", 2)[0]
-			name := strings.TrimSpace(right)
-
-			// Collecter les lignes jusqu'à la prochaine "}"
-			i++
-			var body []string
-			for i < len(lines) {
-				l := lines[i]
-				body = append(body, l)
-				if strings.TrimSpace(l) == "}" {
-					i++
-					break
-				}
-				i++
-			}
-
-			props, order := parseProperties(body)
-			blocks = append(blocks, Block{
-				Type:          typ,
-				Name:          name,
-				Properties:    props,
-				PropertyOrder: order,
-			})
-			continue
-		}
-
-		i++
-	}
+	return ReadDocumentWithOptions(data, ReadOptions{})
+}
 
-	return &Document{Blocks: blocks}, nil
+// ReadDocumentWithOptions parses plaintext SII content into a Document.
+// Syntax errors are returned as *ParseError with line and column.
+func ReadDocumentWithOptions(data []byte, opts ReadOptions) (*Document, error) {
+	p := &parser{s: newScanner(bytes.NewReader(data), ""), opts: opts}
+	p.s.record = opts.Lossless
+	return p.parseDocument()
 }
 
 // WriteDocument sérialise un Document vers du texte SII. La partie chiffrement
 // éventuelle doit être appliquée par l'appelant si nécessaire.
+//
+// A document read with ReadOptions.Lossless is written back byte for byte,
+// except for the lines whose values were changed, added or removed.
 func WriteDocument(doc *Document) ([]byte, error) {
 	var buf bytes.Buffer
 
-	buf.WriteString("SiiNunit\n{\n")
-
-	for i, b := range doc.Blocks {
-		fmt.Fprintf(&buf, "%s : %s {\n", b.Type, b.Name)
-
-		// Use PropertyOrder if available, otherwise iterate over map (may be unordered)
-		if len(b.PropertyOrder) > 0 {
-			// Write properties in original order
-			for _, k := range b.PropertyOrder {
-				if vals, ok := b.Properties[k]; ok {
-					for _, v := range vals {
-						fmt.Fprintf(&buf, " %s: %s\n", k, v)
-					}
-				}
-			}
-			// Write any new properties that weren't in original order
-			for k, vals := range b.Properties {
-				found := false
-				for _, orderedKey := range b.PropertyOrder {
-					if k == orderedKey {
-						found = true
-						break
-					}
-				}
-				if !found {
-					for _, v := range vals {
-						fmt.Fprintf(&buf, " %s: %s\n", k, v)
-					}
-				}
-			}
-		} else {
-			// Fallback: iterate over map (no order guarantee)
-			for k, vals := range b.Properties {
-				for _, v := range vals {
-					fmt.Fprintf(&buf, " %s: %s\n", k, v)
-				}
-			}
-		}
-		buf.WriteString("}\n")
-
-		// Add blank line between blocks (except after the last block)
-		if i < len(doc.Blocks)-1 {
-			buf.WriteString("\n")
-		}
+	if doc.src != nil {
+		writeLossless(&buf, doc)
+		return buf.Bytes(), nil
 	}
 
-	buf.WriteString("}\n")
-	return buf.Bytes(), nil
-}
-
-func splitLines(b []byte) []string {
-	sc := bufio.NewScanner(bytes.NewReader(b))
-	var out []string
-	for sc.Scan() {
-		out = append(out, sc.Text())
-	}
-	return out
-}
-
-func parseProperties(lines []string) (map[string][]string, []string) {
-	props := make(map[string][]string)
-	var order []string
-	seen := make(map[string]bool)
-
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l == "" || l == "}" || l == "{" {
-			continue
-		}
-		parts := strings.SplitN(l, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		k := strings.TrimSpace(parts[0])
-		v := strings.TrimSpace(parts[1])
-		props[k] = append(props[k], v)
-
-		// Track order of first occurrence of each key
-		if !seen[k] {
-			order = append(order, k)
-			seen[k] = true
+	w := NewWriter(&buf)
+	for _, b := range doc.Blocks {
+		if err := w.WriteBlock(b); err != nil {
+			return nil, err
 		}
 	}
-	return props, order
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
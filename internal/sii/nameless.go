@@ -0,0 +1,156 @@
+package sii
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultNamelessPrefix is used for documents without any nameless unit. It
+// is the most common prefix of game saves.
+const defaultNamelessPrefix = 0x231
+
+// ParseNameless returns the 64-bit ID of a "_nameless.231.8c2f.7230" name,
+// as stored in binary saves.
+func ParseNameless(name string) (uint64, bool) {
+	rest, ok := strings.CutPrefix(name, namelessPrefix)
+	if !ok {
+		return 0, false
+	}
+	groups := strings.Split(rest, ".")
+	if len(groups) > 4 {
+		return 0, false
+	}
+	var id uint64
+	for _, group := range groups {
+		v, err := strconv.ParseUint(group, 16, 16)
+		if err != nil {
+			return 0, false
+		}
+		id = id<<16 | v
+	}
+	return id, true
+}
+
+// FormatNameless is the reverse of ParseNameless. Like the BSII decoder, it
+// drops the leading zeros of the two high groups and writes the two low
+// groups with four digits.
+func FormatNameless(id uint64) string {
+	var groups []string
+	if high := id >> 48; high != 0 {
+		groups = append(groups, strconv.FormatUint(high, 16))
+	}
+	if mid := id >> 32 & 0xffff; mid != 0 || len(groups) > 0 {
+		groups = append(groups, strconv.FormatUint(mid, 16))
+	}
+	groups = append(groups, fmt.Sprintf("%04x", id>>16&0xffff), fmt.Sprintf("%04x", id&0xffff))
+	return namelessPrefix + strings.Join(groups, ".")
+}
+
+// namelessAllocator hands out nameless IDs following the scheme of the
+// document: the game numbers units like memory addresses, sharing their high
+// 32 bits and aligned on 16.
+type namelessAllocator struct {
+	used map[uint64]bool
+	next uint64
+	step uint64
+}
+
+// newNamelessAllocator learns the ID scheme from the block names and
+// pointers of d.
+func newNamelessAllocator(d *Document) *namelessAllocator {
+	a := &namelessAllocator{used: make(map[uint64]bool), step: 0x10}
+	note := func(s string) {
+		if id, ok := ParseNameless(s); ok {
+			a.used[id] = true
+		}
+	}
+	for _, b := range d.Blocks {
+		note(b.Name)
+		for _, vals := range b.Properties {
+			for _, v := range vals {
+				if strings.HasPrefix(v, namelessPrefix) {
+					note(v)
+				}
+			}
+		}
+	}
+
+	// The most common prefix wins; new IDs go after its highest one.
+	counts := make(map[uint64]int)
+	for id := range a.used {
+		counts[id>>32]++
+	}
+	prefix, best := uint64(defaultNamelessPrefix), 0
+	for p, n := range counts {
+		if n > best || n == best && p < prefix {
+			prefix, best = p, n
+		}
+	}
+	a.next = prefix << 32
+	for id := range a.used {
+		if id>>32 != prefix {
+			continue
+		}
+		if id%a.step != 0 {
+			a.step = 1
+		}
+		if id >= a.next {
+			a.next = id + 1
+		}
+	}
+	return a
+}
+
+// NewNameless returns a "_nameless.…" name used by no block or pointer of
+// the document, following the ID scheme of the units already in it. Blocks
+// created by editors should be named with it rather than invented names,
+// which the game may reject.
+func (d *Document) NewNameless() string {
+	if d.ids == nil {
+		d.ids = newNamelessAllocator(d)
+	}
+	a := d.ids
+	for {
+		id := (a.next + a.step - 1) / a.step * a.step
+		a.next = id + a.step
+		if id == 0 || a.used[id] {
+			continue
+		}
+		a.used[id] = true
+		name := FormatNameless(id)
+		if d.Block(name) == nil {
+			return name
+		}
+	}
+}
+
+// NewNumberedName returns "prefix.N" with N above every number already used
+// by a block with that prefix or returned by an earlier call. The game names
+// some units this way instead of with nameless IDs, such as drivers
+// ("driver.243").
+func (d *Document) NewNumberedName(prefix string) string {
+	if d.numbered == nil {
+		d.numbered = make(map[string]int)
+	}
+	next, ok := d.numbered[prefix]
+	if !ok {
+		for _, b := range d.Blocks {
+			rest, ok := strings.CutPrefix(b.Name, prefix+".")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.Atoi(rest); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+	}
+	for {
+		name := prefix + "." + strconv.Itoa(next)
+		next++
+		if d.Block(name) == nil {
+			d.numbered[prefix] = next
+			return name
+		}
+	}
+}
@@ -0,0 +1,560 @@
+package sii
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxIncludeDepth guards against @include cycles.
+const maxIncludeDepth = 16
+
+// ParseError reports a syntax error in SII text. Line and Column are 1-based
+// and point at the offending character.
+type ParseError struct {
+	File   string // set for errors inside an @include'd file
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("sii: %s: line %d, column %d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("sii: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ReadOptions tunes ReadDocumentWithOptions.
+type ReadOptions struct {
+	// ResolveInclude returns the content of the file named by an @include
+	// directive. Its blocks or properties are then parsed in place. When nil,
+	// directives are only recorded in Document.Includes / Block.Includes.
+	ResolveInclude func(path string) ([]byte, error)
+	// Lossless keeps the source text (whitespace, comments, quoting) so that
+	// WriteDocument reproduces it byte for byte and only rewrites the lines
+	// whose values changed.
+	Lossless bool
+}
+
+// scanner reads SII text byte by byte and keeps track of the position.
+// When record is set, consumed bytes are kept until the next cut.
+type scanner struct {
+	r      *bufio.Reader
+	file   string
+	line   int
+	col    int
+	record bool
+	rec    []byte
+}
+
+func newScanner(r io.Reader, file string) *scanner {
+	return &scanner{r: bufio.NewReader(r), file: file, line: 1, col: 1}
+}
+
+// peek returns the next byte without consuming it; ok is false at EOF.
+func (s *scanner) peek() (byte, bool) {
+	b, err := s.r.Peek(1)
+	if err != nil {
+		return 0, false
+	}
+	return b[0], true
+}
+
+// peekIs reports whether the upcoming bytes are prefix.
+func (s *scanner) peekIs(prefix string) bool {
+	b, _ := s.r.Peek(len(prefix))
+	return string(b) == prefix
+}
+
+func (s *scanner) next() (byte, bool) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return 0, false
+	}
+	if s.record {
+		s.rec = append(s.rec, c)
+	}
+	if c == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
+	return c, true
+}
+
+// cut returns the text consumed since the previous cut.
+func (s *scanner) cut() string {
+	text := string(s.rec)
+	s.rec = s.rec[:0]
+	return text
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return &ParseError{File: s.file, Line: s.line, Column: s.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe formats the next byte for error messages.
+func (s *scanner) describe() string {
+	c, ok := s.peek()
+	if !ok {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", c)
+}
+
+func (s *scanner) atCommentStart() bool {
+	return s.peekIs("//") || s.peekIs("#") || s.peekIs("/*")
+}
+
+// skipTrivia skips blanks and comments. Line breaks are only skipped when
+// newlines is true.
+func (s *scanner) skipTrivia(newlines bool) error {
+	for {
+		c, ok := s.peek()
+		if !ok {
+			return nil
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			s.next()
+		case c == '\n' && newlines:
+			s.next()
+		case s.peekIs("/*"):
+			line, col := s.line, s.col
+			s.next()
+			s.next()
+			for !s.peekIs("*/") {
+				if _, ok := s.next(); !ok {
+					return &ParseError{File: s.file, Line: line, Column: col, Msg: "unterminated /* comment"}
+				}
+			}
+			s.next()
+			s.next()
+		case s.peekIs("//") || c == '#':
+			for {
+				c, ok := s.peek()
+				if !ok || c == '\n' {
+					break
+				}
+				s.next()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// expect consumes c or fails.
+func (s *scanner) expect(c byte, context string) error {
+	if got, ok := s.peek(); !ok || got != c {
+		return s.errorf("expected %q %s, found %s", c, context, s.describe())
+	}
+	s.next()
+	return nil
+}
+
+// readWord reads a bare token such as a block type, unit name or property
+// key. It stops at blanks, structural characters and comments.
+func (s *scanner) readWord(what string) (string, error) {
+	var sb strings.Builder
+	for {
+		c, ok := s.peek()
+		if !ok || isBlank(c) || strings.IndexByte(":{}\"", c) >= 0 || s.atCommentStart() {
+			break
+		}
+		sb.WriteByte(c)
+		s.next()
+	}
+	if sb.Len() == 0 {
+		return "", s.errorf("expected %s, found %s", what, s.describe())
+	}
+	return sb.String(), nil
+}
+
+// readQuoted reads a quoted string and returns it as written, quotes and
+// escape sequences included.
+func (s *scanner) readQuoted() (string, error) {
+	line, col := s.line, s.col
+	var sb strings.Builder
+	c, _ := s.next()
+	sb.WriteByte(c)
+	for {
+		c, ok := s.next()
+		if !ok || c == '\n' {
+			return "", &ParseError{File: s.file, Line: line, Column: col, Msg: "unterminated string"}
+		}
+		sb.WriteByte(c)
+		switch c {
+		case '\\':
+			esc, ok := s.next()
+			if !ok {
+				return "", &ParseError{File: s.file, Line: line, Column: col, Msg: "unterminated string"}
+			}
+			sb.WriteByte(esc)
+		case '"':
+			return sb.String(), nil
+		}
+	}
+}
+
+// readValue reads a property value: a quoted string, or everything up to
+// the end of the line, a closing brace or a comment.
+func (s *scanner) readValue() (string, error) {
+	if c, ok := s.peek(); ok && c == '"' {
+		return s.readQuoted()
+	}
+
+	var sb strings.Builder
+	for {
+		c, ok := s.peek()
+		if !ok || c == '\n' || c == '\r' || c == '}' || s.atCommentStart() {
+			break
+		}
+		sb.WriteByte(c)
+		s.next()
+	}
+	return strings.TrimRight(sb.String(), " \t"), nil
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v'
+}
+
+// parser turns the scanner output into a Document.
+type parser struct {
+	s     *scanner
+	opts  ReadOptions
+	depth int
+}
+
+// parseDocument parses a complete "SiiNunit { ... }" file.
+func (p *parser) parseDocument() (*Document, error) {
+	s := p.s
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	if s.record {
+		doc.src = &docSource{head: s.cut()}
+	}
+	if err := p.parseUnits(doc, true); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseEnd(); err != nil {
+		return nil, err
+	}
+	if s.record {
+		doc.src.tail = s.cut()
+	}
+	if err := doc.Reindex(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parseHeader parses an optional BOM, the SiiNunit magic and its opening
+// brace.
+func (p *parser) parseHeader() error {
+	s := p.s
+	if s.peekIs("\xef\xbb\xbf") {
+		for i := 0; i < 3; i++ {
+			s.next()
+		}
+		s.col = 1
+	}
+	if err := s.skipTrivia(true); err != nil {
+		return err
+	}
+	magic, err := s.readWord("SiiNunit header")
+	if err != nil {
+		return err
+	}
+	if magic != "SiiNunit" {
+		return &ParseError{File: s.file, Line: s.line, Column: s.col - len(magic), Msg: fmt.Sprintf("expected SiiNunit header, found %q", magic)}
+	}
+	if err := s.skipTrivia(true); err != nil {
+		return err
+	}
+	return s.expect('{', "after SiiNunit")
+}
+
+// parseEnd checks that only whitespace and comments follow the closing brace
+// of SiiNunit.
+func (p *parser) parseEnd() error {
+	s := p.s
+	if err := s.skipTrivia(true); err != nil {
+		return err
+	}
+	if _, ok := s.peek(); ok {
+		return s.errorf("unexpected %s after the end of SiiNunit", s.describe())
+	}
+	return nil
+}
+
+// parseUnits parses blocks and @include directives until the closing brace
+// of SiiNunit (braced) or the end of an included file.
+func (p *parser) parseUnits(doc *Document, braced bool) error {
+	for {
+		done, err := p.parseUnit(doc, braced)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// parseUnit parses one block or @include directive and appends the blocks
+// it yields to doc. done is set at the closing brace of SiiNunit (braced) or
+// the end of an included file.
+func (p *parser) parseUnit(doc *Document, braced bool) (done bool, err error) {
+	s := p.s
+	if err := s.skipTrivia(true); err != nil {
+		return false, err
+	}
+	c, ok := s.peek()
+	switch {
+	case !ok && braced:
+		return false, s.errorf("unexpected end of file, missing '}' to close SiiNunit")
+	case !ok:
+		return true, nil
+	case c == '}' && braced:
+		s.next()
+		return true, nil
+	case c == '@':
+		path, err := p.parseInclude()
+		if err != nil {
+			return false, err
+		}
+		doc.Includes = append(doc.Includes, path)
+		if err := p.include(path, func(sub *parser) error { return sub.parseUnits(doc, false) }); err != nil {
+			return false, err
+		}
+	default:
+		block, err := p.parseBlock()
+		if err != nil {
+			return false, err
+		}
+		doc.Blocks = append(doc.Blocks, block)
+	}
+	return false, nil
+}
+
+// parseBlock parses "type : name { key: value ... }".
+func (p *parser) parseBlock() (*Block, error) {
+	s := p.s
+	var src *blockSource
+	if s.record {
+		src = &blockSource{leading: s.cut()}
+	}
+	typ, err := s.readWord("block type")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.skipTrivia(true); err != nil {
+		return nil, err
+	}
+	if err := s.expect(':', "after block type "+typ); err != nil {
+		return nil, err
+	}
+	if err := s.skipTrivia(true); err != nil {
+		return nil, err
+	}
+	name, err := s.readWord("block name")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.skipTrivia(true); err != nil {
+		return nil, err
+	}
+	if err := s.expect('{', "to open block "+name); err != nil {
+		return nil, err
+	}
+
+	block := &Block{Type: typ, Name: name, Properties: make(map[string][]string)}
+	if src != nil {
+		src.typ, src.name, src.header = typ, name, s.cut()
+		block.src = src
+	}
+	if err := p.parseProperties(block, true); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// parseProperties parses the body of a block up to its closing brace
+// (braced) or the end of an included file.
+func (p *parser) parseProperties(block *Block, braced bool) error {
+	s := p.s
+	for {
+		if err := s.skipTrivia(true); err != nil {
+			return err
+		}
+		c, ok := s.peek()
+		switch {
+		case !ok && braced:
+			return s.errorf("unexpected end of file in block %s", block.Name)
+		case !ok:
+			return nil
+		case c == '}' && braced:
+			s.next()
+			if block.src != nil {
+				block.src.closing = s.cut()
+			}
+			return nil
+		case c == '@':
+			path, err := p.parseInclude()
+			if err != nil {
+				return err
+			}
+			block.Includes = append(block.Includes, path)
+			if err := p.include(path, func(sub *parser) error { return sub.parseProperties(block, false) }); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, err := s.readWord("property name")
+		if err != nil {
+			return err
+		}
+		if err := s.skipTrivia(false); err != nil {
+			return err
+		}
+		if c, ok := s.peek(); ok && c == '{' {
+			return s.errorf("unexpected '{' after %q: missing '}' to close block %s", key, block.Name)
+		}
+		if err := s.expect(':', "after property "+key); err != nil {
+			return err
+		}
+		if err := s.skipTrivia(false); err != nil {
+			return err
+		}
+		var entry sourceEntry
+		if s.record {
+			entry.prefix = s.cut()
+		}
+		quoted := s.peekIs("\"")
+		value, err := s.readValue()
+		if err != nil {
+			return err
+		}
+		if s.record {
+			// Blanks trimmed off an unquoted value belong to the suffix.
+			entry.suffix = s.cut()[len(value):]
+		}
+		if err := s.skipTrivia(false); err != nil {
+			return err
+		}
+		if c, ok := s.peek(); quoted && ok && c != '\n' && c != '}' {
+			return s.errorf("unexpected %q after string value", c)
+		}
+		if s.record && block.src != nil {
+			entry.key = key
+			entry.suffix += s.cut()
+			// Keep a CR with its LF in the next prefix, so that dropping or
+			// appending lines never splits a CRLF.
+			if strings.HasSuffix(entry.suffix, "\r") {
+				entry.suffix = entry.suffix[:len(entry.suffix)-1]
+				s.rec = append(s.rec, '\r')
+			}
+			block.src.entries = append(block.src.entries, entry)
+		}
+		block.addProperty(key, value)
+	}
+}
+
+// parseInclude parses `@include "path"` and returns the path.
+func (p *parser) parseInclude() (string, error) {
+	s := p.s
+	line, col := s.line, s.col
+	s.next()
+	directive, err := s.readWord("directive")
+	if err != nil {
+		return "", err
+	}
+	if directive != "include" {
+		return "", &ParseError{File: s.file, Line: line, Column: col, Msg: fmt.Sprintf("unknown directive @%s", directive)}
+	}
+	if err := s.skipTrivia(false); err != nil {
+		return "", err
+	}
+	if c, ok := s.peek(); !ok || c != '"' {
+		return "", s.errorf("expected quoted path after @include, found %s", s.describe())
+	}
+	raw, err := s.readQuoted()
+	if err != nil {
+		return "", err
+	}
+	return unquote(raw), nil
+}
+
+// include parses the file named by an @include directive with parse, when
+// a resolver is configured.
+func (p *parser) include(path string, parse func(sub *parser) error) error {
+	if p.opts.ResolveInclude == nil {
+		return nil
+	}
+	if p.depth >= maxIncludeDepth {
+		return p.s.errorf("@include nested too deeply at %q", path)
+	}
+	data, err := p.opts.ResolveInclude(path)
+	if err != nil {
+		return fmt.Errorf("sii: @include %q: %w", path, err)
+	}
+	// Included text is not part of this file's source, so it is not recorded.
+	sub := &parser{s: newScanner(bytes.NewReader(data), path), opts: p.opts, depth: p.depth + 1}
+	return parse(sub)
+}
+
+// addProperty appends a value to key, keeping track of the key order.
+func (b *Block) addProperty(key, value string) {
+	if _, seen := b.Properties[key]; !seen {
+		b.PropertyOrder = append(b.PropertyOrder, key)
+	}
+	b.Properties[key] = append(b.Properties[key], value)
+}
+
+// unquote strips the quotes of a string value and resolves its escape
+// sequences (\", \\, \n, \t and \xNN). Unquoted values are returned as-is.
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 >= len(value) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'x':
+			if i+2 < len(value) {
+				var b byte
+				if _, err := fmt.Sscanf(value[i+1:i+3], "%02x", &b); err == nil {
+					sb.WriteByte(b)
+					i += 2
+					continue
+				}
+			}
+			sb.WriteString("\\x")
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
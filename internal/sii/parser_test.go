@@ -0,0 +1,173 @@
+package sii
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const trickySII = `SiiNunit
+{
+# header comment
+cargo_data : cargo.apples { // trailing comment
+ name: "Apples: red {and} green"  # comment after a string
+ /* a block comment
+    spanning lines with : and { */
+ volume: &3f400000
+ body_types[]: curtainside
+ body_types[]: refrigerated
+ @include "shared.sui"
+}
+a : x.one { v: 1 } b : x.two { v: (1, 2, 3) (4; 5, 6, 7) }
+@include "more.sui"
+}
+`
+
+func TestReadDocument_Grammar(t *testing.T) {
+	includes := map[string]string{
+		"shared.sui": "mass: 2000 // kg\n",
+		"more.sui":   "c : x.three {\n}\n",
+	}
+	doc, err := ReadDocumentWithOptions([]byte(trickySII), ReadOptions{
+		ResolveInclude: func(path string) ([]byte, error) {
+			return []byte(includes[path]), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	if len(doc.Blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(doc.Blocks))
+	}
+	cargo := doc.Blocks[0]
+	want := map[string][]string{
+		"name":         {`"Apples: red {and} green"`},
+		"volume":       {"&3f400000"},
+		"body_types[]": {"curtainside", "refrigerated"},
+		"mass":         {"2000"},
+	}
+	if !reflect.DeepEqual(cargo.Properties, want) {
+		t.Errorf("cargo properties = %#v, want %#v", cargo.Properties, want)
+	}
+	if !reflect.DeepEqual(cargo.PropertyOrder, []string{"name", "volume", "body_types[]", "mass"}) {
+		t.Errorf("cargo property order = %v", cargo.PropertyOrder)
+	}
+	if got := doc.Blocks[2].Properties["v"]; !reflect.DeepEqual(got, []string{"(1, 2, 3) (4; 5, 6, 7)"}) {
+		t.Errorf("inline block value = %q", got)
+	}
+	if doc.Blocks[3].Name != "x.three" {
+		t.Errorf("included block = %s : %s", doc.Blocks[3].Type, doc.Blocks[3].Name)
+	}
+	if !reflect.DeepEqual(cargo.Includes, []string{"shared.sui"}) || !reflect.DeepEqual(doc.Includes, []string{"more.sui"}) {
+		t.Errorf("includes = %v / %v", cargo.Includes, doc.Includes)
+	}
+}
+
+func TestReadDocument_ErrorPosition(t *testing.T) {
+	_, err := ReadDocument([]byte("SiiNunit\n{\nbank : _nameless.1 {\n money: \"unterminated\n}\n}\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *ParseError", err)
+	}
+	if perr.Line != 4 || perr.Column != 9 {
+		t.Errorf("error at line %d, column %d, want line 4, column 9 (%v)", perr.Line, perr.Column, perr)
+	}
+}
+
+func TestWriteDocument_Lossless(t *testing.T) {
+	src := strings.ReplaceAll(trickySII, "\n", "\r\n") + "// end\r\n"
+	read := func() *Document {
+		doc, err := ReadDocumentWithOptions([]byte(src), ReadOptions{Lossless: true})
+		if err != nil {
+			t.Fatalf("ReadDocumentWithOptions: %v", err)
+		}
+		return doc
+	}
+
+	out, err := WriteDocument(read())
+	if err != nil {
+		t.Fatalf("WriteDocument: %v", err)
+	}
+	if string(out) != src {
+		t.Fatalf("round trip differs:\n%q\nwant\n%q", out, src)
+	}
+
+	doc := read()
+	doc.Blocks[0].Properties["volume"] = []string{"1.5"}
+	doc.Blocks[0].Properties["body_types[]"] = []string{"curtainside"}
+	doc.Blocks[0].Properties["units_count"] = []string{"2"}
+	out, _ = WriteDocument(doc)
+	want := strings.Replace(src, " volume: &3f400000", " volume: 1.5", 1)
+	want = strings.Replace(want, "\r\n body_types[]: refrigerated", "", 1)
+	want = strings.Replace(want, "\r\n @include", "\r\n units_count: 2\r\n @include", 1)
+	if string(out) != want {
+		t.Fatalf("edited output:\n%q\nwant\n%q", out, want)
+	}
+}
+
+func TestWriteDocument_GrownArray(t *testing.T) {
+	doc, err := ReadDocument([]byte("SiiNunit\n{\ngarage : garage.x {\n vehicles: 0\n status: 0\n}\n}\n"))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	PutArray(doc.Blocks[0].Properties, "vehicles", Array{Token("null")})
+	out, _ := WriteDocument(doc)
+	want := "SiiNunit\n{\ngarage : garage.x {\n vehicles: 1\n vehicles[0]: null\n status: 0\n}\n}\n"
+	if string(out) != want {
+		t.Fatalf("output:\n%q\nwant\n%q", out, want)
+	}
+}
+
+func TestReader_MatchesReadDocument(t *testing.T) {
+	resolve := func(path string) ([]byte, error) {
+		return []byte(map[string]string{
+			"shared.sui": "mass: 2000 // kg\n",
+			"more.sui":   "c : x.three {\n}\n",
+		}[path]), nil
+	}
+	doc, err := ReadDocumentWithOptions([]byte(trickySII), ReadOptions{ResolveInclude: resolve})
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	want, err := WriteDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReaderWithOptions(strings.NewReader(trickySII), ReadOptions{ResolveInclude: resolve})
+	var got strings.Builder
+	w := NewWriter(&got)
+	for {
+		b, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if err := w.WriteBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("streamed output differs:\n got %q\nwant %q", got.String(), want)
+	}
+	if !reflect.DeepEqual(r.Includes(), []string{"more.sui"}) {
+		t.Errorf("includes = %v", r.Includes())
+	}
+
+	truncated := NewReader(strings.NewReader("SiiNunit\n{\na : x.one {\n}\n"))
+	if _, err := truncated.Next(); err != nil {
+		t.Fatalf("first block of truncated document: %v", err)
+	}
+	var perr *ParseError
+	if _, err := truncated.Next(); !errors.As(err, &perr) {
+		t.Errorf("missing closing brace: got %v, want *ParseError", err)
+	}
+}
@@ -0,0 +1,440 @@
+package sii
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PointerKind tells how a property refers to other units.
+type PointerKind int
+
+const (
+	// NotPointer is a property that holds no unit name.
+	NotPointer PointerKind = iota
+	// PointerOwner refers to a unit owned by the block: it is written right
+	// after its owner and deleted along with it.
+	PointerOwner
+	// PointerLink refers to a unit owned by some other block.
+	PointerLink
+)
+
+func (k PointerKind) String() string {
+	switch k {
+	case PointerOwner:
+		return "owner"
+	case PointerLink:
+		return "link"
+	default:
+		return "none"
+	}
+}
+
+// PointerSchema tells which properties of the blocks hold pointers.
+type PointerSchema struct {
+	// Kinds maps a block type and a property name to the kind of pointer
+	// the property holds. Array properties are listed with a "[]" suffix
+	// ("accessories[]"), so that their element count is not taken for a
+	// pointer.
+	//
+	// Properties missing from Kinds are treated as links when their value
+	// is a nameless unit name, since nothing else is written that way.
+	Kinds map[string]map[string]PointerKind
+
+	// Parallel lists, for a block type, the groups of arrays whose elements
+	// go together: element i of each array of a group is about the same
+	// thing, such as player.trucks[i] and player.truck_profit_logs[i].
+	// Arrays are named without the "[]" suffix.
+	Parallel map[string][][]string
+}
+
+// Kind returns the pointer kind of key ("profit_log", "accessories[3]" or
+// "accessories[]") in blocks of type typ.
+func (s PointerSchema) Kind(typ, key string) PointerKind {
+	if base := arrayBase(key); base != key {
+		key = base + "[]"
+	}
+	return s.Kinds[typ][key]
+}
+
+// Reference is one pointer value held by a block.
+type Reference struct {
+	Block  string // name of the block holding the pointer
+	Key    string // property, such as "accessories[3]"
+	Target string // name of the unit pointed to
+	Kind   PointerKind
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s %s -> %s (%s)", r.Block, r.Key, r.Target, r.Kind)
+}
+
+// References returns the non-null pointers held by b, in property order.
+func (s PointerSchema) References(b *Block) []Reference {
+	var refs []Reference
+	for _, key := range orderedKeys(b) {
+		kind := s.Kind(b.Type, key)
+		for _, v := range b.Properties[key] {
+			target := ParsePointer(v)
+			if target == "" {
+				continue
+			}
+			switch {
+			case kind != NotPointer:
+				refs = append(refs, Reference{Block: b.Name, Key: key, Target: target, Kind: kind})
+			case strings.HasPrefix(target, namelessPrefix):
+				refs = append(refs, Reference{Block: b.Name, Key: key, Target: target, Kind: PointerLink})
+			}
+		}
+	}
+	return refs
+}
+
+// namelessPrefix starts the names the game gives to units that are only
+// reachable through pointers.
+const namelessPrefix = "_nameless."
+
+// Owned returns the names of the blocks owned by the named block, directly
+// or through other owned blocks, in document order.
+func (d *Document) Owned(schema PointerSchema, name string) []string {
+	owned := d.ownedSet(schema, name)
+	var out []string
+	for _, b := range d.Blocks {
+		if owned[b] {
+			out = append(out, b.Name)
+		}
+	}
+	return out
+}
+
+// ownedSet returns the blocks owned by the named block, directly or not.
+func (d *Document) ownedSet(schema PointerSchema, name string) map[*Block]bool {
+	owned := make(map[*Block]bool)
+	root := d.Block(name)
+	if root == nil {
+		return owned
+	}
+	queue := []*Block{root}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		for _, child := range d.ownedBy(schema, b) {
+			if child != root && !owned[child] {
+				owned[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return owned
+}
+
+// ownedBy returns the blocks b points at through owner pointers, in no
+// particular order. It is the fast path of References for ownership walks.
+func (d *Document) ownedBy(schema PointerSchema, b *Block) []*Block {
+	kinds := schema.Kinds[b.Type]
+	if len(kinds) == 0 {
+		return nil
+	}
+	var out []*Block
+	for key, vals := range b.Properties {
+		if schema.Kind(b.Type, key) != PointerOwner {
+			continue
+		}
+		for _, v := range vals {
+			if child := d.Block(v); child != nil {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// ownedEnd returns the position after the last block of the subtree owned by
+// ob: its last owned block in document order, followed by what that block
+// owns itself.
+func (d *Document) ownedEnd(schema PointerSchema, ob *Block) int {
+	pending := make(map[*Block]bool)
+	pending[ob] = true
+	for _, child := range d.ownedBy(schema, ob) {
+		pending[child] = true
+	}
+
+	// Blocks are only compared until the last direct child is found, so
+	// that owners near the start of a large document stay cheap.
+	end, i := 0, 0
+	var last *Block
+	for ; i < len(d.Blocks) && len(pending) > 0; i++ {
+		if b := d.Blocks[i]; pending[b] {
+			delete(pending, b)
+			end, last = i+1, b
+		}
+	}
+	if last == ob {
+		return end
+	}
+	pending = d.ownedSet(schema, last.Name)
+	for ; i < len(d.Blocks) && len(pending) > 0; i++ {
+		if b := d.Blocks[i]; pending[b] {
+			delete(pending, b)
+			end = i + 1
+		}
+	}
+	return end
+}
+
+// AddOwned inserts blocks after the last unit owned by owner (and the units
+// that one owns), and points the owner pointer key at them: the elements are appended when key
+// is an array, and the value is replaced otherwise (with a single block).
+// key must be an owner pointer of the owner type in schema.
+func (d *Document) AddOwned(schema PointerSchema, owner, key string, blocks ...*Block) error {
+	ob := d.Block(owner)
+	if ob == nil {
+		return fmt.Errorf("sii: add to %s: no such block", owner)
+	}
+	array := schema.Kinds[ob.Type][key+"[]"] == PointerOwner
+	if !array && schema.Kinds[ob.Type][key] != PointerOwner {
+		return fmt.Errorf("sii: add to %s: %s is not an owner pointer of %s", owner, key, ob.Type)
+	}
+	if !array && len(blocks) != 1 {
+		return fmt.Errorf("sii: add to %s: %s holds a single pointer", owner, key)
+	}
+
+	at := d.ownedEnd(schema, ob)
+	names := make([]string, len(blocks))
+	for i, b := range blocks {
+		if err := d.Insert(at+i, b); err != nil {
+			return err
+		}
+		names[i] = b.Name
+	}
+
+	if !array {
+		ob.Properties[key] = names
+		return nil
+	}
+	if vals, ok := ob.Properties[key+"[]"]; ok {
+		ob.Properties[key+"[]"] = append(vals, names...)
+		return nil
+	}
+	values := pointerArray(append(GetArray(ob.Properties, key), names...), PointerOwner)
+	PutArray(ob.Properties, key, values)
+	return nil
+}
+
+// Replace swaps the block with the same name as b for b, keeping its place
+// in the document. A block read with ReadOptions.Lossless passes its source
+// text on to b, so that unchanged lines are still written as they were.
+func (d *Document) Replace(b *Block) error {
+	idx := d.index()
+	old, ok := idx.byName[b.Name]
+	if !ok {
+		return fmt.Errorf("sii: replace %s: no such block", b.Name)
+	}
+	if b.Properties == nil {
+		b.Properties = make(map[string][]string)
+	}
+	if b.src == nil {
+		b.src = old.src
+	}
+	for i := range d.Blocks {
+		if d.Blocks[i] == old {
+			d.Blocks[i] = b
+			break
+		}
+	}
+	if old.Type != b.Type {
+		return d.Reindex()
+	}
+	idx.byName[b.Name] = b
+	sameType := idx.byType[b.Type]
+	for i := range sameType {
+		if sameType[i] == old {
+			sameType[i] = b
+			break
+		}
+	}
+	return nil
+}
+
+// RemoveCascade deletes the named blocks together with every block they
+// own, and detaches the remaining pointers to them: owner array elements are
+// dropped, other pointers are set to null (so that garage slots keep their
+// position). When the dropped element belongs to parallel arrays, the same
+// index is dropped from every array of the group, and the units owned
+// through them are deleted as well. It returns how many blocks were deleted.
+func (d *Document) RemoveCascade(schema PointerSchema, names ...string) int {
+	doomed := make(map[string]bool)
+	doom := func(name string) {
+		doomed[name] = true
+		for b := range d.ownedSet(schema, name) {
+			doomed[b.Name] = true
+		}
+	}
+	for _, name := range names {
+		if d.Block(name) != nil {
+			doom(name)
+		}
+	}
+	if len(doomed) == 0 {
+		return 0
+	}
+
+	// Units paired with doomed ones in parallel arrays go with them, which
+	// can in turn doom elements of other groups.
+	for changed := true; changed; {
+		changed = false
+		for _, b := range d.Blocks {
+			if doomed[b.Name] {
+				continue
+			}
+			for _, group := range schema.Parallel[b.Type] {
+				for _, key := range group {
+					if schema.Kinds[b.Type][key+"[]"] != PointerOwner {
+						continue
+					}
+					vals := GetArray(b.Properties, key)
+					for i := range parallelIndices(b, group, doomed) {
+						if i >= len(vals) {
+							continue
+						}
+						if name := ParsePointer(vals[i]); name != "" && !doomed[name] && d.Block(name) != nil {
+							doom(name)
+							changed = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, b := range d.Blocks {
+		if !doomed[b.Name] {
+			detach(schema, b, doomed)
+		}
+	}
+
+	list := make([]string, 0, len(doomed))
+	for name := range doomed {
+		list = append(list, name)
+	}
+	return d.Remove(list...)
+}
+
+// detach removes the pointers of b to the doomed units.
+func detach(schema PointerSchema, b *Block, doomed map[string]bool) {
+	parallel := make(map[string]bool)
+	for _, group := range schema.Parallel[b.Type] {
+		drop := parallelIndices(b, group, doomed)
+		for _, key := range group {
+			parallel[key] = true
+			if len(drop) > 0 {
+				dropIndices(b.Properties, key, drop)
+			}
+		}
+	}
+
+	arrays := make(map[string]PointerKind)
+	for _, ref := range schema.References(b) {
+		if !doomed[ref.Target] {
+			continue
+		}
+		if base := arrayBase(ref.Key); base != ref.Key {
+			if !parallel[base] {
+				arrays[base] = ref.Kind
+			}
+			continue
+		}
+		for i, v := range b.Properties[ref.Key] {
+			if v == ref.Target {
+				b.Properties[ref.Key][i] = Null
+			}
+		}
+	}
+
+	for base, kind := range arrays {
+		if vals, ok := b.Properties[base+"[]"]; ok {
+			b.Properties[base+"[]"] = keepPointers(vals, kind, doomed)
+			continue
+		}
+		values := keepPointers(GetArray(b.Properties, base), kind, doomed)
+		PutArray(b.Properties, base, pointerArray(values, kind))
+	}
+}
+
+// parallelIndices returns the indices at which an array of group points at a
+// doomed unit.
+func parallelIndices(b *Block, group []string, doomed map[string]bool) map[int]bool {
+	drop := make(map[int]bool)
+	for _, key := range group {
+		for i, v := range GetArray(b.Properties, key) {
+			if doomed[ParsePointer(v)] {
+				drop[i] = true
+			}
+		}
+	}
+	return drop
+}
+
+// dropIndices removes the elements at the drop indices from the array key,
+// keeping the other values as they were written.
+func dropIndices(props map[string][]string, key string, drop map[int]bool) {
+	vals := GetArray(props, key)
+	if len(vals) == 0 {
+		return
+	}
+	var kept []string
+	for i, v := range vals {
+		if !drop[i] {
+			kept = append(kept, v)
+		}
+	}
+	if _, ok := props[key+"[]"]; ok {
+		props[key+"[]"] = kept
+		return
+	}
+	values := make(Array, len(kept))
+	for i, v := range kept {
+		values[i] = Token(v)
+	}
+	PutArray(props, key, values)
+}
+
+// keepPointers drops the owner pointers to doomed units, and nulls the
+// links.
+func keepPointers(vals []string, kind PointerKind, doomed map[string]bool) []string {
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		switch {
+		case !doomed[v]:
+			out = append(out, v)
+		case kind != PointerOwner:
+			out = append(out, Null)
+		}
+	}
+	return out
+}
+
+func pointerArray(vals []string, kind PointerKind) Array {
+	out := make(Array, len(vals))
+	for i, v := range vals {
+		if kind == PointerOwner {
+			out[i] = OwnerPointer(ParsePointer(v))
+		} else {
+			out[i] = LinkPointer(ParsePointer(v))
+		}
+	}
+	return out
+}
+
+// DanglingReferences lists the pointers to units that are not in the
+// document.
+func (d *Document) DanglingReferences(schema PointerSchema) []Reference {
+	var out []Reference
+	for _, b := range d.Blocks {
+		for _, ref := range schema.References(b) {
+			if d.Block(ref.Target) == nil {
+				out = append(out, ref)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,225 @@
+package sii
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// docSource keeps the text of a document read with ReadOptions.Lossless that
+// is not part of any block: everything up to the opening brace of SiiNunit,
+// and everything after the closing brace of the last block.
+type docSource struct {
+	head string
+	tail string
+}
+
+// blockSource keeps the original text of a block so that unchanged parts can
+// be written back as they were read.
+type blockSource struct {
+	leading string // blank lines and comments before the block type
+	typ     string
+	name    string
+	header  string // "type : name {" as written
+	entries []sourceEntry
+	closing string // text before and including the closing brace
+}
+
+// sourceEntry is one "key: value" line. prefix holds everything from the end
+// of the previous entry up to the value (line break, indentation, comments
+// on previous lines, key and colon); suffix holds what follows the value on
+// the same line.
+type sourceEntry struct {
+	key    string
+	prefix string
+	suffix string
+}
+
+// indent returns the indentation in front of the key.
+func (e sourceEntry) indent() string {
+	line := e.prefix[strings.LastIndexByte(e.prefix, '\n')+1:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// newline returns the line break style of the document.
+func (s *docSource) newline() string {
+	if strings.Contains(s.head, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// writeLossless writes a document read with ReadOptions.Lossless. Unchanged
+// text is copied from the source; changed values are replaced in place,
+// removed values drop their line, and new properties and blocks are written
+// in the standard format next to their siblings.
+func writeLossless(buf *bytes.Buffer, doc *Document) {
+	nl := doc.src.newline()
+	leading := nl + nl
+	buf.WriteString(doc.src.head)
+	for i := range doc.Blocks {
+		b := doc.Blocks[i]
+		src := b.src
+		if src == nil {
+			src = &blockSource{leading: leading, closing: nl + "}"}
+		} else {
+			leading = src.leading
+		}
+		buf.WriteString(src.leading)
+		if src.header != "" && src.typ == b.Type && src.name == b.Name {
+			buf.WriteString(src.header)
+		} else {
+			buf.WriteString(b.Type + " : " + b.Name + " {")
+		}
+		writeLosslessProperties(buf, b, src, nl)
+		buf.WriteString(src.closing)
+	}
+	buf.WriteString(doc.src.tail)
+}
+
+func writeLosslessProperties(buf *bytes.Buffer, b *Block, src *blockSource, nl string) {
+	// Locate the last source entry of every key and array so that added
+	// values land right after their siblings.
+	lastOfKey := make(map[string]int)
+	lastOfBase := make(map[string]int)
+	for i, e := range src.entries {
+		lastOfKey[e.key] = i
+		lastOfBase[arrayBase(e.key)] = i
+	}
+
+	indent := " "
+	if len(src.entries) > 0 {
+		indent = src.entries[0].indent()
+	}
+	writeNew := func(indent, key string, values []string) {
+		for _, v := range values {
+			buf.WriteString(nl + indent + key + ": " + v)
+		}
+	}
+
+	// Keys missing from the source.
+	var added []string
+	for _, k := range orderedKeys(b) {
+		if _, ok := lastOfKey[k]; !ok {
+			added = append(added, k)
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, e := range src.entries {
+		values := b.Properties[e.key]
+		n := seen[e.key]
+		seen[e.key] = n + 1
+		if n < len(values) {
+			buf.WriteString(e.prefix + values[n] + e.suffix)
+		}
+		if lastOfKey[e.key] == i && len(values) > n+1 {
+			writeNew(e.indent(), e.key, values[n+1:])
+		}
+		if base := arrayBase(e.key); lastOfBase[base] == i {
+			var siblings []string
+			for _, k := range added {
+				if arrayBase(k) == base {
+					siblings = append(siblings, k)
+				}
+			}
+			sort.Slice(siblings, func(i, j int) bool { return lessKey(siblings[i], siblings[j]) })
+			for _, k := range siblings {
+				writeNew(e.indent(), k, b.Properties[k])
+			}
+		}
+	}
+	for _, k := range added {
+		if _, ok := lastOfBase[arrayBase(k)]; !ok {
+			writeNew(indent, k, b.Properties[k])
+		}
+	}
+}
+
+// orderedKeys returns the keys of b in PropertyOrder, followed by the keys
+// missing from it in natural order. Missing elements of a listed array are
+// placed right after the last listed key of that array instead, so that
+// grown arrays stay in one piece.
+func orderedKeys(b *Block) []string {
+	listed := make(map[string]bool, len(b.PropertyOrder))
+	var order []string
+	for _, k := range b.PropertyOrder {
+		if _, ok := b.Properties[k]; ok && !listed[k] {
+			order = append(order, k)
+			listed[k] = true
+		}
+	}
+	var rest []string
+	for k := range b.Properties {
+		if !listed[k] {
+			rest = append(rest, k)
+		}
+	}
+	if len(rest) == 0 {
+		return order
+	}
+	sort.Slice(rest, func(i, j int) bool { return lessKey(rest[i], rest[j]) })
+
+	lastOfBase := make(map[string]int, len(order))
+	for i, k := range order {
+		lastOfBase[arrayBase(k)] = i
+	}
+	siblings := make(map[string][]string)
+	var tail []string
+	for _, k := range rest {
+		if base := arrayBase(k); base != k {
+			if _, ok := lastOfBase[base]; ok {
+				siblings[base] = append(siblings[base], k)
+				continue
+			}
+		}
+		tail = append(tail, k)
+	}
+
+	keys := make([]string, 0, len(b.Properties))
+	for i, k := range order {
+		keys = append(keys, k)
+		base := arrayBase(k)
+		if lastOfBase[base] == i {
+			keys = append(keys, siblings[base]...)
+		}
+	}
+	return append(keys, tail...)
+}
+
+// arrayBase returns "name" for "name[3]" and "name[]", and key otherwise.
+func arrayBase(key string) string {
+	if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+		return key[:i]
+	}
+	return key
+}
+
+// arrayIndex returns 3 for "name[3]"; ok is false for other keys.
+func arrayIndex(key string) (int, bool) {
+	base := arrayBase(key)
+	if base == key {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(key[len(base)+1 : len(key)-1])
+	return idx, err == nil
+}
+
+// lessKey orders keys by name, with the array count first and the elements
+// in index order (so that "trucks[10]" comes after "trucks[9]").
+func lessKey(a, b string) bool {
+	baseA, baseB := arrayBase(a), arrayBase(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	if a == baseA || b == baseB {
+		return a == baseA && b != baseB
+	}
+	idxA, okA := arrayIndex(a)
+	idxB, okB := arrayIndex(b)
+	if okA && okB {
+		return idxA < idxB
+	}
+	return a < b
+}
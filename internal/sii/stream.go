@@ -0,0 +1,120 @@
+package sii
+
+import (
+	"bufio"
+	"io"
+)
+
+// Reader parses SII text from an io.Reader one block at a time, so that a
+// whole save never has to be held in memory. Blocks of top-level @include
+// directives are returned in place when ReadOptions.ResolveInclude is set.
+type Reader struct {
+	p       *parser
+	pending *Document // blocks parsed but not yet returned, and includes
+	started bool
+	done    bool
+	err     error
+}
+
+// NewReader returns a Reader for plaintext SII content.
+func NewReader(r io.Reader) *Reader {
+	return NewReaderWithOptions(r, ReadOptions{})
+}
+
+// NewReaderWithOptions is NewReader with explicit options. Lossless is not
+// supported by the streaming reader and is ignored.
+func NewReaderWithOptions(r io.Reader, opts ReadOptions) *Reader {
+	opts.Lossless = false
+	return &Reader{
+		p:       &parser{s: newScanner(r, ""), opts: opts},
+		pending: &Document{},
+	}
+}
+
+// Next returns the next block of the document. It returns io.EOF after the
+// closing brace of SiiNunit; syntax errors are returned as *ParseError.
+func (r *Reader) Next() (*Block, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !r.started {
+		r.started = true
+		if err := r.p.parseHeader(); err != nil {
+			r.err = err
+			return nil, err
+		}
+	}
+	for len(r.pending.Blocks) == 0 {
+		if r.done {
+			r.err = io.EOF
+			return nil, io.EOF
+		}
+		done, err := r.p.parseUnit(r.pending, true)
+		if err == nil && done {
+			err = r.p.parseEnd()
+		}
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		r.done = done
+	}
+	b := r.pending.Blocks[0]
+	r.pending.Blocks[0] = nil
+	r.pending.Blocks = r.pending.Blocks[1:]
+	return b, nil
+}
+
+// Includes returns the paths of the top-level @include directives read so
+// far.
+func (r *Reader) Includes() []string {
+	return r.pending.Includes
+}
+
+// Writer writes SII text one block at a time in the same format as
+// WriteDocument. Close must be called to write the closing brace.
+type Writer struct {
+	w       *bufio.Writer
+	started bool
+	blocks  int
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) start() {
+	if !w.started {
+		w.started = true
+		w.w.WriteString("SiiNunit\n{\n")
+	}
+}
+
+// WriteBlock writes b, with its properties in PropertyOrder followed by the
+// other keys in natural order.
+func (w *Writer) WriteBlock(b *Block) error {
+	w.start()
+	// Blank line between blocks
+	if w.blocks > 0 {
+		w.w.WriteString("\n")
+	}
+	w.blocks++
+
+	w.w.WriteString(b.Type + " : " + b.Name + " {\n")
+	for _, k := range orderedKeys(b) {
+		for _, v := range b.Properties[k] {
+			w.w.WriteString(" " + k + ": " + v + "\n")
+		}
+	}
+	_, err := w.w.WriteString("}\n")
+	return err
+}
+
+// Close writes the closing brace of SiiNunit and flushes the output. It does
+// not close the underlying writer.
+func (w *Writer) Close() error {
+	w.start()
+	w.w.WriteString("}\n")
+	return w.w.Flush()
+}
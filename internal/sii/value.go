@@ -0,0 +1,474 @@
+package sii
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// This file is the typed view of property values. Block.Properties keeps the
+// raw text; the types below parse it and format it back the way the game
+// writes it, so that item types do not each carry their own helpers.
+
+// Value is a typed property value. Format returns its SII text form.
+type Value interface {
+	Format() string
+}
+
+// Nil is the text of an unset integer or float, and Null the text of an
+// unset pointer.
+const (
+	Nil  = "nil"
+	Null = "null"
+)
+
+// ErrNil is returned by the number parsers for a "nil" value.
+var ErrNil = errors.New("sii: value is nil")
+
+// Float is a float32 written as an integer when it has no fractional part
+// and as "&" hex bits otherwise, mirroring NumericUtilities.SingleFloatToString.
+type Float float32
+
+// HexFloat is a float32 always written as "&" hex bits, e.g. "&3f400000".
+type HexFloat float32
+
+// Int is a signed integer, Uint an unsigned one (e.g. company_check_hash).
+type Int int64
+type Uint uint64
+
+// Bool is written as "true" or "false".
+type Bool bool
+
+// Vec2f is "(x, y)"; Vec3f is "(x, y, z)"; Vec3i is "(x, y, z)" with
+// integer components.
+type Vec2f struct {
+	X, Y float32
+}
+type Vec3f struct {
+	X, Y, Z float32
+}
+type Vec3i struct {
+	X, Y, Z int32
+}
+
+// Quat is a rotation written "(w; x, y, z)".
+type Quat struct {
+	X, Y, Z, W float32
+}
+
+// Placement is a position followed by a rotation, "(x, y, z) (w; x, y, z)",
+// the Vector_3f_4f type of the C# code.
+type Placement struct {
+	Position Vec3f
+	Rotation Quat
+}
+
+// Token is a bare identifier of up to 12 characters out of [0-9a-z_], such
+// as a city or a brand name.
+type Token string
+
+// OwnerPointer names a unit owned by the block (deleted along with it), and
+// LinkPointer a unit merely referenced. The empty pointer is written "null".
+type OwnerPointer string
+type LinkPointer string
+
+// String is a text value, written quoted unless it only uses [0-9A-Za-z_].
+type String string
+
+// Array is the element list of an array property; see GetArray and PutArray.
+type Array []Value
+
+func (f Float) Format() string { return FormatFloat(float32(f)) }
+
+func (f HexFloat) Format() string { return FormatHexFloat(float32(f)) }
+
+func (i Int) Format() string { return strconv.FormatInt(int64(i), 10) }
+
+func (u Uint) Format() string { return strconv.FormatUint(uint64(u), 10) }
+
+func (b Bool) Format() string { return strconv.FormatBool(bool(b)) }
+
+func (v Vec2f) Format() string {
+	return "(" + FormatFloat(v.X) + ", " + FormatFloat(v.Y) + ")"
+}
+
+func (v Vec3f) Format() string {
+	return "(" + FormatFloat(v.X) + ", " + FormatFloat(v.Y) + ", " + FormatFloat(v.Z) + ")"
+}
+
+func (v Vec3i) Format() string {
+	return fmt.Sprintf("(%d, %d, %d)", v.X, v.Y, v.Z)
+}
+
+func (q Quat) Format() string {
+	return "(" + FormatFloat(q.W) + "; " + FormatFloat(q.X) + ", " + FormatFloat(q.Y) + ", " + FormatFloat(q.Z) + ")"
+}
+
+func (p Placement) Format() string { return p.Position.Format() + " " + p.Rotation.Format() }
+
+func (t Token) Format() string { return string(t) }
+
+func (p OwnerPointer) Format() string { return formatPointer(string(p)) }
+
+func (p LinkPointer) Format() string { return formatPointer(string(p)) }
+
+func (s String) Format() string { return Quote(string(s)) }
+
+func formatPointer(name string) string {
+	if name == "" {
+		return Null
+	}
+	return name
+}
+
+// FormatFloat formats f the way the game does: "3" or "&3f400000".
+func FormatFloat(f float32) string {
+	if f-float32(math.Trunc(float64(f))) != 0 || math.Abs(float64(f)) >= 1e7 {
+		return FormatHexFloat(f)
+	}
+	return strconv.FormatInt(int64(f), 10)
+}
+
+// FormatHexFloat formats the bits of f, e.g. "&3f400000" for 0.75.
+func FormatHexFloat(f float32) string {
+	return fmt.Sprintf("&%08x", math.Float32bits(f))
+}
+
+// ParseFloat parses "&3f400000" or a decimal number.
+func ParseFloat(s string) (float32, error) {
+	s = strings.TrimSpace(s)
+	if s == Nil {
+		return 0, ErrNil
+	}
+	if strings.HasPrefix(s, "&") {
+		bits, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("sii: invalid hex float %q", s)
+		}
+		return math.Float32frombits(uint32(bits)), nil
+	}
+	f, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return 0, fmt.Errorf("sii: invalid float %q", s)
+	}
+	return float32(f), nil
+}
+
+// ParseInt parses a signed integer.
+func ParseInt(s string) (int64, error) {
+	if s == Nil {
+		return 0, ErrNil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("sii: invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// ParseUint parses an unsigned integer.
+func ParseUint(s string) (uint64, error) {
+	if s == Nil {
+		return 0, ErrNil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("sii: invalid unsigned integer %q", s)
+	}
+	return v, nil
+}
+
+// ParseBool parses "true" or "false" (case-insensitively).
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("sii: invalid bool %q", s)
+}
+
+// parseTuples splits "(1, 2, 3) (4; 5, 6, 7)" into its parenthesized groups
+// and their components.
+func parseTuples(s string) ([][]string, error) {
+	var groups [][]string
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		if rest[0] != '(' {
+			return nil, fmt.Errorf("sii: invalid vector %q", s)
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return nil, fmt.Errorf("sii: invalid vector %q", s)
+		}
+		parts := strings.FieldsFunc(rest[1:end], func(r rune) bool { return r == ',' || r == ';' })
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		groups = append(groups, parts)
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return groups, nil
+}
+
+// parseFloats parses a single group of n float components.
+func parseFloats(s string, n int) ([]float32, error) {
+	groups, err := parseTuples(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) != 1 || len(groups[0]) != n {
+		return nil, fmt.Errorf("sii: expected %d components in %q", n, s)
+	}
+	return parseFloatGroup(groups[0])
+}
+
+func parseFloatGroup(parts []string) ([]float32, error) {
+	out := make([]float32, len(parts))
+	for i, p := range parts {
+		f, err := ParseFloat(p)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// ParseVec2f parses "(x, y)".
+func ParseVec2f(s string) (Vec2f, error) {
+	f, err := parseFloats(s, 2)
+	if err != nil {
+		return Vec2f{}, err
+	}
+	return Vec2f{X: f[0], Y: f[1]}, nil
+}
+
+// ParseVec3f parses "(x, y, z)".
+func ParseVec3f(s string) (Vec3f, error) {
+	f, err := parseFloats(s, 3)
+	if err != nil {
+		return Vec3f{}, err
+	}
+	return Vec3f{X: f[0], Y: f[1], Z: f[2]}, nil
+}
+
+// ParseVec3i parses "(x, y, z)" with integer components.
+func ParseVec3i(s string) (Vec3i, error) {
+	groups, err := parseTuples(s)
+	if err != nil {
+		return Vec3i{}, err
+	}
+	if len(groups) != 1 || len(groups[0]) != 3 {
+		return Vec3i{}, fmt.Errorf("sii: expected 3 components in %q", s)
+	}
+	var v [3]int32
+	for i, p := range groups[0] {
+		n, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return Vec3i{}, fmt.Errorf("sii: invalid integer %q in %q", p, s)
+		}
+		v[i] = int32(n)
+	}
+	return Vec3i{X: v[0], Y: v[1], Z: v[2]}, nil
+}
+
+// ParseQuat parses "(w; x, y, z)".
+func ParseQuat(s string) (Quat, error) {
+	f, err := parseFloats(s, 4)
+	if err != nil {
+		return Quat{}, err
+	}
+	return Quat{W: f[0], X: f[1], Y: f[2], Z: f[3]}, nil
+}
+
+// ParsePlacement parses "(x, y, z) (w; x, y, z)".
+func ParsePlacement(s string) (Placement, error) {
+	groups, err := parseTuples(s)
+	if err != nil {
+		return Placement{}, err
+	}
+	if len(groups) != 2 || len(groups[0]) != 3 || len(groups[1]) != 4 {
+		return Placement{}, fmt.Errorf("sii: invalid placement %q", s)
+	}
+	pos, err := parseFloatGroup(groups[0])
+	if err != nil {
+		return Placement{}, err
+	}
+	rot, err := parseFloatGroup(groups[1])
+	if err != nil {
+		return Placement{}, err
+	}
+	return Placement{
+		Position: Vec3f{X: pos[0], Y: pos[1], Z: pos[2]},
+		Rotation: Quat{W: rot[0], X: rot[1], Y: rot[2], Z: rot[3]},
+	}, nil
+}
+
+// ParseToken checks that s is a valid token.
+func ParseToken(s string) (Token, error) {
+	if len(s) == 0 || len(s) > 12 {
+		return "", fmt.Errorf("sii: invalid token %q", s)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c == '_') {
+			return "", fmt.Errorf("sii: invalid token %q", s)
+		}
+	}
+	return Token(s), nil
+}
+
+// ParsePointer returns the unit name a pointer refers to, or "" for "null".
+func ParsePointer(s string) string {
+	if s == Null {
+		return ""
+	}
+	return s
+}
+
+// Quote formats a string value: bare when it only uses [0-9A-Za-z_] (as the
+// BSII decoder does), quoted with \", \\, \n, \t and \xNN escapes otherwise.
+func Quote(s string) string {
+	bare := s != ""
+	for i := 0; i < len(s) && bare; i++ {
+		c := s[i]
+		bare = c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+	}
+	if bare {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < 0x20:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// Unquote is the reverse of Quote. Bare values are returned as-is.
+func Unquote(s string) string {
+	return unquote(s)
+}
+
+// ParseValue guesses the type of a raw value from its text. Pointers are
+// reported as LinkPointer since the text does not tell owners from links.
+func ParseValue(s string) (Value, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return String(Unquote(s)), nil
+	case s == "true" || s == "false":
+		return Bool(s == "true"), nil
+	case s == Null:
+		return LinkPointer(""), nil
+	case strings.HasPrefix(s, "&"):
+		f, err := ParseFloat(s)
+		return HexFloat(f), err
+	case strings.HasPrefix(s, "("):
+		groups, err := parseTuples(s)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(groups) == 2:
+			return ParsePlacement(s)
+		case len(groups[0]) == 2:
+			return ParseVec2f(s)
+		case len(groups[0]) == 4:
+			return ParseQuat(s)
+		case strings.ContainsAny(s, "&."):
+			return ParseVec3f(s)
+		}
+		return ParseVec3i(s)
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Int(i), nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return Uint(u), nil
+	}
+	if strings.Contains(s, ".") {
+		if _, err := strconv.ParseFloat(s, 32); err == nil {
+			f, _ := ParseFloat(s)
+			return Float(f), nil
+		}
+		return LinkPointer(s), nil
+	}
+	if t, err := ParseToken(s); err == nil {
+		return t, nil
+	}
+	return String(s), nil
+}
+
+// GetArray returns the raw elements of an array property, written either
+// save-style ("key: 2", "key[0]: a", "key[1]: b") or definition-style
+// ("key[]: a", "key[]: b"). Save-style elements are returned by index.
+func GetArray(props map[string][]string, key string) []string {
+	if vals := props[key+"[]"]; len(vals) > 0 {
+		return vals
+	}
+	type element struct {
+		index int
+		value string
+	}
+	var elems []element
+	prefix := key + "["
+	for k, vals := range props {
+		if !strings.HasPrefix(k, prefix) || len(vals) == 0 {
+			continue
+		}
+		if i, ok := arrayIndex(k); ok && arrayBase(k) == key {
+			elems = append(elems, element{i, vals[0]})
+		}
+	}
+	sort.Slice(elems, func(i, j int) bool { return elems[i].index < elems[j].index })
+	out := make([]string, len(elems))
+	for i, e := range elems {
+		out[i] = e.value
+	}
+	return out
+}
+
+// PutArray replaces a save-style array property with values: it writes the
+// count under key and one "key[i]" entry per element, and removes the
+// elements beyond the new length.
+func PutArray(props map[string][]string, key string, values Array) {
+	for k := range props {
+		if arrayBase(k) == key && k != key {
+			delete(props, k)
+		}
+	}
+	props[key] = []string{strconv.Itoa(len(values))}
+	for i, v := range values {
+		props[fmt.Sprintf("%s[%d]", key, i)] = []string{v.Format()}
+	}
+}
+
+// SortedKeys returns the keys of props in natural order: array counts before
+// their elements, and elements by index. FromProperties implementations range
+// over it so that slices are filled in file order.
+func SortedKeys(props map[string][]string) []string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return lessKey(keys[i], keys[j]) })
+	return keys
+}
@@ -0,0 +1,54 @@
+package sii
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValue_RoundTrip(t *testing.T) {
+	cases := []struct {
+		text string
+		want Value
+	}{
+		{"3", Int(3)},
+		{"16453317001458416571", Uint(16453317001458416571)},
+		{"&3f400000", HexFloat(0.75)},
+		{"true", Bool(true)},
+		{"(&c75137e5, 0, &453d1a51)", Vec3f{X: -53559.895, Z: 3025.6448}},
+		{"(2147483647, 0, -1)", Vec3i{X: 2147483647, Z: -1}},
+		{"(1; 0, 0, 0)", Quat{W: 1}},
+		{"(0, 0, 0) (1; 0, 0, 0)", Placement{Rotation: Quat{W: 1}}},
+		{"scania", Token("scania")},
+		{"_nameless.284.21ed.9fb0", LinkPointer("_nameless.284.21ed.9fb0")},
+		{"null", LinkPointer("")},
+		{`"say \"hi\""`, String(`say "hi"`)},
+	}
+	for _, c := range cases {
+		got, err := ParseValue(c.text)
+		if err != nil {
+			t.Fatalf("ParseValue(%q): %v", c.text, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseValue(%q) = %#v, want %#v", c.text, got, c.want)
+		}
+		if got.Format() != c.text {
+			t.Errorf("%#v.Format() = %q, want %q", got, got.Format(), c.text)
+		}
+	}
+
+	if got := Float(1.5).Format(); got != "&3fc00000" {
+		t.Errorf("Float(1.5) = %q", got)
+	}
+	if got := Float(-1e20).Format(); got != FormatHexFloat(-1e20) {
+		t.Errorf("Float(-1e20) = %q", got)
+	}
+
+	props := map[string][]string{"a": {"3"}, "a[10]": {"z"}, "a[2]": {"y"}, "a[0]": {"x"}}
+	if got := GetArray(props, "a"); !reflect.DeepEqual(got, []string{"x", "y", "z"}) {
+		t.Errorf("GetArray = %v", got)
+	}
+	PutArray(props, "a", Array{Token("b")})
+	if !reflect.DeepEqual(props, map[string][]string{"a": {"1"}, "a[0]": {"b"}}) {
+		t.Errorf("PutArray = %v", props)
+	}
+}
@@ -1,9 +1,14 @@
 package siidecrypt
 
 import (
+	"errors"
 	"fmt"
 )
 
+// errShortData is returned (possibly wrapped) when a BSII buffer ends in the
+// middle of a value. The streaming decoder reads more data and retries.
+var errShortData = errors.New("not enough bytes")
+
 // BSIISupportedVersions are the supported BSII format versions
 const (
 	BSIIVersion0 = 0
@@ -14,6 +19,14 @@ const (
 
 // DecodeBSII decodes binary SII format to text SII format
 func DecodeBSII(bytes []byte) ([]byte, error) {
+	text, _, err := DecodeBSIIWithSchema(bytes)
+	return text, err
+}
+
+// DecodeBSIIWithSchema decodes binary SII format to text SII format and also
+// returns the structure definitions found in the file, so the document can be
+// encoded back with EncodeBSII using the same layout.
+func DecodeBSIIWithSchema(bytes []byte) ([]byte, *BSIISchema, error) {
 	pos := 0
 
 	fileData := &BSIIData{
@@ -24,18 +37,18 @@ func DecodeBSII(bytes []byte) ([]byte, error) {
 	// Read header
 	sig, err := decodeUInt32(bytes, &pos)
 	if err != nil {
-		return nil, fmt.Errorf("read signature: %w", err)
+		return nil, nil, fmt.Errorf("read signature: %w", err)
 	}
 	fileData.Header.Signature = sig
 
 	ver, err := decodeUInt32(bytes, &pos)
 	if err != nil {
-		return nil, fmt.Errorf("read version: %w", err)
+		return nil, nil, fmt.Errorf("read version: %w", err)
 	}
 	fileData.Header.Version = ver
 
 	if ver != BSIIVersion0 && ver != BSIIVersion1 && ver != BSIIVersion2 && ver != BSIIVersion3 {
-		return nil, fmt.Errorf("BSII version %d not supported", ver)
+		return nil, nil, fmt.Errorf("BSII version %d not supported", ver)
 	}
 
 	ordinalLists := make(map[uint32]map[uint32]string)
@@ -193,30 +206,10 @@ func DecodeBSII(bytes []byte) ([]byte, error) {
 			}
 			if blockDataItem == nil {
 				// Structure still not found after first pass - this shouldn't happen
-				return nil, fmt.Errorf("structure ID %d not found after collecting all definitions", blockType)
+				return nil, nil, fmt.Errorf("structure ID %d not found after collecting all definitions", blockType)
 			}
 
-			// Create a copy of the structure (like C# code)
-			blockData := BSIIStructureBlock{
-				StructureID: blockDataItem.StructureID,
-				Name:        blockDataItem.Name,
-				Type:        blockDataItem.Type,
-				Validity:    blockDataItem.Validity,
-				Segments:    make([]BSIIDataSegment, len(blockDataItem.Segments)),
-			}
-			for i, seg := range blockDataItem.Segments {
-				blockData.Segments[i] = BSIIDataSegment{
-					Name: seg.Name,
-					Type: seg.Type,
-				}
-			}
-			if blockDataItem.ID != nil {
-				blockData.ID = &IDComplexType{
-					PartCount: blockDataItem.ID.PartCount,
-					Address:   blockDataItem.ID.Address,
-					Value:     blockDataItem.ID.Value,
-				}
-			}
+			blockData := newBSIIInstance(blockDataItem)
 
 			// Get ordinal list for this structure
 			list := ordinalLists[blockData.StructureID]
@@ -224,7 +217,7 @@ func DecodeBSII(bytes []byte) ([]byte, error) {
 			// Load data for this instance
 			err = loadDataBlockLocal(bytes, &pos, &blockData, fileData.Header.Version, list)
 			if err != nil {
-				return nil, fmt.Errorf("load data block for structure %d: %w", blockType, err)
+				return nil, nil, fmt.Errorf("load data block for structure %d: %w", blockType, err)
 			}
 
 			fileData.DecodedBlocks = append(fileData.DecodedBlocks, blockData)
@@ -232,7 +225,37 @@ func DecodeBSII(bytes []byte) ([]byte, error) {
 	}
 
 	// Serialize to text SII
-	return SerializeBSII(fileData)
+	text, err := SerializeBSII(fileData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return text, newBSIISchema(fileData), nil
+}
+
+// newBSIIInstance returns an empty instance of the structure def, to be
+// filled by loadDataBlockLocal (like the copy made in the C# code).
+func newBSIIInstance(def *BSIIStructureBlock) BSIIStructureBlock {
+	blockData := BSIIStructureBlock{
+		StructureID: def.StructureID,
+		Name:        def.Name,
+		Type:        def.Type,
+		Validity:    def.Validity,
+		Segments:    make([]BSIIDataSegment, len(def.Segments)),
+	}
+	for i, seg := range def.Segments {
+		blockData.Segments[i] = BSIIDataSegment{
+			Name: seg.Name,
+			Type: seg.Type,
+		}
+	}
+	if def.ID != nil {
+		blockData.ID = &IDComplexType{
+			PartCount: def.ID.PartCount,
+			Address:   def.ID.Address,
+			Value:     def.ID.Value,
+		}
+	}
+	return blockData
 }
 
 // readDataBlock reads a data segment definition
@@ -375,7 +398,7 @@ func skipSegmentData(bytes []byte, offset *int, dataType uint32, formatVersion u
 			return err
 		}
 		if len(bytes)-*offset < int(length) {
-			return fmt.Errorf("not enough bytes")
+			return errShortData
 		}
 		*offset += int(length)
 	case 0x02: // ArrayOfUTF8String
@@ -403,7 +426,7 @@ func skipSegmentData(bytes []byte, offset *int, dataType uint32, formatVersion u
 		}
 	case 0x05: // Single
 		if len(bytes)-*offset < 4 {
-			return fmt.Errorf("not enough bytes")
+			return errShortData
 		}
 		*offset += 4
 	case 0x06: // ArrayOfSingle
@@ -521,7 +544,7 @@ func skipSegmentData(bytes []byte, offset *int, dataType uint32, formatVersion u
 	case 0x39, 0x3B, 0x3D: // Id, IdType2, IdType3
 		// Skip ID - complex, need to decode partially
 		if len(bytes)-*offset < 1 {
-			return fmt.Errorf("not enough bytes")
+			return errShortData
 		}
 		partCount := bytes[*offset]
 		*offset++
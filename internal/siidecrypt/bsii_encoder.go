@@ -0,0 +1,661 @@
+package siidecrypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// BSIISchema holds the structure definitions of a binary SII file: segment
+// names and types per structure, and the ordinal string tables. It is
+// captured by DecodeBSIIWithSchema and lets EncodeBSII rebuild a file that
+// uses the same layout as the one the game wrote.
+type BSIISchema struct {
+	Version    uint32
+	Structures []BSIIStructureBlock
+}
+
+// newBSIISchema keeps the valid structure definitions collected while decoding.
+func newBSIISchema(data *BSIIData) *BSIISchema {
+	schema := &BSIISchema{Version: data.Header.Version}
+	for _, block := range data.Blocks {
+		if block.Validity {
+			schema.Structures = append(schema.Structures, block)
+		}
+	}
+	return schema
+}
+
+// structureByName returns the first structure definition with the given name.
+func (s *BSIISchema) structureByName(name string) *BSIIStructureBlock {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Structures {
+		if s.Structures[i].Name == name {
+			return &s.Structures[i]
+		}
+	}
+	return nil
+}
+
+// EncodeBSII encodes a document to binary SII format. This is the reverse of
+// DecodeBSII.
+//
+// Structure definitions come from schema when it knows the block type. Block
+// types or properties the schema does not know (or every block when schema
+// is nil) get a definition whose segment types are inferred from the text
+// values. Each definition is written right before its first instance, like
+// the game does.
+func EncodeBSII(doc *sii.Document, schema *BSIISchema) ([]byte, error) {
+	enc := &bsiiEncoder{
+		version: BSIIVersion3,
+		schema:  schema,
+		emitted: make(map[uint32]bool),
+	}
+	if schema != nil {
+		enc.version = schema.Version
+		for _, s := range schema.Structures {
+			if s.StructureID >= enc.nextID {
+				enc.nextID = s.StructureID + 1
+			}
+		}
+	}
+	if enc.nextID == 0 {
+		enc.nextID = 1
+	}
+
+	encodeUInt32(&enc.buf, uint32(SignatureBinary))
+	encodeUInt32(&enc.buf, enc.version)
+
+	for i := range doc.Blocks {
+		if err := enc.encodeBlock(doc.Blocks[i]); err != nil {
+			return nil, fmt.Errorf("encode block %s : %s: %w", doc.Blocks[i].Type, doc.Blocks[i].Name, err)
+		}
+	}
+
+	// End of file marker: an invalid structure definition.
+	encodeUInt32(&enc.buf, 0)
+	encodeBool(&enc.buf, false)
+
+	return enc.buf.Bytes(), nil
+}
+
+type bsiiEncoder struct {
+	buf     bytes.Buffer
+	version uint32
+	schema  *BSIISchema
+	// inferred holds the structures built for blocks the schema did not cover.
+	inferred []BSIIStructureBlock
+	emitted  map[uint32]bool
+	nextID   uint32
+}
+
+// arrayKeyPattern matches the element keys of an array property, either
+// indexed ("name[3]") as written by the game, or appended ("name[]") as
+// used in def files.
+var arrayKeyPattern = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+
+func (e *bsiiEncoder) encodeBlock(block *sii.Block) error {
+	structure, err := e.structureFor(block)
+	if err != nil {
+		return err
+	}
+	if !e.emitted[structure.StructureID] {
+		e.encodeStructure(structure)
+		e.emitted[structure.StructureID] = true
+	}
+
+	encodeUInt32(&e.buf, structure.StructureID)
+	if err := encodeID(&e.buf, block.Name); err != nil {
+		return err
+	}
+	for _, seg := range structure.Segments {
+		if err := e.encodeSegment(block, seg); err != nil {
+			return fmt.Errorf("%s: %w", seg.Name, err)
+		}
+	}
+	return nil
+}
+
+// structureFor finds the structure definition to use for block. A schema
+// structure is used as-is when it covers every property of the block;
+// otherwise a new structure is built (and reused for identical blocks).
+func (e *bsiiEncoder) structureFor(block *sii.Block) (*BSIIStructureBlock, error) {
+	names := blockSegmentNames(block)
+	base := e.schema.structureByName(block.Type)
+
+	var segments []BSIIDataSegment
+	if base != nil {
+		known := make(map[string]bool, len(base.Segments))
+		for _, seg := range base.Segments {
+			known[seg.Name] = true
+		}
+		var extra []string
+		for _, name := range names {
+			if !known[name] {
+				extra = append(extra, name)
+			}
+		}
+		if len(extra) == 0 {
+			return base, nil
+		}
+		segments = append(segments, base.Segments...)
+		names = extra
+	}
+	for _, name := range names {
+		segType, err := inferSegmentType(block, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		segments = append(segments, BSIIDataSegment{Name: name, Type: segType})
+	}
+
+	for i := range e.inferred {
+		if e.inferred[i].Name == block.Type && sameSegments(e.inferred[i].Segments, segments) {
+			return &e.inferred[i], nil
+		}
+	}
+	e.inferred = append(e.inferred, BSIIStructureBlock{
+		StructureID: e.nextID,
+		Validity:    true,
+		Name:        block.Type,
+		Segments:    segments,
+	})
+	e.nextID++
+	return &e.inferred[len(e.inferred)-1], nil
+}
+
+func sameSegments(a, b []BSIIDataSegment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
+
+// blockSegmentNames lists the properties of block in file order, folding
+// array element keys into their array name.
+func blockSegmentNames(block *sii.Block) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range block.PropertyOrder {
+		name := key
+		if m := arrayKeyPattern.FindStringSubmatch(key); m != nil {
+			name = m[1]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// blockArrayValues returns the elements of an array property, or false when
+// block has no such array.
+func blockArrayValues(block *sii.Block, name string) ([]string, bool) {
+	if vals, ok := block.Properties[name+"[]"]; ok {
+		return vals, true
+	}
+	countVals, ok := block.Properties[name]
+	if !ok || len(countVals) == 0 {
+		return nil, false
+	}
+	count, err := strconv.Atoi(countVals[0])
+	if err != nil {
+		return nil, false
+	}
+	out := make([]string, count)
+	for i := range out {
+		if vals, ok := block.Properties[name+"["+strconv.Itoa(i)+"]"]; ok && len(vals) > 0 {
+			out[i] = vals[0]
+		}
+	}
+	return out, true
+}
+
+func isBSIIArrayType(segType uint32) bool {
+	switch segType {
+	case 0x02, 0x04, 0x06, 0x08, 0x0A, 0x12, 0x18, 0x1A, 0x26, 0x28, 0x2A,
+		0x2C, 0x32, 0x34, 0x36, 0x3A, 0x3C, 0x3E:
+		return true
+	}
+	return false
+}
+
+// inferSegmentType guesses the binary type of a property from its text value.
+func inferSegmentType(block *sii.Block, name string) (uint32, error) {
+	_, indexed := block.Properties[name+"[0]"]
+	_, appended := block.Properties[name+"[]"]
+	if indexed || appended {
+		values, _ := blockArrayValues(block, name)
+		if len(values) == 0 {
+			return 0x3A, nil
+		}
+		elemType := inferScalarType(values[0])
+		for _, v := range values[1:] {
+			if t := inferScalarType(v); t != elemType {
+				// Mixed element kinds: only a string array can hold them all.
+				elemType = 0x01
+				break
+			}
+		}
+		return elemType + 1, nil
+	}
+	vals := block.Properties[name]
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("no value")
+	}
+	return inferScalarType(vals[0]), nil
+}
+
+func inferScalarType(value string) uint32 {
+	switch {
+	case value == "true" || value == "false":
+		return 0x35
+	case value == "nil":
+		return 0x27
+	case value == "null" || strings.HasPrefix(value, "_nameless."):
+		return 0x39
+	case strings.HasPrefix(value, "\""):
+		return 0x01
+	case strings.HasPrefix(value, "&"):
+		return 0x05
+	case strings.HasPrefix(value, "("):
+		switch n := len(parseFloatTuple(value)); {
+		case n == 2:
+			return 0x07
+		case n == 4:
+			return 0x17
+		case n >= 7:
+			return 0x19
+		default:
+			return 0x09
+		}
+	}
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return 0x25
+		}
+		return 0x31
+	}
+	if _, err := strconv.ParseFloat(value, 32); err == nil {
+		return 0x05
+	}
+	if strings.Contains(value, ".") && isEncodableID(value) {
+		return 0x39
+	}
+	if _, err := encodeUInt64String(value); err == nil {
+		return 0x03
+	}
+	return 0x01
+}
+
+func isEncodableID(value string) bool {
+	for _, part := range strings.Split(value, ".") {
+		if _, err := encodeUInt64String(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeStructure writes a structure definition block.
+func (e *bsiiEncoder) encodeStructure(s *BSIIStructureBlock) {
+	encodeUInt32(&e.buf, 0)
+	encodeBool(&e.buf, true)
+	encodeUInt32(&e.buf, s.StructureID)
+	encodeUTF8String(&e.buf, s.Name)
+	for _, seg := range s.Segments {
+		encodeUInt32(&e.buf, seg.Type)
+		encodeUTF8String(&e.buf, seg.Name)
+		if seg.Type == 0x37 {
+			encodeOrdinalStringList(&e.buf, ordinalValues(seg))
+		}
+	}
+	encodeUInt32(&e.buf, 0)
+}
+
+func ordinalValues(seg BSIIDataSegment) map[uint32]string {
+	values, _ := seg.Value.(map[uint32]string)
+	return values
+}
+
+// encodeSegment writes the value of one segment for block. Properties the
+// block does not have are written as zero values.
+func (e *bsiiEncoder) encodeSegment(block *sii.Block, seg BSIIDataSegment) error {
+	if isBSIIArrayType(seg.Type) {
+		values, _ := blockArrayValues(block, seg.Name)
+		encodeUInt32(&e.buf, uint32(len(values)))
+		for i, v := range values {
+			if err := e.encodeValue(seg.Type-1, v, seg); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	value := ""
+	if vals, ok := block.Properties[seg.Name]; ok && len(vals) > 0 {
+		value = vals[0]
+	}
+	return e.encodeValue(seg.Type, value, seg)
+}
+
+// encodeValue writes a single (non array) value of the given type. An empty
+// value is written as the zero value of the type.
+func (e *bsiiEncoder) encodeValue(segType uint32, value string, seg BSIIDataSegment) error {
+	buf := &e.buf
+	switch segType {
+	case 0x01: // UTF8String
+		encodeUTF8String(buf, sii.Unquote(value))
+	case 0x03: // EncodedString
+		v, err := encodeUInt64String(sii.Unquote(value))
+		if err != nil {
+			return err
+		}
+		encodeUInt64(buf, v)
+	case 0x05: // Single
+		f, err := parseSingle(value)
+		if err != nil {
+			return err
+		}
+		encodeSingle(buf, f)
+	case 0x07, 0x09, 0x17: // VectorOf2Single, VectorOf3Single, VectorOf4Single
+		size := map[uint32]int{0x07: 2, 0x09: 3, 0x17: 4}[segType]
+		floats, err := parseFloatVector(value, size)
+		if err != nil {
+			return err
+		}
+		for _, f := range floats {
+			encodeSingle(buf, f)
+		}
+	case 0x11: // VectorOf3Int32
+		parts := parseFloatTuple(value)
+		if value != "" && len(parts) != 3 {
+			return fmt.Errorf("expected 3 components in %q", value)
+		}
+		for i := 0; i < 3; i++ {
+			var v int64
+			if value != "" {
+				var err error
+				if v, err = strconv.ParseInt(parts[i], 10, 32); err != nil {
+					return fmt.Errorf("invalid integer %q: %w", parts[i], err)
+				}
+			}
+			encodeUInt32(buf, uint32(int32(v)))
+		}
+	case 0x19: // VectorOf8Single (VectorOf7Single for version 0 and 1)
+		if e.version == BSIIVersion0 || e.version == BSIIVersion1 {
+			floats, err := parseFloatVector(value, 7)
+			if err != nil {
+				return err
+			}
+			for _, f := range floats {
+				encodeSingle(buf, f)
+			}
+			return nil
+		}
+		floats, err := parseFloatVector(value, 7)
+		if err != nil {
+			return err
+		}
+		// The text form has no bias: write a neutral one so the decoder adds
+		// nothing to the position.
+		encodeSingle(buf, floats[0])
+		encodeSingle(buf, floats[1])
+		encodeSingle(buf, floats[2])
+		encodeSingle(buf, float32(2048<<12|2048))
+		for _, f := range floats[3:] {
+			encodeSingle(buf, f)
+		}
+	case 0x25, 0x29, 0x31: // Int32, Int16, Int64
+		v, err := parseIntValue(value, segType)
+		if err != nil {
+			return err
+		}
+		switch segType {
+		case 0x25:
+			encodeUInt32(buf, uint32(int32(v)))
+		case 0x29:
+			encodeUInt16(buf, uint16(int16(v)))
+		default:
+			encodeUInt64(buf, uint64(v))
+		}
+	case 0x27, 0x2F, 0x2B, 0x33: // UInt32, UInt32Type2, UInt16, UInt64
+		v, err := parseUintValue(value, segType)
+		if err != nil {
+			return err
+		}
+		switch segType {
+		case 0x2B:
+			encodeUInt16(buf, uint16(v))
+		case 0x33:
+			encodeUInt64(buf, v)
+		default:
+			encodeUInt32(buf, uint32(v))
+		}
+	case 0x35: // ByteBool
+		encodeBool(buf, value == "true")
+	case 0x37: // OrdinalString
+		index, err := ordinalIndex(ordinalValues(seg), value)
+		if err != nil {
+			return err
+		}
+		encodeUInt32(buf, index)
+	case 0x39, 0x3B, 0x3D: // Id, IdType2, IdType3
+		return encodeID(buf, value)
+	default:
+		return fmt.Errorf("unsupported BSII type 0x%02X", segType)
+	}
+	return nil
+}
+
+func ordinalIndex(values map[uint32]string, value string) (uint32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	for index, s := range values {
+		if s == value {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("value %q is not in the ordinal string table", value)
+}
+
+// parseSingle parses a float written either as "&hex" bits or as a number;
+// empty and "nil" values encode as 0.
+func parseSingle(value string) (float32, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == sii.Nil {
+		return 0, nil
+	}
+	return sii.ParseFloat(value)
+}
+
+// parseFloatTuple splits a vector such as "(1, 2, 3) (4; 5, 6, 7)" into
+// its components.
+func parseFloatTuple(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == '(' || r == ')' || r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+}
+
+func parseFloatVector(value string, size int) ([]float32, error) {
+	out := make([]float32, size)
+	if strings.TrimSpace(value) == "" {
+		return out, nil
+	}
+	parts := parseFloatTuple(value)
+	if len(parts) != size {
+		return nil, fmt.Errorf("expected %d components in %q", size, value)
+	}
+	for i, p := range parts {
+		f, err := parseSingle(p)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// parseIntValue parses a signed integer; "nil" maps to the sentinel the
+// serializer turns into "nil".
+func parseIntValue(value string, segType uint32) (int64, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "nil":
+		if segType == 0x29 {
+			return 32767, nil
+		}
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q: %w", value, err)
+	}
+	return v, nil
+}
+
+// parseUintValue parses an unsigned integer; "nil" maps to the sentinel the
+// serializer turns into "nil".
+func parseUintValue(value string, segType uint32) (uint64, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "nil":
+		switch segType {
+		case 0x2B:
+			return math.MaxUint16, nil
+		case 0x33:
+			return 0, nil
+		}
+		return math.MaxUint32, nil
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsigned integer %q: %w", value, err)
+	}
+	return v, nil
+}
+
+func encodeUInt32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func encodeUInt16(buf *bytes.Buffer, v uint16) {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	buf.Write(b[:])
+}
+
+func encodeUInt64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
+
+func encodeSingle(buf *bytes.Buffer, f float32) {
+	encodeUInt32(buf, math.Float32bits(f))
+}
+
+func encodeBool(buf *bytes.Buffer, v bool) {
+	if v {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+}
+
+// encodeUTF8String writes a length-prefixed string (type 0x01).
+func encodeUTF8String(buf *bytes.Buffer, s string) {
+	encodeUInt32(buf, uint32(len(s)))
+	buf.WriteString(s)
+}
+
+// encodeUInt64String packs a token into the base-38 form read by
+// decodeUInt64String: the first character is the least significant digit.
+func encodeUInt64String(s string) (uint64, error) {
+	if len(s) > 12 {
+		return 0, fmt.Errorf("token %q is longer than 12 characters", s)
+	}
+	var v uint64
+	for i := len(s) - 1; i >= 0; i-- {
+		idx := bytes.IndexByte(BSIICharTable, s[i])
+		if idx < 0 {
+			return 0, fmt.Errorf("token %q contains invalid character %q", s, s[i])
+		}
+		v = v*38 + uint64(idx+1)
+	}
+	return v, nil
+}
+
+// encodeID writes an ID complex type (0x39, 0x3B, 0x3D), the reverse of
+// decodeID: "null" has no parts, "_nameless.xxxx.xxxx" is stored as an
+// address and anything else as a list of encoded tokens.
+func encodeID(buf *bytes.Buffer, value string) error {
+	if value == "" || value == "null" {
+		buf.WriteByte(0)
+		return nil
+	}
+
+	if rest, ok := strings.CutPrefix(value, "_nameless."); ok {
+		var addr uint64
+		for _, group := range strings.Split(rest, ".") {
+			v, err := strconv.ParseUint(group, 16, 16)
+			if err != nil {
+				return fmt.Errorf("invalid nameless id %q: %w", value, err)
+			}
+			addr = addr<<16 | v
+		}
+		buf.WriteByte(0xFF)
+		encodeUInt64(buf, addr)
+		return nil
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) >= 0xFF {
+		return fmt.Errorf("id %q has too many parts", value)
+	}
+	buf.WriteByte(byte(len(parts)))
+	for _, part := range parts {
+		v, err := encodeUInt64String(part)
+		if err != nil {
+			return fmt.Errorf("invalid id %q: %w", value, err)
+		}
+		encodeUInt64(buf, v)
+	}
+	return nil
+}
+
+// encodeOrdinalStringList writes an ordinal string table (type 0x37),
+// sorted by ordinal so the output is stable.
+func encodeOrdinalStringList(buf *bytes.Buffer, values map[uint32]string) {
+	ordinals := make([]uint32, 0, len(values))
+	for ordinal := range values {
+		ordinals = append(ordinals, ordinal)
+	}
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	encodeUInt32(buf, uint32(len(ordinals)))
+	for _, ordinal := range ordinals {
+		encodeUInt32(buf, ordinal)
+		encodeUTF8String(buf, values[ordinal])
+	}
+}
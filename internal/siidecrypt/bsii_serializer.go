@@ -1,11 +1,10 @@
 package siidecrypt
 
 import (
-	"encoding/binary"
-	"fmt"
-	"math"
 	"strconv"
 	"strings"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
 )
 
 // SerializeBSII converts decoded BSII data to text SII format (mirrors C# BSII_Serializer.Serialize)
@@ -14,104 +13,109 @@ func SerializeBSII(data *BSIIData) ([]byte, error) {
 	sb.WriteString("SiiNunit\n")
 	sb.WriteString("{\n")
 
-	indent := ""
-	for _, block := range data.DecodedBlocks {
-		if block.Name == "" || block.ID == nil || block.ID.Value == "" {
+	for i := range data.DecodedBlocks {
+		serializeBlock(&sb, &data.DecodedBlocks[i], data.Header.Version)
+	}
+	sb.WriteString("}")
+	return []byte(sb.String()), nil
+}
+
+// serializeBlock writes one decoded instance as a text SII unit followed by
+// a blank line. Instances without a name or ID are skipped.
+func serializeBlock(sb *strings.Builder, block *BSIIStructureBlock, version uint32) {
+	if block.Name == "" || block.ID == nil || block.ID.Value == "" {
+		return
+	}
+
+	sb.WriteString(block.Name + " : " + block.ID.Value + " {\n")
+	indent := " "
+
+	for _, segment := range block.Segments {
+		if segment.Type == 0 {
 			continue
 		}
 
-		sb.WriteString(block.Name + " : " + block.ID.Value + " {\n")
-		indent = " "
-
-		for _, segment := range block.Segments {
-			if segment.Type == 0 {
-				continue
+		var output string
+		switch segment.Type {
+		case 0x36: // ArrayOfByteBool
+			output = serializeByteBoolArray(&segment, indent)
+		case 0x04: // ArrayOfEncodedString
+			output = serializeEncodedStringArray(&segment, indent)
+		case 0x3A, 0x3C, 0x3E: // ArrayOfIdA, ArrayOfIdC, ArrayOfIdE
+			output = serializeIDArray(&segment, indent)
+		case 0x26: // ArrayOfInt32
+			output = serializeInt32Array(&segment, indent)
+		case 0x06: // ArrayOfSingle
+			output = serializeSingleArray(&segment, indent)
+		case 0x2C: // ArrayOfUInt16
+			output = serializeUInt16Array(&segment, indent)
+		case 0x28: // ArrayOfUInt32
+			output = serializeUInt32Array(&segment, indent)
+		case 0x34: // ArrayOfUInt64
+			output = serializeUInt64Array(&segment, indent)
+		case 0x02: // ArrayOfUTF8String
+			output = serializeUTF8StringArray(&segment, indent)
+		case 0x12: // ArrayOfVectorOf3Int32
+			output = serializeInt32Vector3Array(&segment, indent)
+		case 0x0A: // ArrayOfVectorOf3Single
+			output = serializeSingleVector3Array(&segment, indent)
+		case 0x18: // ArrayOfVectorOf4Single
+			output = serializeSingleVector4Array(&segment, indent)
+		case 0x1A: // ArrayOfVectorOf8Single
+			if version == BSIIVersion1 {
+				output = serializeSingleVector7Array(&segment, indent)
+			} else {
+				output = serializeSingleVector8Array(&segment, indent)
 			}
-
-			var output string
-			switch segment.Type {
-			case 0x36: // ArrayOfByteBool
-				output = serializeByteBoolArray(&segment, indent)
-			case 0x04: // ArrayOfEncodedString
-				output = serializeEncodedStringArray(&segment, indent)
-			case 0x3A, 0x3C, 0x3E: // ArrayOfIdA, ArrayOfIdC, ArrayOfIdE
-				output = serializeIDArray(&segment, indent)
-			case 0x26: // ArrayOfInt32
-				output = serializeInt32Array(&segment, indent)
-			case 0x06: // ArrayOfSingle
-				output = serializeSingleArray(&segment, indent)
-			case 0x2C: // ArrayOfUInt16
-				output = serializeUInt16Array(&segment, indent)
-			case 0x28: // ArrayOfUInt32
-				output = serializeUInt32Array(&segment, indent)
-			case 0x34: // ArrayOfUInt64
-				output = serializeUInt64Array(&segment, indent)
-			case 0x02: // ArrayOfUTF8String
-				output = serializeUTF8StringArray(&segment, indent)
-			case 0x12: // ArrayOfVectorOf3Int32
-				output = serializeInt32Vector3Array(&segment, indent)
-			case 0x0A: // ArrayOfVectorOf3Single
-				output = serializeSingleVector3Array(&segment, indent)
-			case 0x18: // ArrayOfVectorOf4Single
-				output = serializeSingleVector4Array(&segment, indent)
-			case 0x1A: // ArrayOfVectorOf8Single
-				if data.Header.Version == BSIIVersion1 {
-					output = serializeSingleVector7Array(&segment, indent)
-				} else {
-					output = serializeSingleVector8Array(&segment, indent)
-				}
-			case 0x35: // ByteBool
-				output = serializeBool(&segment, indent)
-			case 0x03: // EncodedString
-				output = serializeEncodedString(&segment, indent)
-			case 0x3D, 0x3B, 0x39: // IdType3, IdType2, Id
-				output = serializeId(&segment, indent)
-			case 0x25: // Int32
-				output = serializeInt32(&segment, indent)
-			case 0x31: // Int64
-				output = serializeInt64(&segment, indent)
-			case 0x2F, 0x27: // UInt32Type2, UInt32
-				output = serializeUInt32(&segment, indent)
-			case 0x33: // UInt64
-				output = serializeUInt64(&segment, indent)
-			case 0x2B: // UInt16
-				output = serializeUInt16(&segment, indent)
-			case 0x37: // OrdinalString
-				output = serializeOrdinalString(&segment, indent)
-			case 0x05: // Single
-				output = serializeSingleValue(&segment, indent)
-			case 0x01: // UTF8String
-				output = serializeUTF8String(&segment, indent)
-			case 0x07: // VectorOf2Single
-				output = serializeSingleVector2(&segment, indent)
-			case 0x11: // VectorOf3Int32
-				output = serializeInt32Vector3(&segment, indent)
-			case 0x09: // VectorOf3Single
-				output = serializeSingleVector3(&segment, indent)
-			case 0x17: // VectorOf4Single
-				output = serializeSingleVector4(&segment, indent)
-			case 0x19: // VectorOf8Single
-				if data.Header.Version == BSIIVersion1 {
-					output = serializeSingleVector7(&segment, indent)
-				} else {
-					output = serializeSingleVector8(&segment, indent)
-				}
-			case 0x32: // ArrayOfInt64
-				output = serializeInt64Array(&segment, indent)
-			case 0x08: // ArrayOfVectorOf2Single
-				output = serializeSingleVector2Array(&segment, indent)
-			case 0x29: // Int16
-				output = serializeInt16(&segment, indent)
-			case 0x2A: // ArrayOfInt16
-				output = serializeInt16Array(&segment, indent)
+		case 0x35: // ByteBool
+			output = serializeBool(&segment, indent)
+		case 0x03: // EncodedString
+			output = serializeEncodedString(&segment, indent)
+		case 0x3D, 0x3B, 0x39: // IdType3, IdType2, Id
+			output = serializeId(&segment, indent)
+		case 0x25: // Int32
+			output = serializeInt32(&segment, indent)
+		case 0x31: // Int64
+			output = serializeInt64(&segment, indent)
+		case 0x2F, 0x27: // UInt32Type2, UInt32
+			output = serializeUInt32(&segment, indent)
+		case 0x33: // UInt64
+			output = serializeUInt64(&segment, indent)
+		case 0x2B: // UInt16
+			output = serializeUInt16(&segment, indent)
+		case 0x37: // OrdinalString
+			output = serializeOrdinalString(&segment, indent)
+		case 0x05: // Single
+			output = serializeSingleValue(&segment, indent)
+		case 0x01: // UTF8String
+			output = serializeUTF8String(&segment, indent)
+		case 0x07: // VectorOf2Single
+			output = serializeSingleVector2(&segment, indent)
+		case 0x11: // VectorOf3Int32
+			output = serializeInt32Vector3(&segment, indent)
+		case 0x09: // VectorOf3Single
+			output = serializeSingleVector3(&segment, indent)
+		case 0x17: // VectorOf4Single
+			output = serializeSingleVector4(&segment, indent)
+		case 0x19: // VectorOf8Single
+			if version == BSIIVersion1 {
+				output = serializeSingleVector7(&segment, indent)
+			} else {
+				output = serializeSingleVector8(&segment, indent)
 			}
-			sb.WriteString(output)
+		case 0x32: // ArrayOfInt64
+			output = serializeInt64Array(&segment, indent)
+		case 0x08: // ArrayOfVectorOf2Single
+			output = serializeSingleVector2Array(&segment, indent)
+		case 0x29: // Int16
+			output = serializeInt16(&segment, indent)
+		case 0x2A: // ArrayOfInt16
+			output = serializeInt16Array(&segment, indent)
 		}
-		sb.WriteString("}\n")
-		sb.WriteString("\n")
+		sb.WriteString(output)
 	}
-	sb.WriteString("}")
-	return []byte(sb.String()), nil
+	sb.WriteString("}\n")
+	sb.WriteString("\n")
 }
 
 // formatSingle formats a float32 according to C# logic: "nil" if null, "&hex" if decimal or >= 1e7, else integer
@@ -119,18 +123,7 @@ func formatSingle(f *float32) string {
 	if f == nil {
 		return "nil"
 	}
-	val := *f
-	if val-float32(math.Trunc(float64(val))) != 0.00 || val >= 1e7 {
-		// Format as hex
-		bytes := make([]byte, 4)
-		binary.LittleEndian.PutUint32(bytes, math.Float32bits(val))
-		hexStr := ""
-		for i := len(bytes) - 1; i >= 0; i-- {
-			hexStr += fmt.Sprintf("%02x", bytes[i])
-		}
-		return "&" + hexStr
-	}
-	return strconv.FormatInt(int64(val), 10)
+	return sii.FormatFloat(*f)
 }
 
 func serializeByteBoolArray(seg *BSIIDataSegment, indent string) string {
@@ -55,7 +55,7 @@ type BSIIData struct {
 // decodeUInt32 reads a uint32 from bytes at offset, advances offset
 func decodeUInt32(bytes []byte, offset *int) (uint32, error) {
 	if len(bytes)-*offset < 4 {
-		return 0, fmt.Errorf("not enough bytes for uint32")
+		return 0, fmt.Errorf("%w for uint32", errShortData)
 	}
 	v := binary.LittleEndian.Uint32(bytes[*offset:])
 	*offset += 4
@@ -69,7 +69,7 @@ func decodeUTF8String(bytes []byte, offset *int) (string, error) {
 		return "", err
 	}
 	if len(bytes)-*offset < int(length) {
-		return "", fmt.Errorf("not enough bytes for UTF-8 string")
+		return "", fmt.Errorf("%w for UTF-8 string", errShortData)
 	}
 	s := string(bytes[*offset : *offset+int(length)])
 	*offset += int(length)
@@ -80,7 +80,6 @@ func decodeUTF8String(bytes []byte, offset *int) (string, error) {
 func decodeUInt64String(bytes []byte, offset *int) (string, error) {
 	value, err := decodeUInt64(bytes, offset)
 	if err != nil {
-		fmt.Printf("decodeUInt64String: error decoding uint64: %v\n", err)
 		return "", err
 	}
 	var result strings.Builder
@@ -110,7 +109,7 @@ func decodeUInt64String(bytes []byte, offset *int) (string, error) {
 // decodeUInt64 reads a uint64 from bytes
 func decodeUInt64(bytes []byte, offset *int) (uint64, error) {
 	if len(bytes)-*offset < 8 {
-		return 0, fmt.Errorf("not enough bytes for uint64")
+		return 0, fmt.Errorf("%w for uint64", errShortData)
 	}
 	v := binary.LittleEndian.Uint64(bytes[*offset:])
 	*offset += 8
@@ -120,7 +119,7 @@ func decodeUInt64(bytes []byte, offset *int) (uint64, error) {
 // decodeInt32 reads an int32 from bytes
 func decodeInt32(bytes []byte, offset *int) (int32, error) {
 	if len(bytes)-*offset < 4 {
-		return 0, fmt.Errorf("not enough bytes for int32")
+		return 0, fmt.Errorf("%w for int32", errShortData)
 	}
 	v := int32(binary.LittleEndian.Uint32(bytes[*offset:]))
 	*offset += 4
@@ -130,7 +129,7 @@ func decodeInt32(bytes []byte, offset *int) (int32, error) {
 // decodeSingle reads a float32 from bytes
 func decodeSingle(bytes []byte, offset *int) (float32, error) {
 	if len(bytes)-*offset < 4 {
-		return 0, fmt.Errorf("not enough bytes for float32")
+		return 0, fmt.Errorf("%w for float32", errShortData)
 	}
 	v := binary.LittleEndian.Uint32(bytes[*offset:])
 	*offset += 4
@@ -145,7 +144,7 @@ func float32FromBits(bits uint32) float32 {
 // decodeBool reads a bool from bytes
 func decodeBool(bytes []byte, offset *int) (bool, error) {
 	if len(bytes)-*offset < 1 {
-		return false, fmt.Errorf("not enough bytes for bool")
+		return false, fmt.Errorf("%w for bool", errShortData)
 	}
 	v := bytes[*offset] != 0
 	*offset++
@@ -155,7 +154,7 @@ func decodeBool(bytes []byte, offset *int) (bool, error) {
 // decodeUInt16 reads a uint16 from bytes
 func decodeUInt16(bytes []byte, offset *int) (uint16, error) {
 	if len(bytes)-*offset < 2 {
-		return 0, fmt.Errorf("not enough bytes for uint16")
+		return 0, fmt.Errorf("%w for uint16", errShortData)
 	}
 	v := binary.LittleEndian.Uint16(bytes[*offset:])
 	*offset += 2
@@ -165,7 +164,7 @@ func decodeUInt16(bytes []byte, offset *int) (uint16, error) {
 // decodeInt16 reads an int16 from bytes
 func decodeInt16(bytes []byte, offset *int) (int16, error) {
 	if len(bytes)-*offset < 2 {
-		return 0, fmt.Errorf("not enough bytes for int16")
+		return 0, fmt.Errorf("%w for int16", errShortData)
 	}
 	v := int16(binary.LittleEndian.Uint16(bytes[*offset:]))
 	*offset += 2
@@ -175,7 +174,7 @@ func decodeInt16(bytes []byte, offset *int) (int16, error) {
 // decodeInt64 reads an int64 from bytes
 func decodeInt64(bytes []byte, offset *int) (int64, error) {
 	if len(bytes)-*offset < 8 {
-		return 0, fmt.Errorf("not enough bytes for int64")
+		return 0, fmt.Errorf("%w for int64", errShortData)
 	}
 	v := int64(binary.LittleEndian.Uint64(bytes[*offset:]))
 	*offset += 8
@@ -185,7 +184,7 @@ func decodeInt64(bytes []byte, offset *int) (int64, error) {
 // decodeID reads an ID complex type (0x39, 0x3B, 0x3D)
 func decodeID(bytes []byte, offset *int) (*IDComplexType, error) {
 	if len(bytes)-*offset < 1 {
-		return nil, fmt.Errorf("not enough bytes for ID")
+		return nil, fmt.Errorf("%w for ID", errShortData)
 	}
 	result := &IDComplexType{}
 	result.PartCount = bytes[*offset]
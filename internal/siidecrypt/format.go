@@ -0,0 +1,148 @@
+package siidecrypt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+// FileFormat records how an SII file is stored on disk: the outer container
+// (encrypted or not), an optional 3nK layer and the encoding of the document
+// inside them (plaintext or BSII). It is captured by DecodeFile so the file
+// can be written back the same way.
+type FileFormat struct {
+	Encrypted bool
+	// Scrambled is set for a 3nK file, which wraps the encoding below.
+	Scrambled bool
+	// Seed is the key offset of a 3nK file.
+	Seed byte
+	// Encoding is SignaturePlainText or SignatureBinary. The zero value is
+	// treated as plaintext, and Signature3nK as a 3nK plaintext file.
+	Encoding SignatureType
+	// Schema holds the BSII structure definitions of a binary file. When it
+	// is nil, EncodeBSII infers them from the document.
+	Schema *BSIISchema
+}
+
+// String describes the format, e.g. "encrypted BSII" or "3nK plaintext".
+func (f FileFormat) String() string {
+	f = f.normalize()
+	encoding := "plaintext"
+	if f.Encoding == SignatureBinary {
+		encoding = "BSII"
+	}
+	if f.Scrambled {
+		encoding = "3nK " + encoding
+	}
+	if f.Encrypted {
+		return "encrypted " + encoding
+	}
+	return encoding
+}
+
+// normalize turns an Encoding of Signature3nK into a scrambled plaintext.
+func (f FileFormat) normalize() FileFormat {
+	if f.Encoding == Signature3nK {
+		f.Scrambled = true
+		f.Encoding = SignaturePlainText
+	}
+	return f
+}
+
+// DecodeFile is DecryptFile(path, true) that also reports
+// the format the file was stored in.
+func DecodeFile(path string) ([]byte, FileFormat, error) {
+	format := FileFormat{}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, format, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(raw) >= 4 && SignatureType(binary.LittleEndian.Uint32(raw)) == SignatureEncrypted {
+		format.Encrypted = true
+	}
+
+	payload, err := decryptData(raw, false)
+	if err != nil {
+		return nil, format, err
+	}
+	if len(payload) < 4 {
+		return nil, format, fmt.Errorf("invalid data: cannot read inner signature")
+	}
+
+	if SignatureType(binary.LittleEndian.Uint32(payload)) == Signature3nK {
+		format.Scrambled = true
+		if len(payload) >= threeNKHeaderSize {
+			format.Seed = payload[5]
+		}
+		if payload, err = Decode3nK(payload); err != nil {
+			return nil, format, err
+		}
+		if len(payload) < 4 {
+			return nil, format, fmt.Errorf("invalid data: cannot read 3nK inner signature")
+		}
+	}
+
+	if SignatureType(binary.LittleEndian.Uint32(payload)) == SignatureBinary {
+		format.Encoding = SignatureBinary
+		text, schema, err := DecodeBSIIWithSchema(payload)
+		if err != nil {
+			return nil, format, err
+		}
+		format.Schema = schema
+		return text, format, nil
+	}
+
+	text, err := decodePayload(payload)
+	if err != nil {
+		return nil, format, err
+	}
+	return text, format, nil
+}
+
+// EncodeDocument serializes doc in the given format. It is the reverse of
+// DecodeFile followed by sii.ReadDocument.
+func EncodeDocument(doc *sii.Document, format FileFormat) ([]byte, error) {
+	format = format.normalize()
+
+	var data []byte
+	if format.Encoding == SignatureBinary {
+		encoded, err := EncodeBSII(doc, format.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("encode BSII: %w", err)
+		}
+		data = encoded
+	} else {
+		text, err := sii.WriteDocument(doc)
+		if err != nil {
+			return nil, fmt.Errorf("serialize SII document: %w", err)
+		}
+		data = text
+	}
+	if format.Scrambled {
+		data = Encode3nK(data, format.Seed)
+	}
+
+	if format.Encrypted {
+		encrypted, err := encrypt(data)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt data: %w", err)
+		}
+		data = encrypted
+	}
+	return data, nil
+}
+
+// WriteFile serializes doc in the given format and writes it to path.
+func WriteFile(path string, doc *sii.Document, format FileFormat) error {
+	data, err := EncodeDocument(doc, format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
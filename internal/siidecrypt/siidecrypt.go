@@ -46,13 +46,19 @@ var siiKey = []byte{
 
 // DecryptFile is the Go equivalent of Decryptor.Decrypt(filePath, decode=true).
 // It returns the decoded payload (typically starting with "SiiNunit" for
-// plaintext SII files). Binary BSII and 3nK formats are not yet supported.
+// plaintext SII files). Binary BSII and 3nK payloads are decoded to text.
+// Like the C# code, the HMAC of encrypted files is not checked: the way the
+// game computes it is not known.
 func DecryptFile(path string, decode bool) ([]byte, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read %s: %w", path, err)
 	}
+	return decryptData(bytes, decode)
+}
 
+// decryptData is DecryptFile on an in-memory buffer.
+func decryptData(bytes []byte, decode bool) ([]byte, error) {
 	pos := 0
 	fileType, ok := tryReadUint32(bytes, &pos)
 	if !ok {
@@ -86,8 +92,14 @@ func DecryptFile(path string, decode bool) ([]byte, error) {
 		return bytes, nil
 	}
 
-	// Decode by inner signature (plaintext / binary / 3nK)
-	pos = 0
+	return decodePayload(bytes)
+}
+
+// decodePayload decodes an already decrypted buffer by its inner signature
+// (plaintext / binary / 3nK). A 3nK payload is decoded again because the
+// scrambled data is itself a plaintext (or, in theory, binary) SII file.
+func decodePayload(bytes []byte) ([]byte, error) {
+	pos := 0
 	dataType, ok := tryReadUint32(bytes, &pos)
 	if !ok {
 		return nil, errors.New("invalid data: cannot read inner signature")
@@ -102,7 +114,11 @@ func DecryptFile(path string, decode bool) ([]byte, error) {
 		// Note: DecodeBSII expects the full buffer including the signature (like C# BSII_Decoder.Decode)
 		return DecodeBSII(bytes)
 	case Signature3nK:
-		return nil, errors.New("_3nK decoding not implemented yet")
+		decoded, err := Decode3nK(bytes)
+		if err != nil {
+			return nil, err
+		}
+		return decodePayload(decoded)
 	default:
 		return nil, fmt.Errorf("unknown inner signature: 0x%08X", dataType)
 	}
@@ -124,7 +140,6 @@ func decrypt(encrypted []byte) (*SIIData, error) {
 	}
 	if len(encrypted)-pos >= 32 {
 		hmac = encrypted[pos : pos+32]
-		_ = hmac // HMAC is currently unused, same as original C# code.
 		pos += 32
 	}
 	if len(encrypted)-pos >= 16 {
@@ -148,6 +163,7 @@ func decrypt(encrypted []byte) (*SIIData, error) {
 	if len(iv) != aes.BlockSize {
 		return nil, errors.New("invalid IV size for AES")
 	}
+	_ = hmac // HMAC is currently unused, same as original C# code.
 
 	dst := make([]byte, len(finalEncrypted))
 	mode := cipher.NewCBCDecrypter(block, iv)
@@ -185,7 +201,7 @@ func bytesReader(b []byte) io.Reader {
 
 // EncryptFile encrypts plaintext SII data and writes it to a file.
 // This is the reverse of DecryptFile: it compresses with zlib, encrypts with AES-CBC,
-// and writes the encrypted format with signature, HMAC placeholder, IV, and encrypted data.
+// and writes the encrypted format with signature, HMAC placeholder, IV, data size and encrypted data.
 func EncryptFile(path string, plaintext []byte) error {
 	encrypted, err := encrypt(plaintext)
 	if err != nil {
@@ -201,7 +217,8 @@ func EncryptFile(path string, plaintext []byte) error {
 
 // encrypt is the reverse of decrypt: it compresses plaintext with zlib,
 // then encrypts it with AES-CBC using a random IV, and formats it with
-// signature, HMAC placeholder, IV, dataSize, and encrypted data.
+// signature, HMAC placeholder, IV, dataSize (the uncompressed size, as the
+// game writes it), and encrypted data.
 func encrypt(plaintext []byte) ([]byte, error) {
 	// Step 1: Compress with zlib
 	var compressed bytes.Buffer
@@ -240,6 +257,7 @@ func encrypt(plaintext []byte) ([]byte, error) {
 
 	// Step 5: Build output buffer
 	// Format: signature (4) + HMAC (32) + IV (16) + dataSize (4) + encrypted data
+	dataSize := uint32(len(plaintext))
 	var output bytes.Buffer
 
 	// Signature
@@ -247,7 +265,8 @@ func encrypt(plaintext []byte) ([]byte, error) {
 		return nil, fmt.Errorf("write signature: %w", err)
 	}
 
-	// HMAC placeholder (32 bytes of zeros, matching C# behavior)
+	// HMAC placeholder (32 bytes of zeros, matching C# behavior): the HMAC
+	// the game writes is not known
 	hmac := make([]byte, 32)
 	if _, err := output.Write(hmac); err != nil {
 		return nil, fmt.Errorf("write HMAC: %w", err)
@@ -259,7 +278,7 @@ func encrypt(plaintext []byte) ([]byte, error) {
 	}
 
 	// Data size
-	if err := binary.Write(&output, binary.LittleEndian, uint32(len(encryptedData))); err != nil {
+	if err := binary.Write(&output, binary.LittleEndian, dataSize); err != nil {
 		return nil, fmt.Errorf("write data size: %w", err)
 	}
 
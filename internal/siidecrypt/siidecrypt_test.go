@@ -0,0 +1,166 @@
+package siidecrypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robebs/ts-se-tool-go/internal/sii"
+)
+
+const samplePlaintext = "SiiNunit\n{\nbank : _nameless.231.8bc4.9960 {\n money_account: 100000000\n loans: 0\n}\n\n}\n"
+
+func TestDecryptFile_3nKRoundTrip(t *testing.T) {
+	encoded := Encode3nK([]byte(samplePlaintext), 0xA7)
+	if bytes.Contains(encoded, []byte("SiiNunit")) {
+		t.Fatalf("3nK payload was not scrambled")
+	}
+
+	path := filepath.Join(t.TempDir(), "def.sii")
+	if err := os.WriteFile(path, encoded, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := DecryptFile(path, true)
+	if err != nil {
+		t.Fatalf("DecryptFile: %v", err)
+	}
+	if string(plain) != samplePlaintext {
+		t.Errorf("3nK round trip mismatch:\n got %q\nwant %q", plain, samplePlaintext)
+	}
+}
+
+func TestEncryptFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.sii")
+	if err := EncryptFile(path, []byte(samplePlaintext)); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+
+	plain, err := DecryptFile(path, true)
+	if err != nil {
+		t.Fatalf("DecryptFile: %v", err)
+	}
+	if string(plain) != samplePlaintext {
+		t.Errorf("encrypt round trip mismatch:\n got %q\nwant %q", plain, samplePlaintext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[4:36], make([]byte, 32)) {
+		t.Errorf("HMAC = %x, want the zero placeholder", data[4:36])
+	}
+	if size := binary.LittleEndian.Uint32(data[52:56]); size != uint32(len(samplePlaintext)) {
+		t.Errorf("data size = %d, want the plaintext size %d", size, len(samplePlaintext))
+	}
+}
+
+func TestEncodeBSII_RoundTrip(t *testing.T) {
+	path := "../../tmp/save/1/game.sii"
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("sample save not available")
+	}
+	raw, err := DecryptFile(path, false)
+	if err != nil {
+		t.Fatalf("DecryptFile: %v", err)
+	}
+	text, schema, err := DecodeBSIIWithSchema(raw)
+	if err != nil {
+		t.Fatalf("DecodeBSIIWithSchema: %v", err)
+	}
+	doc, err := sii.ReadDocument(text)
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+
+	encoded, err := EncodeBSII(doc, schema)
+	if err != nil {
+		t.Fatalf("EncodeBSII: %v", err)
+	}
+	again, err := DecodeBSII(encoded)
+	if err != nil {
+		t.Fatalf("DecodeBSII: %v", err)
+	}
+	if !bytes.Equal(text, again) {
+		t.Fatalf("text differs after BSII round trip (%d vs %d bytes)", len(text), len(again))
+	}
+
+	// Without a schema every structure is inferred from the text values.
+	inferred, err := EncodeBSII(doc, nil)
+	if err != nil {
+		t.Fatalf("EncodeBSII without schema: %v", err)
+	}
+	if _, err := DecodeBSII(inferred); err != nil {
+		t.Fatalf("DecodeBSII of inferred encoding: %v", err)
+	}
+}
+
+func TestDecodeFile_3nKBinary(t *testing.T) {
+	doc, err := sii.ReadDocument([]byte(samplePlaintext))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	binary, err := EncodeBSII(doc, nil)
+	if err != nil {
+		t.Fatalf("EncodeBSII: %v", err)
+	}
+	want, err := DecodeBSII(binary)
+	if err != nil {
+		t.Fatalf("DecodeBSII: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "game.sii")
+	if err := os.WriteFile(path, Encode3nK(binary, 0x5A), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		plain, format, err := DecodeFile(path)
+		if err != nil {
+			t.Fatalf("DecodeFile: %v", err)
+		}
+		if !format.Scrambled || format.Seed != 0x5A || format.Encoding != SignatureBinary || format.Schema == nil {
+			t.Fatalf("format = %s (seed 0x%02X), want 3nK BSII with seed 0x5A", format, format.Seed)
+		}
+		if !bytes.Equal(plain, want) {
+			t.Fatalf("plaintext mismatch:\n got %q\nwant %q", plain, want)
+		}
+		// Write it back in the recorded format and read it again
+		if err := WriteFile(path, doc, format); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+}
+
+func TestNewDecodeReader_MatchesDecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	encrypted := filepath.Join(dir, "profile.sii")
+	if err := EncryptFile(encrypted, Encode3nK([]byte(samplePlaintext), 0x3C)); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{encrypted, "../../tmp/save/1/game.sii"}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		want, err := DecryptFile(path, true)
+		if err != nil {
+			t.Fatalf("DecryptFile %s: %v", path, err)
+		}
+		r, err := OpenFile(path)
+		if err != nil {
+			t.Fatalf("OpenFile %s: %v", path, err)
+		}
+		got, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: streamed output differs (%d vs %d bytes)", path, len(got), len(want))
+		}
+	}
+}
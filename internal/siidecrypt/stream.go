@@ -0,0 +1,402 @@
+package siidecrypt
+
+import (
+	"bufio"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// streamChunkSize is how much encrypted or binary data the streaming
+// readers fetch at a time.
+const streamChunkSize = 32 * 1024
+
+// OpenFile opens an SII file for streaming. The returned reader yields the
+// same text as DecryptFile(path, true), but decrypts,
+// inflates and decodes it on the fly, so memory use does not grow with the
+// size of the save. Use it with sii.NewReader.
+func OpenFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	r, err := NewDecodeReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &fileReader{Reader: r, f: f}, nil
+}
+
+type fileReader struct {
+	io.Reader
+	f *os.File
+}
+
+func (r *fileReader) Close() error {
+	return r.f.Close()
+}
+
+// NewDecodeReader is the streaming equivalent of DecryptFile with decode
+// set: it returns a reader of the plaintext SII content of the file read
+// from r.
+func NewDecodeReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	sig, err := br.Peek(4)
+	if err != nil {
+		return nil, errors.New("invalid file: cannot read signature")
+	}
+	if SignatureType(binary.LittleEndian.Uint32(sig)) != SignatureEncrypted {
+		return newPayloadReader(br)
+	}
+
+	cbc, err := newCBCReader(br)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zlib.NewReader(cbc)
+	if err != nil {
+		return nil, fmt.Errorf("zlib uncompress: %w", err)
+	}
+	return newPayloadReader(bufio.NewReader(&inflateReader{zr: zr, cbc: cbc}))
+}
+
+// newPayloadReader decodes a decrypted stream by its inner signature, like
+// decodePayload.
+func newPayloadReader(br *bufio.Reader) (io.Reader, error) {
+	sig, err := br.Peek(4)
+	if err != nil {
+		return nil, errors.New("invalid data: cannot read inner signature")
+	}
+
+	switch dataType := binary.LittleEndian.Uint32(sig); SignatureType(dataType) {
+	case SignaturePlainText:
+		return br, nil
+	case SignatureBinary:
+		return newBSIIReader(br), nil
+	case Signature3nK:
+		var header [threeNKHeaderSize]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return nil, errors.New("3nK: data too short for header")
+		}
+		return newPayloadReader(bufio.NewReader(&threeNKReader{r: br, seed: header[5]}))
+	default:
+		return nil, fmt.Errorf("unknown inner signature: 0x%08X", dataType)
+	}
+}
+
+// inflateReader reads the zlib stream and, once it is exhausted, drains the
+// encrypted container so that its padding gets checked.
+type inflateReader struct {
+	zr  io.ReadCloser
+	cbc *cbcReader
+}
+
+func (r *inflateReader) Read(p []byte) (int, error) {
+	n, err := r.zr.Read(p)
+	if err == io.EOF {
+		_ = r.zr.Close()
+		if _, err := io.Copy(io.Discard, r.cbc); err != nil {
+			return n, err
+		}
+		return n, io.EOF
+	}
+	if err != nil {
+		return n, fmt.Errorf("zlib read: %w", err)
+	}
+	return n, nil
+}
+
+// cbcReader is the streaming equivalent of decrypt: it decrypts the AES-CBC
+// payload of an encrypted container chunk by chunk, strips the PKCS#7
+// padding.
+type cbcReader struct {
+	src  io.Reader
+	mode cipher.BlockMode
+
+	in    []byte
+	out   []byte
+	ready []byte
+	last  []byte // the last decrypted block, which may hold the padding
+	err   error
+}
+
+func newCBCReader(src io.Reader) (*cbcReader, error) {
+	// signature (4) + HMAC (32) + IV (16) + data size (4)
+	var header [56]byte
+	if _, err := io.ReadFull(src, header[:]); err != nil {
+		return nil, errors.New("encrypted data is too short for its header")
+	}
+	iv := header[36:52]
+
+	block, err := aes.NewCipher(siiKey)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+	return &cbcReader{
+		src:  src,
+		mode: cipher.NewCBCDecrypter(block, iv),
+		in:   make([]byte, streamChunkSize),
+		out:  make([]byte, streamChunkSize+aes.BlockSize),
+	}, nil
+}
+
+func (r *cbcReader) Read(p []byte) (int, error) {
+	for len(r.ready) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.ready)
+	r.ready = r.ready[n:]
+	return n, nil
+}
+
+// fill decrypts the next chunk into ready, holding back its last block until
+// the end of the data is known.
+func (r *cbcReader) fill() {
+	n, err := io.ReadFull(r.src, r.in)
+	if n%aes.BlockSize != 0 {
+		r.err = errors.New("encrypted data is not a multiple of AES block size")
+		return
+	}
+	if n > 0 {
+		held := copy(r.out, r.last)
+		r.mode.CryptBlocks(r.out[held:held+n], r.in[:n])
+		end := held + n - aes.BlockSize
+		r.ready = r.out[:end]
+		r.last = append(r.last[:0], r.out[end:end+aes.BlockSize]...)
+	}
+	if err == nil {
+		return
+	}
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		r.err = fmt.Errorf("read encrypted data: %w", err)
+		return
+	}
+
+	// PKCS#7 unpadding
+	if len(r.last) == 0 {
+		r.err = errors.New("decrypted data is empty")
+		return
+	}
+	pad := int(r.last[len(r.last)-1])
+	if pad <= 0 || pad > aes.BlockSize {
+		r.err = errors.New("invalid PKCS#7 padding")
+		return
+	}
+	r.ready = append(r.ready, r.last[:aes.BlockSize-pad]...)
+	r.last = r.last[:0]
+
+	r.err = io.EOF
+}
+
+// threeNKReader descrambles a 3nK payload (header already consumed).
+type threeNKReader struct {
+	r    io.Reader
+	seed byte
+	pos  int
+}
+
+func (r *threeNKReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	transcode3nK(p[:n], p[:n], byte(int(r.seed)+r.pos))
+	r.pos += n
+	return n, err
+}
+
+// bsiiReader decodes a BSII stream in a single pass and yields the same text
+// as DecodeBSII. Each record is decoded from a window of the input, which is
+// extended whenever a record does not fit. Unlike DecodeBSII, it requires
+// every structure to be defined before its first instance, which is how the
+// game writes its files.
+type bsiiReader struct {
+	src    io.Reader
+	buf    []byte
+	pos    int
+	srcEOF bool
+
+	version  uint32
+	defs     map[uint32]*BSIIStructureBlock
+	ordinals map[uint32]map[uint32]string
+
+	started bool
+	done    bool
+	out     strings.Builder
+	ready   string
+	err     error
+}
+
+func newBSIIReader(src io.Reader) *bsiiReader {
+	return &bsiiReader{
+		src:      src,
+		defs:     make(map[uint32]*BSIIStructureBlock),
+		ordinals: make(map[uint32]map[uint32]string),
+	}
+}
+
+func (r *bsiiReader) Read(p []byte) (int, error) {
+	for len(r.ready) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			r.err = io.EOF
+			continue
+		}
+		r.out.Reset()
+		if err := r.step(); err != nil {
+			r.err = err
+		}
+		r.ready = r.out.String()
+	}
+	n := copy(p, r.ready)
+	r.ready = r.ready[n:]
+	return n, nil
+}
+
+// step decodes the header or the next record, reading more input until it
+// fits.
+func (r *bsiiReader) step() error {
+	for {
+		start := r.pos
+		var err error
+		if r.started {
+			err = r.decodeRecord()
+		} else {
+			err = r.decodeHeader()
+		}
+		if !errors.Is(err, errShortData) || r.srcEOF {
+			return err
+		}
+		r.pos = start
+		if err := r.more(); err != nil {
+			return err
+		}
+	}
+}
+
+// more compacts the window and reads at least as much input as it holds.
+func (r *bsiiReader) more() error {
+	n := copy(r.buf, r.buf[r.pos:])
+	r.buf = r.buf[:n]
+	r.pos = 0
+
+	want := n
+	if want < streamChunkSize {
+		want = streamChunkSize
+	}
+	if cap(r.buf)-n < want {
+		grown := make([]byte, n, n+want)
+		copy(grown, r.buf)
+		r.buf = grown
+	}
+	read, err := io.ReadAtLeast(r.src, r.buf[n:cap(r.buf)], want)
+	r.buf = r.buf[:n+read]
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.srcEOF = true
+		return nil
+	}
+	return err
+}
+
+func (r *bsiiReader) decodeHeader() error {
+	if _, err := decodeUInt32(r.buf, &r.pos); err != nil {
+		return fmt.Errorf("read signature: %w", err)
+	}
+	ver, err := decodeUInt32(r.buf, &r.pos)
+	if err != nil {
+		return fmt.Errorf("read version: %w", err)
+	}
+	if ver != BSIIVersion0 && ver != BSIIVersion1 && ver != BSIIVersion2 && ver != BSIIVersion3 {
+		return fmt.Errorf("BSII version %d not supported", ver)
+	}
+	r.version = ver
+	r.started = true
+	r.out.WriteString("SiiNunit\n{\n")
+	return nil
+}
+
+// decodeRecord decodes one structure definition or instance. At the end of
+// the data, or of a truncated definition, it closes the document like
+// DecodeBSII does.
+func (r *bsiiReader) decodeRecord() error {
+	blockType, err := decodeUInt32(r.buf, &r.pos)
+	if err == nil && blockType == 0 {
+		err = r.decodeDefinition()
+	}
+	if err != nil {
+		if errors.Is(err, errShortData) && r.srcEOF {
+			r.finish()
+			return nil
+		}
+		return err
+	}
+	if blockType == 0 {
+		return nil
+	}
+
+	def, ok := r.defs[blockType]
+	if !ok {
+		return fmt.Errorf("structure ID %d not defined before its first instance", blockType)
+	}
+	blockData := newBSIIInstance(def)
+	if err := loadDataBlockLocal(r.buf, &r.pos, &blockData, r.version, r.ordinals[blockType]); err != nil {
+		return fmt.Errorf("load data block for structure %d: %w", blockType, err)
+	}
+	serializeBlock(&r.out, &blockData, r.version)
+	return nil
+}
+
+// decodeDefinition mirrors the first pass of DecodeBSIIWithSchema: the first
+// definition of a structure ID wins.
+func (r *bsiiReader) decodeDefinition() error {
+	valid, err := decodeBool(r.buf, &r.pos)
+	if err != nil || !valid {
+		return err
+	}
+	structureID, err := decodeUInt32(r.buf, &r.pos)
+	if err != nil {
+		return err
+	}
+	name, err := decodeUTF8String(r.buf, &r.pos)
+	if err != nil {
+		return err
+	}
+	def := &BSIIStructureBlock{StructureID: structureID, Name: name, Validity: valid}
+	var ordinals map[uint32]string
+	for {
+		segment, err := readDataBlock(r.buf, &r.pos)
+		if err != nil {
+			return err
+		}
+		if segment.Type == 0 {
+			break
+		}
+		if dict, ok := segment.Value.(map[uint32]string); ok && segment.Type == 0x37 && ordinals == nil {
+			ordinals = dict
+		}
+		def.Segments = append(def.Segments, segment)
+	}
+
+	if _, exists := r.ordinals[structureID]; !exists && ordinals != nil {
+		r.ordinals[structureID] = ordinals
+	}
+	if _, exists := r.defs[structureID]; !exists {
+		r.defs[structureID] = def
+	}
+	return nil
+}
+
+// finish closes the document.
+func (r *bsiiReader) finish() {
+	r.out.WriteString("}")
+	r.done = true
+}
@@ -0,0 +1,59 @@
+package siidecrypt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// threeNKHeaderSize is the size of the 3nK header: signature (4 bytes,
+// "3nK\x01"), one unknown byte (always 0x01 in game files) and the seed byte.
+const threeNKHeaderSize = 6
+
+// threeNKKeyTable mirrors the 256-byte key table used by SII_3nK_Transcoder.
+// Every entry is derived from its index, so we compute it once at init time
+// instead of embedding the literal table.
+var threeNKKeyTable = func() [256]byte {
+	var table [256]byte
+	for i := 0; i < 256; i++ {
+		b := byte(i)
+		table[i] = (((b << 2) ^ ^b) << 3) ^ b
+	}
+	return table
+}()
+
+// Decode3nK decodes a 3nK scrambled buffer (signature included) and returns
+// the plain payload, which is normally a textual SiiNunit document.
+func Decode3nK(data []byte) ([]byte, error) {
+	if len(data) < threeNKHeaderSize {
+		return nil, errors.New("3nK: data too short for header")
+	}
+	if SignatureType(binary.LittleEndian.Uint32(data)) != Signature3nK {
+		return nil, fmt.Errorf("3nK: invalid signature 0x%08X", binary.LittleEndian.Uint32(data))
+	}
+
+	seed := data[5]
+	payload := data[threeNKHeaderSize:]
+	out := make([]byte, len(payload))
+	transcode3nK(out, payload, seed)
+	return out, nil
+}
+
+// Encode3nK is the reverse of Decode3nK: it scrambles plain with the given
+// seed and prepends the 3nK header.
+func Encode3nK(plain []byte, seed byte) []byte {
+	out := make([]byte, threeNKHeaderSize+len(plain))
+	binary.LittleEndian.PutUint32(out, uint32(Signature3nK))
+	out[4] = 0x01
+	out[5] = seed
+	transcode3nK(out[threeNKHeaderSize:], plain, seed)
+	return out
+}
+
+// transcode3nK xors src with the key table starting at seed. The operation is
+// symmetric, so it is used for both decoding and encoding.
+func transcode3nK(dst, src []byte, seed byte) {
+	for i, b := range src {
+		dst[i] = b ^ threeNKKeyTable[byte(int(seed)+i)]
+	}
+}